@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -27,6 +28,11 @@ func NewDatabase(dbUser, dbPass, dbName, dbHost string) (*DatabaseStruct, error)
 	return &DatabaseStruct{connection: db}, err
 }
 
+// Wrap usa una conexión ya abierta, por ejemplo con otro driver en los benchmarks
+func Wrap(db *sql.DB) *DatabaseStruct {
+	return &DatabaseStruct{connection: db}
+}
+
 func (db *DatabaseStruct) Close() {
 	db.connection.Close()
 }
@@ -118,6 +124,17 @@ func (db *DatabaseStruct) Exec(query string, args ...interface{}) (sql.Result, e
 	return db.connection.Exec(query, args...)
 }
 
+// Begin inicia una transacción para operaciones que deben aplicarse completas o no aplicarse
+func (db *DatabaseStruct) Begin() (*sql.Tx, error) {
+	return db.connection.Begin()
+}
+
+// Conn reserva una conexión del pool para lo que depende de la sesión de MySQL, como GET_LOCK.
+// Hay que cerrarla para devolverla al pool.
+func (db *DatabaseStruct) Conn(ctx context.Context) (*sql.Conn, error) {
+	return db.connection.Conn(ctx)
+}
+
 // CheckArtistLinksTable verifica si la tabla artist_links existe y muestra su estructura
 func (db *DatabaseStruct) CheckArtistLinksTable() {
 	// Verificar si la tabla existe
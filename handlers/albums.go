@@ -17,7 +17,7 @@ type Album struct {
 func (h *AuthHandler) GetAlbums(w http.ResponseWriter, r *http.Request) {
 	rows, err := h.DB.Select("SELECT id, id_Facebook, title, slug FROM albums")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	defer rows.Close()
@@ -36,13 +36,13 @@ func (h *AuthHandler) GetAlbumByID(w http.ResponseWriter, r *http.Request) {
 	var b Album
 	row, err := h.DB.SelectRow("SELECT id, id_Facebook, title, slug FROM albums WHERE id = ?", id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 
 	err = row.Scan(&b.ID, &b.IDFacebook, &b.Title, &b.Slug)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, "", err)
 		return
 	}
 
@@ -54,7 +54,7 @@ func (h *AuthHandler) CreateAlbum(w http.ResponseWriter, r *http.Request) {
 	json.NewDecoder(r.Body).Decode(&b)
 	id, err := h.DB.Insert(false, "INSERT INTO albums (id_Facebook, title, slug) VALUES (?, ?, ?, ?)", b.IDFacebook, b.Title, b.Slug)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	b.ID = int(id)
@@ -66,7 +66,7 @@ func (h *AuthHandler) UpdateAlbum(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	_, err := h.DB.Update(false, "UPDATE albums SET name=?, bio=?, slug=?, social=? WHERE id=?", b.IDFacebook, b.Title, b.Slug, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -76,7 +76,7 @@ func (h *AuthHandler) DeleteAlbum(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	_, err := h.DB.Delete(false, "DELETE FROM albums WHERE id = ?", id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
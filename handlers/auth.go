@@ -15,10 +15,32 @@ import (
 
 type AuthHandler struct {
 	DB *database.DatabaseStruct
+
+	autocomplete *autocompleteIndex
+	cache        *responseCache
+	webhooks     *webhookDispatcher
+
+	submissionStream *submissionBroker
+	bus              *EventBus  // eventos de dominio; los suscriptores se registran en subscribe()
+	jobs             *JobQueue  // trabajos en segundo plano; los tipos se registran en registerJobs()
+	scheduler        *Scheduler // tareas periódicas; se declaran en registerScheduledTasks()
 }
 
 func NewAuthHandler(db *database.DatabaseStruct) *AuthHandler {
-	return &AuthHandler{DB: db}
+	h := &AuthHandler{
+		DB:               db,
+		autocomplete:     &autocompleteIndex{},
+		cache:            newResponseCache(db),
+		webhooks:         newWebhookDispatcher(),
+		submissionStream: newSubmissionBroker(),
+		bus:              NewEventBus(),
+		jobs:             NewJobQueue(db),
+	}
+	h.scheduler = newScheduler(db, h.jobs.workerID)
+	h.registerJobs()
+	h.registerScheduledTasks()
+	h.subscribe()
+	return h
 }
 
 func (h *AuthHandler) RequestPasswordRecovery(w http.ResponseWriter, r *http.Request) {
@@ -26,7 +48,7 @@ func (h *AuthHandler) RequestPasswordRecovery(w http.ResponseWriter, r *http.Req
 		Email string `json:"email"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
 
@@ -35,9 +57,9 @@ func (h *AuthHandler) RequestPasswordRecovery(w http.ResponseWriter, r *http.Req
 	err := row.Scan(&u.ID, &u.Email)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			http.Error(w, "Email no encontrado", http.StatusNotFound)
+			writeError(w, r, http.StatusNotFound, "Email no encontrado")
 		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "", err)
 		}
 		return
 	}
@@ -45,14 +67,14 @@ func (h *AuthHandler) RequestPasswordRecovery(w http.ResponseWriter, r *http.Req
 	recoveryToken := fmt.Sprintf("%x", md5.Sum([]byte(time.Now().String()+u.Email)))
 	_, err = h.DB.Update(true, "UPDATE users SET recovery_hash = ?, recovery_hash_time = NOW() WHERE id = ?", recoveryToken, u.ID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 
 	err = utils.SendRecoveryEmail(u.Email, recoveryToken)
 	if err != nil {
 		log.Println("Error al enviar el correo de recuperación:", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 
@@ -66,7 +88,7 @@ func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 		NewPassword string `json:"newPassword"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
 
@@ -76,19 +98,19 @@ func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	err := row.Scan(&userID, &recoveryHashTimeStr)
 	if err != nil {
 		log.Println("Error al obtener token de recuperación:", err)
-		http.Error(w, "Error al obtener el token de recuperación", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener el token de recuperación")
 		return
 	}
 
 	recoveryHashTime, err := time.Parse("2006-01-02 15:04:05", recoveryHashTimeStr)
 	if err != nil {
 		log.Println("Error al parsear recoveryHashTime:", err)
-		http.Error(w, "Error al procesar la fecha del token", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al procesar la fecha del token")
 		return
 	}
 
 	if time.Since(recoveryHashTime).Hours() > 24 {
-		http.Error(w, "El token de recuperación ha expirado", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "El token de recuperación ha expirado")
 		return
 	}
 
@@ -96,7 +118,7 @@ func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	newHashedPassword := utils.HashPassword(requestData.NewPassword, newSalt)
 	_, err = h.DB.Update(true, "UPDATE users SET password_hash = ?, salt = ?, recovery_hash = NULL WHERE id = ?", newHashedPassword, newSalt, userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cada cuánto se recarga el índice de autocompletado aunque no haya cambios
+const autocompleteRefreshInterval = 5 * time.Minute
+
+// Sugerencias que se devuelven por defecto y como máximo
+const (
+	defaultAutocompleteLimit = 8
+	maxAutocompleteLimit     = 20
+)
+
+// AutocompleteEntry es una sugerencia del autocompletado.
+//
+// @Schema
+type AutocompleteEntry struct {
+	Type     string  `json:"type"` // band, venue o person
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Slug     string  `json:"slug,omitempty"`
+	Subtitle string  `json:"subtitle,omitempty"` // ciudad del venue, usuario de la persona
+	Score    float64 `json:"score"`
+
+	folded string
+	words  []string
+}
+
+// matches indica si cada palabra buscada es prefijo de alguna palabra del nombre.
+// Así "renga la" encuentra "La Renga" y "pajar" encuentra "Los Pájaros".
+func (e *AutocompleteEntry) matches(query []string) bool {
+	for _, q := range query {
+		found := false
+		for _, word := range e.words {
+			if strings.HasPrefix(word, q) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// autocompleteIndex mantiene en memoria los nombres de bandas, venues y personas
+// ordenados por popularidad, para responder sin ir a la base.
+type autocompleteIndex struct {
+	mu         sync.RWMutex
+	entries    []AutocompleteEntry
+	loadedAt   time.Time
+	stale      bool
+	refreshing bool
+}
+
+// markStale pide recargar el índice en la próxima consulta (se llama al modificar bandas o venues)
+func (idx *autocompleteIndex) markStale() {
+	idx.mu.Lock()
+	idx.stale = true
+	idx.mu.Unlock()
+}
+
+// popularity combina fechas próximas, visitas y fechas pasadas. Las visitas pesan en escala
+// logarítmica para que un perfil muy visitado no tape a los que tienen shows confirmados.
+func popularity(upcoming, views, past int) float64 {
+	score := 3*float64(upcoming) + math.Log1p(float64(views)) + 0.2*math.Min(float64(past), 25)
+	return math.Round(score*100) / 100
+}
+
+// loadAutocompleteEntries lee bandas, venues y personas con su popularidad
+func (h *AuthHandler) loadAutocompleteEntries() ([]AutocompleteEntry, error) {
+	var entries []AutocompleteEntry
+
+	bandRows, err := h.DB.Select(`
+		SELECT b.id, b.name, b.slug, b.views,
+			COUNT(DISTINCT CASE WHEN e.date_start >= NOW() THEN e.id END),
+			COUNT(DISTINCT CASE WHEN e.date_start < NOW() THEN e.id END)
+		FROM bands b
+		LEFT JOIN events_bands eb ON eb.id_band = b.id
+		LEFT JOIN events e ON e.id = eb.id_event
+		GROUP BY b.id, b.name, b.slug, b.views`)
+	if err != nil {
+		return nil, err
+	}
+	for bandRows.Next() {
+		e := AutocompleteEntry{Type: "band"}
+		var views, upcoming, past int
+		if err := bandRows.Scan(&e.ID, &e.Name, &e.Slug, &views, &upcoming, &past); err != nil {
+			continue
+		}
+		e.Score = popularity(upcoming, views, past)
+		entries = append(entries, e)
+	}
+	bandRows.Close()
+
+	venueRows, err := h.DB.Select(`
+		SELECT v.id, v.name, v.slug, IFNULL(v.city, ''), v.views,
+			COUNT(DISTINCT CASE WHEN e.date_start >= NOW() THEN e.id END),
+			COUNT(DISTINCT CASE WHEN e.date_start < NOW() THEN e.id END)
+		FROM venues v
+		LEFT JOIN events e ON e.id_venue = v.id
+		GROUP BY v.id, v.name, v.slug, v.city, v.views`)
+	if err != nil {
+		return nil, err
+	}
+	for venueRows.Next() {
+		e := AutocompleteEntry{Type: "venue"}
+		var views, upcoming, past int
+		if err := venueRows.Scan(&e.ID, &e.Name, &e.Slug, &e.Subtitle, &views, &upcoming, &past); err != nil {
+			continue
+		}
+		e.Score = popularity(upcoming, views, past)
+		entries = append(entries, e)
+	}
+	venueRows.Close()
+
+	// Personas: solo usuarios con un vínculo aprobado a una banda o un venue (perfil público).
+	// Nunca se exponen emails.
+	personRows, err := h.DB.Select(`
+		SELECT u.id, IFNULL(NULLIF(u.realName, ''), u.username), u.username,
+			(SELECT COUNT(*) FROM artist_links al WHERE al.user_id = u.id AND al.status = 'approved') +
+			(SELECT COUNT(*) FROM venue_links vl WHERE vl.user_id = u.id AND vl.status = 'approved')
+		FROM users u
+		WHERE EXISTS(SELECT 1 FROM artist_links al WHERE al.user_id = u.id AND al.status = 'approved')
+		   OR EXISTS(SELECT 1 FROM venue_links vl WHERE vl.user_id = u.id AND vl.status = 'approved')`)
+	if err != nil {
+		return nil, err
+	}
+	for personRows.Next() {
+		e := AutocompleteEntry{Type: "person"}
+		var links int
+		if err := personRows.Scan(&e.ID, &e.Name, &e.Subtitle, &links); err != nil {
+			continue
+		}
+		e.Score = popularity(0, 0, links)
+		entries = append(entries, e)
+	}
+	personRows.Close()
+
+	for i := range entries {
+		entries[i].folded = foldForMatch(entries[i].Name)
+		entries[i].words = strings.Fields(entries[i].folded)
+		// El nombre completo sin espacios también cuenta como palabra ("larenga")
+		if len(entries[i].words) > 1 {
+			entries[i].words = append(entries[i].words, strings.ReplaceAll(entries[i].folded, " ", ""))
+		}
+	}
+
+	// Más populares primero; a igual popularidad, alfabético
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].folded < entries[j].folded
+	})
+	return entries, nil
+}
+
+// refreshAutocomplete recarga el índice. Si falla, se conserva el anterior.
+func (h *AuthHandler) refreshAutocomplete() error {
+	entries, err := h.loadAutocompleteEntries()
+
+	idx := h.autocomplete
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.refreshing = false
+	if err != nil {
+		return err
+	}
+	idx.entries = entries
+	idx.loadedAt = time.Now()
+	idx.stale = false
+	return nil
+}
+
+// StartAutocompleteRefresh carga el índice de autocompletado y lo recarga periódicamente
+func (h *AuthHandler) StartAutocompleteRefresh() {
+	if err := h.refreshAutocomplete(); err != nil {
+		fmt.Printf("[Warning] No se pudo cargar el índice de autocompletado: %v\n", err)
+	}
+	go func() {
+		ticker := time.NewTicker(autocompleteRefreshInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := h.refreshAutocomplete(); err != nil {
+				fmt.Printf("[Warning] No se pudo recargar el índice de autocompletado: %v\n", err)
+			}
+		}
+	}()
+}
+
+// autocompleteEntries devuelve el índice actual. Si quedó desactualizado lo recarga
+// en segundo plano y mientras tanto responde con el anterior.
+func (h *AuthHandler) autocompleteEntries() []AutocompleteEntry {
+	idx := h.autocomplete
+	idx.mu.RLock()
+	entries, loaded := idx.entries, !idx.loadedAt.IsZero()
+	needsRefresh := (idx.stale || time.Since(idx.loadedAt) > autocompleteRefreshInterval) && !idx.refreshing
+	idx.mu.RUnlock()
+
+	if !loaded {
+		// Primera consulta sin StartAutocompleteRefresh: cargar en el momento
+		if err := h.refreshAutocomplete(); err != nil {
+			fmt.Printf("[Warning] No se pudo cargar el índice de autocompletado: %v\n", err)
+		}
+		idx.mu.RLock()
+		defer idx.mu.RUnlock()
+		return idx.entries
+	}
+
+	if needsRefresh {
+		idx.mu.Lock()
+		if !idx.refreshing {
+			idx.refreshing = true
+			go func() {
+				if err := h.refreshAutocomplete(); err != nil {
+					fmt.Printf("[Warning] No se pudo recargar el índice de autocompletado: %v\n", err)
+				}
+			}()
+		}
+		idx.mu.Unlock()
+	}
+	return entries
+}
+
+// suggest devuelve hasta limit sugerencias de los tipos pedidos. Como el índice ya está
+// ordenado por popularidad, alcanza con cortar en las primeras coincidencias.
+func (h *AuthHandler) suggest(q string, types map[string]bool, limit int) []AutocompleteEntry {
+	query := strings.Fields(foldForMatch(q))
+	results := []AutocompleteEntry{}
+	if len(query) == 0 {
+		return results
+	}
+	for _, e := range h.autocompleteEntries() {
+		if len(types) > 0 && !types[e.Type] {
+			continue
+		}
+		if e.matches(query) {
+			results = append(results, e)
+			if len(results) >= limit {
+				break
+			}
+		}
+	}
+	return results
+}
+
+// Autocomplete sugiere bandas, venues y personas a medida que se escribe.
+//
+// @Summary Autocompletado
+// @Description Busca por prefijo de cada palabra, sin distinguir tildes ni mayúsculas, en un índice en memoria ordenado por popularidad (fechas próximas y visitas). Las personas son usuarios vinculados a una banda o un venue.
+// @Tags búsqueda
+// @Produce json
+// @Param q query string true "Texto escrito hasta el momento"
+// @Param type query string false "Tipos a incluir, separados por coma (band, venue, person)"
+// @Param limit query int false "Cantidad de sugerencias (por defecto 8, máximo 20)"
+// @Success 200 {object} map[string]interface{} "Sugerencias"
+// @Failure 400 {string} string "Tipo inválido"
+// @Router /autocomplete [get]
+func (h *AuthHandler) Autocomplete(w http.ResponseWriter, r *http.Request) {
+	types := map[string]bool{}
+	if typesParam := r.URL.Query().Get("type"); typesParam != "" {
+		for _, t := range strings.Split(typesParam, ",") {
+			t = strings.TrimSpace(t)
+			if t != "band" && t != "venue" && t != "person" {
+				writeError(w, r, http.StatusBadRequest, "Tipo inválido: "+t)
+				return
+			}
+			types[t] = true
+		}
+	}
+
+	limit := defaultAutocompleteLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxAutocompleteLimit)
+	}
+
+	q := r.URL.Query().Get("q")
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=30")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"query":   q,
+		"results": h.suggest(q, types, limit),
+	})
+}
+
+// countView suma una visita al perfil de una banda o un venue, sin demorar la respuesta.
+// updated_at se deja como estaba: una visita no cambia el perfil (ni su Last-Modified).
+func (h *AuthHandler) countView(table string, id int) {
+	if id <= 0 || (table != "bands" && table != "venues") {
+		return
+	}
+	go func() {
+		if _, err := h.DB.Update(false, "UPDATE "+table+" SET views = views + 1, updated_at = updated_at WHERE id = ?", id); err != nil {
+			fmt.Printf("[Warning] No se pudo registrar la visita de %s #%d: %v\n", table, id, err)
+		}
+	}()
+}
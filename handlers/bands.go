@@ -10,7 +10,6 @@ import (
 	"net/http"
 	"os"
 	"strconv"
-	"strings"
 	"bytes"
 
 	"brotecolectivo/models"
@@ -22,11 +21,12 @@ import (
 // Band representa la estructura de datos de un artista o banda musical en el sistema.
 // Se utiliza tanto para almacenar en la base de datos como para la respuesta JSON de la API.
 type Band struct {
-	ID     int               `json:"id"`     // Identificador único de la banda
-	Name   string            `json:"name"`   // Nombre de la banda o artista
-	Bio    string            `json:"bio"`    // Biografía o descripción del artista
-	Slug   string            `json:"slug"`   // Identificador URL-friendly para rutas amigables
-	Social map[string]string `json:"social"` // Mapa de redes sociales (clave: plataforma, valor: enlace)
+	ID     int               `json:"id"`               // Identificador único de la banda
+	Name   string            `json:"name"`             // Nombre de la banda o artista
+	Bio    string            `json:"bio"`              // Biografía o descripción del artista
+	Slug   string            `json:"slug"`             // Identificador URL-friendly para rutas amigables
+	Social map[string]string `json:"social"`           // Mapa de redes sociales (clave: plataforma, valor: enlace)
+	Genres []Genre           `json:"genres,omitempty"` // Géneros de sus canciones (con include=genres)
 }
 
 // getBucket obtiene el nombre del bucket de almacenamiento desde el archivo de configuración.
@@ -52,7 +52,7 @@ func getOpenAIKey() string {
 // CheckBandSlug verifica si un slug de banda ya existe en la base de datos.
 //
 // @Summary Verifica disponibilidad de slug
-// @Description Comprueba si un slug de banda ya está en uso
+// @Description Comprueba si un slug de banda ya está en uso. Obsoleto: usar GET /slugs/check?type=band
 // @Tags bands
 // @Accept json
 // @Produce json
@@ -65,15 +65,13 @@ func getOpenAIKey() string {
 func (h *AuthHandler) CheckBandSlug(w http.ResponseWriter, r *http.Request) {
 	slug := chi.URLParam(r, "slug")
 	if slug == "" {
-		http.Error(w, "Falta el slug", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Falta el slug")
 		return
 	}
 
-	var exists bool
-	row, _ := h.DB.SelectRow("SELECT EXISTS(SELECT 1 FROM bands WHERE slug = ?)", slug)
-	err := row.Scan(&exists)
+	exists, err := h.slugTaken("band", slug)
 	if err != nil {
-		http.Error(w, "Error al consultar la base de datos", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al consultar la base de datos")
 		return
 	}
 
@@ -81,14 +79,14 @@ func (h *AuthHandler) CheckBandSlug(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"exists": true}`))
 	} else {
-		http.Error(w, "Slug disponible", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, "Slug disponible")
 	}
 }
 
 // GetBandsCount devuelve el número total de bandas/artistas en la base de datos.
 //
 // @Summary Obtiene el conteo total de bandas
-// @Description Devuelve el número total de bandas/artistas registrados
+// @Description Devuelve el número de bandas/artistas que cumplen los mismos filtros que /bands/table (q, id, name, slug)
 // @Tags bands
 // @Accept json
 // @Produce json
@@ -96,20 +94,82 @@ func (h *AuthHandler) CheckBandSlug(w http.ResponseWriter, r *http.Request) {
 // @Failure 500 {string} string "Error al contar artistas o leer el conteo"
 // @Router /bands/count [get]
 func (h *AuthHandler) GetBandsCount(w http.ResponseWriter, r *http.Request) {
-	row, err := h.DB.SelectRow("SELECT COUNT(*) FROM bands")
+	h.writeCount(w, r, bandListSpec)
+}
+
+// bandListSpec declara los filtros y órdenes del listado de bandas.
+// Lo comparten el listado público, la tabla del panel y el conteo.
+var bandListSpec = &listSpec{
+	from:   "bands",
+	id:     column("id"),
+	search: columns("name", "slug", "bio", "social"),
+	filters: map[string]filterField{
+		"id":   numberFilter("id"),
+		"name": textFilter("name"),
+		"slug": textFilter("slug"),
+	},
+	sorts: map[string]string{"id": column("id"), "name": column("name"), "slug": column("slug")},
+}
+
+// Campos y relaciones de los listados de bandas
+var bandFieldSet = fieldSetSpec{
+	fields:    []string{"id", "name", "bio", "slug", "social"},
+	relations: []string{"genres"},
+}
+
+// writeBandPage responde una página de bandas con los encabezados de paginación
+func (h *AuthHandler) writeBandPage(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePageRequest(r, 10)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+	fs, err := bandFieldSet.parse(r)
 	if err != nil {
-		http.Error(w, "Error al contar artistas", http.StatusInternalServerError)
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
 
-	var count int
-	if err := row.Scan(&count); err != nil {
-		http.Error(w, "Error al leer el conteo", http.StatusInternalServerError)
+	bands, page, err := h.queryBands(r, p, fs)
+	if err != nil {
+		writeListError(w, r, err)
 		return
 	}
 
+	if fs.include("genres") {
+		if err := h.newLoader().bandGenresFor(bands); err != nil {
+			writeError(w, r, http.StatusInternalServerError, "Error al cargar los géneros", err)
+			return
+		}
+	}
+
+	writePageHeaders(w, r, page)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]int{"count": count})
+	fs.encode(w, bands)
+}
+
+// queryBands lee una página de bandas con los filtros del pedido
+func (h *AuthHandler) queryBands(r *http.Request, p *pageRequest, fs *fieldSet) ([]Band, *pageInfo, error) {
+	q, err := bandListSpec.query(r, "id, name, "+fs.pick("bio", "bio")+", slug, "+fs.pick("social", "social"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var bands []Band
+	page, err := h.queryPage(q, p, func(scan func(dest ...interface{}) error) error {
+		var b Band
+		var socialRaw []byte
+		if err := scan(&b.ID, &b.Name, &b.Bio, &b.Slug, &socialRaw); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(socialRaw, &b.Social); err != nil {
+			// si falla, igual devolvemos un map vacío
+			b.Social = map[string]string{}
+		}
+		bands = append(bands, b)
+		return nil
+	})
+	return bands, page, err
 }
 
 // UploadBandImage sube y procesa una imagen para una banda/artista.
@@ -125,45 +185,44 @@ func (h *AuthHandler) GetBandsCount(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} map[string]string "URL de la imagen subida"
 // @Failure 400 {string} string "Error en los parámetros o formato de imagen"
 // @Failure 500 {string} string "Error al procesar o guardar la imagen"
-// @Security BearerAuth
 // @Router /bands/upload-image [post]
 func (h *AuthHandler) UploadBandImage(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseMultipartForm(10 << 20) // 10MB max
 	if err != nil {
-		http.Error(w, "No se pudo procesar la imagen", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "No se pudo procesar la imagen")
 		return
 	}
 
 	file, handler, err := r.FormFile("file")
 	if err != nil {
-		http.Error(w, "No se pudo leer el archivo", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "No se pudo leer el archivo")
 		return
 	}
 	defer file.Close()
 
 	slug := r.FormValue("slug")
 	if slug == "" {
-		http.Error(w, "Slug es requerido", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Slug es requerido")
 		return
 	}
 
 	// Decodificamos la imagen
 	srcImage, _, err := image.Decode(file)
 	if err != nil {
-		http.Error(w, "Formato de imagen no válido", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Formato de imagen no válido")
 		return
 	}
 
 	// Crear carpeta temporal si no existe
 	if err := os.MkdirAll("tmp", os.ModePerm); err != nil {
-		http.Error(w, "No se pudo crear la carpeta temporal", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se pudo crear la carpeta temporal")
 		return
 	}
 
 	tmpFilePath := fmt.Sprintf("tmp/%s.jpg", slug)
 	out, err := os.Create(tmpFilePath)
 	if err != nil {
-		http.Error(w, "No se pudo crear archivo temporal", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se pudo crear archivo temporal")
 		return
 	}
 
@@ -172,7 +231,7 @@ func (h *AuthHandler) UploadBandImage(w http.ResponseWriter, r *http.Request) {
 
 	err = jpeg.Encode(out, srcImage, &jpeg.Options{Quality: 85})
 	if err != nil {
-		http.Error(w, "No se pudo convertir a JPG", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se pudo convertir a JPG")
 		return
 	}
 
@@ -180,7 +239,7 @@ func (h *AuthHandler) UploadBandImage(w http.ResponseWriter, r *http.Request) {
 	key := fmt.Sprintf("bands/%s.jpg", slug)
 	err = uploadToSpaces(tmpFilePath, key, "image/jpeg")
 	if err != nil {
-		http.Error(w, "Error al subir a Spaces: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al subir a Spaces", err)
 		return
 	}
 
@@ -193,113 +252,32 @@ func (h *AuthHandler) UploadBandImage(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// GetBandsDatatable obtiene datos de bandas formateados para DataTables.
-// Soporta paginación, búsqueda y ordenamiento.
+// GetBandsDatatable obtiene datos de bandas para la tabla del panel.
+// Soporta paginación, búsqueda, filtros por columna y ordenamiento.
 //
-// @Summary Obtiene bandas para DataTables
-// @Description Devuelve datos de bandas formateados para DataTables con soporte para paginación, búsqueda y ordenamiento
+// @Summary Obtiene bandas para la tabla del panel
+// @Description Devuelve bandas paginadas con búsqueda, filtros por columna y ordenamiento. Usa los mismos filtros que /bands/count
 // @Tags bands
 // @Accept json
 // @Produce json
-// @Param draw query int false "Parámetro draw de DataTables"
-// @Param start query int false "Índice de inicio para paginación"
-// @Param length query int false "Número de registros a devolver"
-// @Param search[value] query string false "Término de búsqueda"
-// @Param order[0][column] query int false "Índice de columna para ordenar"
-// @Param order[0][dir] query string false "Dirección de ordenamiento (asc/desc)"
-// @Success 200 {object} map[string]interface{} "Datos para DataTables"
-// @Failure 500 {string} string "Error al consultar datos"
+// @Param limit query int false "Cantidad de registros (por defecto 10, máximo 100)"
+// @Param offset query int false "Desplazamiento para paginación"
+// @Param cursor query string false "Cursor de la página siguiente (X-Next-Cursor)"
+// @Param total query bool false "Incluir el total en X-Total-Count"
+// @Param q query string false "Término de búsqueda"
+// @Param id query string false "Filtro por ID"
+// @Param name query string false "Filtro por nombre"
+// @Param slug query string false "Filtro por slug"
+// @Param sort query string false "Orden: hasta 3 columnas separadas por coma, con - o :desc para descendente (id, name, slug)"
+// @Param order query string false "Dirección cuando sort tiene una sola columna (asc/desc)"
+// @Param fields query string false "Campos a devolver, separados por coma (id, name, bio, slug, social, genres)"
+// @Param include query string false "Relaciones a incluir: genres"
+// @Success 200 {array} Band "Lista de bandas"
+// @Failure 400 {object} ErrorResponse "Parámetros de paginación inválidos"
+// @Failure 500 {object} ErrorResponse "Error al consultar datos"
 // @Router /bands/table [get]
 func (h *AuthHandler) GetBandsDatatable(w http.ResponseWriter, r *http.Request) {
-	offsetParam := r.URL.Query().Get("offset")
-	limitParam := r.URL.Query().Get("limit")
-	search := r.URL.Query().Get("q")
-	sortBy := r.URL.Query().Get("sort")
-	order := r.URL.Query().Get("order")
-
-	idFilter := r.URL.Query().Get("id")
-	nameFilter := r.URL.Query().Get("name")
-	slugFilter := r.URL.Query().Get("slug")
-
-	offset := 0
-	limit := 10
-	var err error
-
-	if offsetParam != "" {
-		offset, err = strconv.Atoi(offsetParam)
-		if err != nil {
-			http.Error(w, "Offset inválido", http.StatusBadRequest)
-			return
-		}
-	}
-	if limitParam != "" {
-		limit, err = strconv.Atoi(limitParam)
-		if err != nil {
-			http.Error(w, "Límite inválido", http.StatusBadRequest)
-			return
-		}
-	}
-
-	query := "SELECT id, name, bio, slug, social FROM bands WHERE 1=1"
-	var queryParams []interface{}
-
-	if search != "" {
-		pattern := "%" + search + "%"
-		query += " AND (name LIKE ? OR slug LIKE ? OR bio LIKE ?)"
-		queryParams = append(queryParams, pattern, pattern, pattern)
-	}
-	if idFilter != "" {
-		query += " AND id LIKE ?"
-		queryParams = append(queryParams, "%"+idFilter+"%")
-	}
-	if nameFilter != "" {
-		query += " AND name LIKE ?"
-		queryParams = append(queryParams, "%"+nameFilter+"%")
-	}
-	if slugFilter != "" {
-		query += " AND slug LIKE ?"
-		queryParams = append(queryParams, "%"+slugFilter+"%")
-	}
-
-	if sortBy != "" {
-		validSorts := map[string]bool{"id": true, "name": true, "slug": true}
-		if validSorts[sortBy] {
-			if order != "desc" {
-				order = "asc"
-			}
-			query += fmt.Sprintf(" ORDER BY %s %s", sortBy, strings.ToUpper(order))
-		}
-	}
-
-	query += " LIMIT ? OFFSET ?"
-	queryParams = append(queryParams, limit, offset)
-
-	rows, err := h.DB.Select(query, queryParams...)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	var bands []Band
-	for rows.Next() {
-		var b Band
-		var socialRaw []byte
-
-		if err := rows.Scan(&b.ID, &b.Name, &b.Bio, &b.Slug, &socialRaw); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		if err := json.Unmarshal(socialRaw, &b.Social); err != nil {
-			b.Social = map[string]string{}
-		}
-
-		bands = append(bands, b)
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(bands)
+	h.writeBandPage(w, r)
 }
 
 // GetBands obtiene una lista de bandas/artistas con soporte para filtrado y paginación.
@@ -309,92 +287,21 @@ func (h *AuthHandler) GetBandsDatatable(w http.ResponseWriter, r *http.Request)
 // @Tags bands
 // @Accept json
 // @Produce json
-// @Param limit query int false "Límite de resultados (por defecto 20)"
+// @Param limit query int false "Límite de resultados (por defecto 10, máximo 100)"
 // @Param offset query int false "Desplazamiento para paginación"
-// @Param search query string false "Término de búsqueda"
+// @Param cursor query string false "Cursor de la página siguiente (X-Next-Cursor)"
+// @Param total query bool false "Incluir el total en X-Total-Count"
+// @Param q query string false "Término de búsqueda"
+// @Param sort query string false "Orden: hasta 3 columnas separadas por coma, con - o :desc para descendente (id, name, slug)"
+// @Param order query string false "Dirección cuando sort tiene una sola columna (asc/desc)"
+// @Param fields query string false "Campos a devolver, separados por coma (id, name, bio, slug, social, genres)"
+// @Param include query string false "Relaciones a incluir: genres"
 // @Success 200 {array} Band "Lista de bandas"
+// @Failure 400 {object} ErrorResponse "Parámetros de paginación inválidos"
 // @Failure 500 {string} string "Error al consultar bandas"
 // @Router /bands [get]
 func (h *AuthHandler) GetBands(w http.ResponseWriter, r *http.Request) {
-	offsetParam := r.URL.Query().Get("offset")
-	limitParam := r.URL.Query().Get("limit")
-	search := r.URL.Query().Get("q")
-	sortBy := r.URL.Query().Get("sort")
-	order := r.URL.Query().Get("order")
-
-	offset := 0
-	limit := 10
-	var err error
-
-	if offsetParam != "" {
-		offset, err = strconv.Atoi(offsetParam)
-		if err != nil {
-			http.Error(w, "Offset inválido", http.StatusBadRequest)
-			return
-		}
-	}
-
-	if limitParam != "" {
-		limit, err = strconv.Atoi(limitParam)
-		if err != nil {
-			http.Error(w, "Límite inválido", http.StatusBadRequest)
-			return
-		}
-	}
-
-	query := "SELECT id, name, bio, slug, social FROM bands WHERE 1=1"
-	var queryParams []interface{}
-
-	if search != "" {
-		query += " AND (name LIKE ? OR bio LIKE ? OR slug LIKE ? OR social LIKE ?)"
-		searchPattern := "%" + search + "%"
-		queryParams = append(queryParams, searchPattern, searchPattern, searchPattern, searchPattern)
-	}
-
-	if sortBy != "" {
-		validSorts := map[string]bool{"id": true, "name": true, "slug": true}
-		if validSorts[sortBy] {
-			if order != "desc" {
-				order = "asc"
-			}
-			query += fmt.Sprintf(" ORDER BY %s %s", sortBy, strings.ToUpper(order))
-		}
-	} else {
-		// Por defecto, ordenar por ID descendente (del más nuevo al más viejo)
-		query += " ORDER BY id DESC"
-	}
-
-	query += " LIMIT ? OFFSET ?"
-	queryParams = append(queryParams, limit, offset)
-
-	rows, err := h.DB.Select(query, queryParams...)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	var bands []Band
-	for rows.Next() {
-		var b Band
-		var socialRaw []byte
-
-		if err := rows.Scan(&b.ID, &b.Name, &b.Bio, &b.Slug, &socialRaw); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		if err := json.Unmarshal(socialRaw, &b.Social); err != nil {
-
-			// si falla, igual devolvemos un map vacío
-			b.Social = map[string]string{}
-		}
-
-		bands = append(bands, b)
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(bands)
+	h.writeBandPage(w, r)
 }
 
 // GetBandByID obtiene los detalles de una banda/artista específico por su ID.
@@ -417,38 +324,41 @@ func (h *AuthHandler) GetBandByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	var b Band
 	var socialRaw []byte
+	var updatedAt string
 
 	// allow to id could be a slug
 	if _, err := strconv.Atoi(id); err != nil {
 		// es un slug, buscamos por slug
-		row, err := h.DB.SelectRow("SELECT id, name, bio, slug, social FROM bands WHERE slug = ?", id)
+		row, err := h.DB.SelectRow("SELECT id, name, bio, slug, social, updated_at FROM bands WHERE slug = ?", id)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Error al consultar la base de datos: " + err.Error()})
+			writeError(w, r, http.StatusInternalServerError, "Error al consultar la base de datos", err)
 			return
 		}
-		err = row.Scan(&b.ID, &b.Name, &b.Bio, &b.Slug, &socialRaw)
+		err = row.Scan(&b.ID, &b.Name, &b.Bio, &b.Slug, &socialRaw, &updatedAt)
 		if err != nil {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Artista no encontrado con slug: " + id})
+			if h.redirectOldSlug(w, r, "band", id) {
+				return
+			}
+			writeError(w, r, http.StatusNotFound, "Artista no encontrado con slug: "+id)
 			return
 		}
 	} else {
-		row, err := h.DB.SelectRow("SELECT id, name, bio, slug, social FROM bands WHERE id = ?", id)
+		row, err := h.DB.SelectRow("SELECT id, name, bio, slug, social, updated_at FROM bands WHERE id = ?", id)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Error al consultar la base de datos: " + err.Error()})
+			writeError(w, r, http.StatusInternalServerError, "Error al consultar la base de datos", err)
 			return
 		}
 
-		err = row.Scan(&b.ID, &b.Name, &b.Bio, &b.Slug, &socialRaw)
+		err = row.Scan(&b.ID, &b.Name, &b.Bio, &b.Slug, &socialRaw, &updatedAt)
 		if err != nil {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Artista no encontrado con ID: " + id})
+			writeError(w, r, http.StatusNotFound, "Artista no encontrado con ID: "+id)
 			return
 		}
 	}
 
+	h.countView("bands", b.ID)
+	setLastModified(w, updatedAt)
+
 	// Inicializar Social como un mapa vacío si es nil
 	b.Social = map[string]string{}
 
@@ -484,18 +394,25 @@ func (h *AuthHandler) GetBandByID(w http.ResponseWriter, r *http.Request) {
 // @Success 201 {object} Band "Banda creada"
 // @Failure 400 {string} string "Datos inválidos"
 // @Failure 500 {string} string "Error al crear la banda"
-// @Security BearerAuth
 // @Router /bands [post]
 func (h *AuthHandler) CreateBand(w http.ResponseWriter, r *http.Request) {
 	var b Band
 	json.NewDecoder(r.Body).Decode(&b)
+	slug, err := h.assignSlug("band", b.Slug, b.Name, "")
+	if err != nil {
+		writeSlugError(w, r, err)
+		return
+	}
+	b.Slug = slug
+	defer h.releaseSlug("band", slug)
 	socialJSON, _ := json.Marshal(b.Social)
 	id, err := h.DB.Insert(false, "INSERT INTO bands (name, bio, slug, social) VALUES (?, ?, ?, ?)", b.Name, b.Bio, b.Slug, string(socialJSON))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	b.ID = int(id)
+	h.bus.Publish(BandCreated{Actor: actorFrom(r), Band: b, Origin: OriginAPI})
 	json.NewEncoder(w).Encode(b)
 }
 
@@ -512,7 +429,6 @@ func (h *AuthHandler) CreateBand(w http.ResponseWriter, r *http.Request) {
 // @Failure 400 {string} string "ID o datos inválidos"
 // @Failure 404 {string} string "Banda no encontrada"
 // @Failure 500 {string} string "Error al actualizar la banda"
-// @Security BearerAuth
 // @Router /bands/{id} [put]
 func (h *AuthHandler) UpdateBand(w http.ResponseWriter, r *http.Request) {
 	// Configurar encabezados para JSON
@@ -522,19 +438,28 @@ func (h *AuthHandler) UpdateBand(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
 	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Error al decodificar el cuerpo: " + err.Error()})
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar el cuerpo", err)
 		return
 	}
 
 	socialJSON, _ := json.Marshal(b.Social)
 
-	_, err := h.DB.Update(false, "UPDATE bands SET name=?, bio=?, slug=?, social=? WHERE id=?", b.Name, b.Bio, b.Slug, string(socialJSON), id)
+	prevSlug := h.currentSlug("band", id)
+	slug, err := h.updateSlug("band", prevSlug, b.Slug, b.Name, "")
+	if err != nil {
+		writeSlugError(w, r, err)
+		return
+	}
+	b.Slug = slug
+	defer h.releaseSlug("band", slug)
+	_, err = h.DB.Update(false, "UPDATE bands SET name=?, bio=?, slug=?, social=? WHERE id=?", b.Name, b.Bio, b.Slug, string(socialJSON), id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Error al actualizar el artista: " + err.Error()})
+		writeError(w, r, http.StatusInternalServerError, "Error al actualizar el artista", err)
 		return
 	}
+	h.recordSlugChange("band", prevSlug, b.Slug)
+	b.ID = prevSlug.ID
+	h.bus.Publish(BandUpdated{Actor: actorFrom(r), Band: b})
 
 	// Obtener los datos actualizados para devolverlos en la respuesta
 	var updatedBand Band
@@ -542,15 +467,13 @@ func (h *AuthHandler) UpdateBand(w http.ResponseWriter, r *http.Request) {
 
 	row, err := h.DB.SelectRow("SELECT id, name, bio, slug, social FROM bands WHERE id = ?", id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Artista actualizado pero no se pudo recuperar la información actualizada"})
+		writeError(w, r, http.StatusInternalServerError, "Artista actualizado pero no se pudo recuperar la información actualizada")
 		return
 	}
 
 	err = row.Scan(&updatedBand.ID, &updatedBand.Name, &updatedBand.Bio, &updatedBand.Slug, &socialRaw)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Artista actualizado pero no se pudo recuperar la información actualizada"})
+		writeError(w, r, http.StatusInternalServerError, "Artista actualizado pero no se pudo recuperar la información actualizada")
 		return
 	}
 
@@ -576,28 +499,30 @@ func (h *AuthHandler) DeleteBand(w http.ResponseWriter, r *http.Request) {
 	// Primero eliminar las referencias en artist_links
 	_, err := h.DB.Delete(false, "DELETE FROM artist_links WHERE artist_id = ?", id)
 	if err != nil {
-		http.Error(w, "Error al eliminar referencias de artist_links: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al eliminar referencias de artist_links", err)
 		return
 	}
 
 	// Eliminar las referencias en events_bands si existen
 	_, err = h.DB.Delete(false, "DELETE FROM events_bands WHERE id_band = ?", id)
 	if err != nil {
-		http.Error(w, "Error al eliminar referencias de events_bands: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al eliminar referencias de events_bands", err)
 		return
 	}
 
 	// Finalmente eliminar la banda
 	result, err := h.DB.Delete(false, "DELETE FROM bands WHERE id = ?", id)
 	if err != nil {
-		http.Error(w, "Error al eliminar la banda: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al eliminar la banda", err)
 		return
 	}
 
 	if result == 0 {
-		http.Error(w, "No se encontró la banda", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, "No se encontró la banda")
 		return
 	}
+	bandID, _ := strconv.Atoi(id)
+	h.removeFromSearch("band", bandID)
 
 	// Respuesta exitosa
 	w.WriteHeader(http.StatusOK)
@@ -629,23 +554,20 @@ func (h *AuthHandler) GetUserBands(w http.ResponseWriter, r *http.Request) {
 	// Verificar que el usuario autenticado tenga permiso para ver estos artistas
 	claims, ok := r.Context().Value("user").(*models.Claims)
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Usuario no autenticado"})
+		writeError(w, r, http.StatusUnauthorized, "Usuario no autenticado")
 		return
 	}
 
 	// Convertir userID de string a uint para comparar con claims.UserID
 	userIDUint, err := strconv.ParseUint(userID, 10, 32)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "ID de usuario inválido"})
+		writeError(w, r, http.StatusBadRequest, "ID de usuario inválido")
 		return
 	}
 
 	// Solo permitir acceso si es el mismo usuario o es admin
 	if claims.UserID != uint(userIDUint) && claims.Role != "admin" {
-		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(map[string]string{"error": "No tienes permiso para ver estos artistas"})
+		writeError(w, r, http.StatusForbidden, "No tienes permiso para ver estos artistas")
 		return
 	}
 
@@ -658,8 +580,7 @@ func (h *AuthHandler) GetUserBands(w http.ResponseWriter, r *http.Request) {
 		ORDER BY b.name ASC`, userID)
 
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Error al consultar los artistas: " + err.Error()})
+		writeError(w, r, http.StatusInternalServerError, "Error al consultar los artistas", err)
 		return
 	}
 	defer rows.Close()
@@ -695,51 +616,23 @@ func (h *AuthHandler) GetUserBands(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(bands)
 }
 
-// SearchBands busca artistas por nombre
+// SearchBands busca artistas por nombre para el selector de bandas.
+// Usa el índice en memoria del autocompletado (ver Autocomplete).
 func (h *AuthHandler) SearchBands(w http.ResponseWriter, r *http.Request) {
-	// Establecer encabezados CORS
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-	// Manejar solicitudes OPTIONS
-	if r.Method == "OPTIONS" {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
-
 	// Obtener el término de búsqueda
 	query := r.URL.Query().Get("q")
 	if query == "" {
-		http.Error(w, "Parámetro de búsqueda 'q' requerido", http.StatusBadRequest)
-		return
-	}
-
-	// Buscar artistas que coincidan con el nombre
-	rows, err := h.DB.Select(
-		`SELECT id, name, slug FROM bands 
-		WHERE name LIKE ? 
-		ORDER BY name ASC LIMIT 10`,
-		"%"+query+"%",
-	)
-	if err != nil {
-		http.Error(w, "Error al buscar artistas: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusBadRequest, "Parámetro de búsqueda 'q' requerido")
 		return
 	}
-	defer rows.Close()
 
 	// Construir la respuesta
-	var bands []map[string]interface{}
-	for rows.Next() {
-		var id int
-		var name, slug string
-		if err := rows.Scan(&id, &name, &slug); err != nil {
-			continue
-		}
+	bands := []map[string]interface{}{}
+	for _, e := range h.suggest(query, map[string]bool{"band": true}, 10) {
 		bands = append(bands, map[string]interface{}{
-			"id":   id,
-			"name": name,
-			"slug": slug,
+			"id":   e.ID,
+			"name": e.Name,
+			"slug": e.Slug,
 		})
 	}
 
@@ -758,7 +651,7 @@ func (h *AuthHandler) GenerateArtistBio(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		http.Error(w, "Error al leer el cuerpo de la solicitud", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Error al leer el cuerpo de la solicitud")
 		return
 	}
 
@@ -792,14 +685,14 @@ Información proporcionada: %s`, requestData.Name, requestData.CustomPrompt)
 
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
-		http.Error(w, "Error al preparar la solicitud", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al preparar la solicitud")
 		return
 	}
 
 	// Crear la solicitud HTTP
 	req, err := http.NewRequest("POST", openaiURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		http.Error(w, "Error al crear la solicitud", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al crear la solicitud")
 		return
 	}
 
@@ -811,7 +704,7 @@ Información proporcionada: %s`, requestData.Name, requestData.CustomPrompt)
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		http.Error(w, "Error al realizar la solicitud a OpenAI", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al realizar la solicitud a OpenAI")
 		return
 	}
 	defer resp.Body.Close()
@@ -826,13 +719,13 @@ Información proporcionada: %s`, requestData.Name, requestData.CustomPrompt)
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&openaiResponse); err != nil {
-		http.Error(w, "Error al leer la respuesta de OpenAI", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al leer la respuesta de OpenAI")
 		return
 	}
 
 	// Verificar que hay una respuesta
 	if len(openaiResponse.Choices) == 0 {
-		http.Error(w, "No se recibió respuesta de OpenAI", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se recibió respuesta de OpenAI")
 		return
 	}
 
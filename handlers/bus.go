@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// DomainEvent es un hecho del dominio (una banda creada, una submission aprobada...) que los
+// handlers publican en el bus. Los eventos se publican por valor.
+type DomainEvent interface {
+	EventName() string
+}
+
+// EventBus reparte los eventos de dominio a sus suscriptores dentro del proceso. Los síncronos
+// corren en el orden en que se registraron antes de que Publish vuelva; los asíncronos, cada uno
+// en su goroutine. Un suscriptor que entra en pánico queda registrado en el log y no afecta al
+// handler que publicó ni al resto de los suscriptores.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]subscriber
+	pending     sync.WaitGroup
+}
+
+type subscriber struct {
+	name  string
+	async bool
+	fn    func(DomainEvent)
+}
+
+// allEvents es la clave de los suscriptores a todos los eventos (auditoría)
+const allEvents = "*"
+
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: map[string][]subscriber{}}
+}
+
+// Subscribe registra un suscriptor síncrono para los eventos de tipo E. Conviene para lo que la
+// respuesta tiene que ver ya hecho, como el índice de búsqueda.
+func Subscribe[E DomainEvent](bus *EventBus, name string, fn func(E)) {
+	bus.add(eventName[E](), subscriber{name: name, fn: typed(fn)})
+}
+
+// SubscribeAsync registra un suscriptor para los eventos de tipo E que corre en segundo plano,
+// para lo que depende de servicios externos (WhatsApp, redes sociales).
+func SubscribeAsync[E DomainEvent](bus *EventBus, name string, fn func(E)) {
+	bus.add(eventName[E](), subscriber{name: name, async: true, fn: typed(fn)})
+}
+
+// SubscribeAll registra un suscriptor asíncrono para todos los eventos
+func (b *EventBus) SubscribeAll(name string, fn func(DomainEvent)) {
+	b.add(allEvents, subscriber{name: name, async: true, fn: fn})
+}
+
+func eventName[E DomainEvent]() string {
+	var zero E
+	return zero.EventName()
+}
+
+func typed[E DomainEvent](fn func(E)) func(DomainEvent) {
+	return func(e DomainEvent) {
+		if ev, ok := e.(E); ok {
+			fn(ev)
+		}
+	}
+}
+
+func (b *EventBus) add(event string, s subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[event] = append(b.subscribers[event], s)
+}
+
+// Publish entrega el evento a sus suscriptores
+func (b *EventBus) Publish(e DomainEvent) {
+	b.mu.RLock()
+	subs := make([]subscriber, 0, len(b.subscribers[e.EventName()])+len(b.subscribers[allEvents]))
+	subs = append(subs, b.subscribers[e.EventName()]...)
+	subs = append(subs, b.subscribers[allEvents]...)
+	b.mu.RUnlock()
+
+	for _, s := range subs {
+		if !s.async {
+			b.deliver(s, e)
+			continue
+		}
+		b.pending.Add(1)
+		go func(s subscriber) {
+			defer b.pending.Done()
+			b.deliver(s, e)
+		}(s)
+	}
+}
+
+func (b *EventBus) deliver(s subscriber, e DomainEvent) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			fmt.Printf("[Warning] El suscriptor %q de %s falló: %v\n%s\n", s.name, e.EventName(), rec, debug.Stack())
+		}
+	}()
+	s.fn(e)
+}
+
+// Wait espera a que terminen los suscriptores asíncronos en curso
+func (b *EventBus) Wait() {
+	b.pending.Wait()
+}
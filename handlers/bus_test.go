@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testEvent struct{ N int }
+
+func (testEvent) EventName() string { return "test.event" }
+
+type otherTestEvent struct{}
+
+func (otherTestEvent) EventName() string { return "test.other" }
+
+func TestEventBusSyncOrder(t *testing.T) {
+	b := NewEventBus()
+	var got []string
+	Subscribe(b, "primero", func(e testEvent) { got = append(got, "primero") })
+	Subscribe(b, "segundo", func(e testEvent) { got = append(got, "segundo") })
+	Subscribe(b, "tercero", func(e testEvent) { got = append(got, "tercero") })
+	Subscribe(b, "otro evento", func(e otherTestEvent) { got = append(got, "otro") })
+
+	b.Publish(testEvent{})
+
+	want := []string{"primero", "segundo", "tercero"}
+	if len(got) != len(want) {
+		t.Fatalf("suscriptores ejecutados = %v, se esperaba %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("suscriptores ejecutados = %v, se esperaba %v", got, want)
+		}
+	}
+}
+
+func TestEventBusAsyncWait(t *testing.T) {
+	b := NewEventBus()
+	var done atomic.Int32
+	release := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		SubscribeAsync(b, "lento", func(e testEvent) {
+			<-release
+			done.Add(1)
+		})
+	}
+
+	b.Publish(testEvent{}) // no tiene que bloquearse esperando a los asíncronos
+	if n := done.Load(); n != 0 {
+		t.Fatalf("%d suscriptores asíncronos terminaron antes de liberarlos", n)
+	}
+
+	close(release)
+	waited := make(chan struct{})
+	go func() {
+		b.Wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("Wait no volvió")
+	}
+	if n := done.Load(); n != 3 {
+		t.Fatalf("Wait volvió con %d de 3 suscriptores terminados", n)
+	}
+}
+
+func TestEventBusPanicIsolation(t *testing.T) {
+	b := NewEventBus()
+	var mu sync.Mutex
+	var got []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, name)
+	}
+	Subscribe(b, "roto", func(e testEvent) { panic("falla síncrona") })
+	Subscribe(b, "sano", func(e testEvent) { record("sano") })
+	SubscribeAsync(b, "roto asíncrono", func(e testEvent) { panic("falla asíncrona") })
+	SubscribeAsync(b, "sano asíncrono", func(e testEvent) { record("sano asíncrono") })
+
+	func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				t.Fatalf("el pánico de un suscriptor llegó a quien publicó: %v", rec)
+			}
+		}()
+		b.Publish(testEvent{})
+	}()
+	b.Wait()
+
+	if len(got) != 2 {
+		t.Fatalf("suscriptores ejecutados = %v, se esperaban los dos sanos", got)
+	}
+}
+
+func TestEventBusSubscribeAll(t *testing.T) {
+	b := NewEventBus()
+	var mu sync.Mutex
+	var got []string
+	b.SubscribeAll("auditoría", func(e DomainEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, e.EventName())
+	})
+
+	b.Publish(testEvent{N: 1})
+	b.Publish(otherTestEvent{})
+	b.Publish(testEvent{N: 2})
+	b.Wait()
+
+	counts := map[string]int{}
+	for _, name := range got {
+		counts[name]++
+	}
+	if counts["test.event"] != 2 || counts["test.other"] != 1 {
+		t.Fatalf("SubscribeAll recibió %v", got)
+	}
+}
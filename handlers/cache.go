@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"brotecolectivo/database"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Entidades cuyas lecturas se guardan en el caché de respuestas. Una escritura sobre una de ellas
+// invalida todas las respuestas que dependen de esa entidad.
+var cachedEntities = []string{"bands", "events", "venues", "news", "series", "songs", "videos"}
+
+const (
+	maxCacheEntries = 2000
+	maxCachedBody   = 1 << 20 // las respuestas más grandes se sirven siempre desde la base
+
+	// Cada cuánto se leen las invalidaciones hechas por otras instancias
+	cacheSyncInterval = 2 * time.Second
+)
+
+// responseCache guarda en memoria las respuestas de las lecturas públicas. Cada entrada recuerda
+// la versión de las entidades de las que depende: una escritura sube la versión de su entidad y
+// las entradas que la usaban dejan de servirse. El vencimiento (max-age de la ruta) cubre los
+// cambios hechos por fuera de la API.
+//
+// Con varias instancias, las invalidaciones también se anotan en la tabla cache_versions y cada
+// instancia la lee cada cacheSyncInterval (ver StartCacheSync).
+type responseCache struct {
+	mu       sync.Mutex
+	db       *database.DatabaseStruct
+	entries  map[string]*cachedResponse
+	versions map[string]uint64
+	remote   map[string]uint64 // última versión vista en cache_versions
+}
+
+type cachedResponse struct {
+	header   http.Header
+	body     []byte
+	versions []uint64 // en el mismo orden que las entidades de la ruta
+	expires  time.Time
+}
+
+func newResponseCache(db *database.DatabaseStruct) *responseCache {
+	return &responseCache{
+		db:       db,
+		entries:  map[string]*cachedResponse{},
+		versions: map[string]uint64{},
+		remote:   map[string]uint64{},
+	}
+}
+
+// snapshot devuelve la versión actual de cada entidad
+func (c *responseCache) snapshot(entities []string) []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	versions := make([]uint64, len(entities))
+	for i, e := range entities {
+		versions[i] = c.versions[e]
+	}
+	return versions
+}
+
+// get devuelve la respuesta guardada si no venció y ninguna de sus entidades cambió
+func (c *responseCache) get(key string, entities []string) *cachedResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	fresh := time.Now().Before(entry.expires)
+	for i, e := range entities {
+		fresh = fresh && entry.versions[i] == c.versions[e]
+	}
+	if !fresh {
+		delete(c.entries, key)
+		return nil
+	}
+	return entry
+}
+
+func (c *responseCache) put(key string, entry *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		now := time.Now()
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		// Si siguen sin lugar se vacía: es más simple que llevar un LRU y se vuelve a llenar solo
+		if len(c.entries) >= maxCacheEntries {
+			c.entries = map[string]*cachedResponse{}
+		}
+	}
+	c.entries[key] = entry
+}
+
+// invalidate sube la versión de las entidades indicadas (de todas si no se indica ninguna)
+// y la publica en cache_versions para que la vean las demás instancias
+func (c *responseCache) invalidate(entities ...string) {
+	if len(entities) == 0 {
+		entities = cachedEntities
+	}
+	c.mu.Lock()
+	for _, e := range entities {
+		c.versions[e]++
+	}
+	c.mu.Unlock()
+
+	if c.db == nil {
+		return
+	}
+	values := make([]string, len(entities))
+	args := make([]interface{}, len(entities))
+	for i, e := range entities {
+		values[i] = "(?, 1)"
+		args[i] = e
+	}
+	_, err := c.db.Exec(`
+		INSERT INTO cache_versions (entity, version) VALUES `+strings.Join(values, ", ")+`
+		ON DUPLICATE KEY UPDATE version = version + 1`, args...)
+	if err != nil {
+		fmt.Printf("[Warning] No se pudo publicar la invalidación del caché: %v\n", err)
+	}
+}
+
+// syncVersions lee cache_versions y descarta las entradas de las entidades que otra instancia
+// invalidó. Las invalidaciones propias también se ven acá y cuestan un descarte extra.
+func (c *responseCache) syncVersions() error {
+	rows, err := c.db.Select("SELECT entity, version FROM cache_versions")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for rows.Next() {
+		var entity string
+		var version uint64
+		if err := rows.Scan(&entity, &version); err != nil {
+			return err
+		}
+		if c.remote[entity] != version {
+			c.remote[entity] = version
+			c.versions[entity]++
+		}
+	}
+	return rows.Err()
+}
+
+// StartCacheSync carga las versiones de cache_versions y las vuelve a leer periódicamente, así
+// una escritura en cualquier instancia invalida el caché de todas en unos segundos
+func (h *AuthHandler) StartCacheSync() {
+	if err := h.cache.syncVersions(); err != nil {
+		fmt.Printf("[Warning] No se pudieron leer las versiones del caché: %v\n", err)
+	}
+	go func() {
+		ticker := time.NewTicker(cacheSyncInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := h.cache.syncVersions(); err != nil {
+				fmt.Printf("[Warning] No se pudieron leer las versiones del caché: %v\n", err)
+			}
+		}
+	}()
+}
+
+// responseRecorder junta la respuesta del handler para calcular el ETag antes de enviarla
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) Header() http.Header { return rec.header }
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(b)
+}
+
+// CacheControl agrega a una lectura pública ETag, Cache-Control con el max-age indicado y
+// respuestas 304 a If-None-Match / If-Modified-Since (este último si el handler puso Last-Modified).
+// El handler se ejecuta siempre: sirve para las rutas que además registran algo en cada visita.
+func CacheControl(maxAge time.Duration) func(http.Handler) http.Handler {
+	return cacheMiddleware(nil, maxAge, nil)
+}
+
+// Cached es CacheControl más el caché de respuestas en memoria. entities son las entidades que
+// aparecen en la respuesta: una escritura sobre cualquiera de ellas (ver Invalidates) la descarta.
+// Solo se guardan respuestas 200 de pedidos sin Authorization.
+func (h *AuthHandler) Cached(maxAge time.Duration, entities ...string) func(http.Handler) http.Handler {
+	return cacheMiddleware(h.cache, maxAge, entities)
+}
+
+func cacheMiddleware(cache *responseCache, maxAge time.Duration, entities []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+			public := r.Header.Get("Authorization") == ""
+			cacheControl := fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+			if !public {
+				// Con sesión (panel de administración) se revalida siempre, pero el 304 sigue sirviendo
+				cacheControl = "private, no-cache"
+			}
+
+			key := r.URL.RequestURI()
+			useCache := cache != nil && public
+			if useCache {
+				if entry := cache.get(key, entities); entry != nil {
+					w.Header().Set("X-Cache", "HIT")
+					writeConditional(w, r, http.StatusOK, entry.header, entry.body, cacheControl)
+					return
+				}
+			}
+
+			var versions []uint64
+			if useCache {
+				// Las versiones se toman antes de consultar: si hay una escritura en el medio, la entrada ya nace vencida
+				versions = cache.snapshot(entities)
+			}
+			rec := &responseRecorder{header: http.Header{}}
+			next.ServeHTTP(rec, r)
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+
+			if useCache && rec.status == http.StatusOK && rec.body.Len() <= maxCachedBody {
+				cache.put(key, &cachedResponse{
+					header:   rec.header.Clone(),
+					body:     bytes.Clone(rec.body.Bytes()),
+					versions: versions,
+					expires:  time.Now().Add(maxAge),
+				})
+				w.Header().Set("X-Cache", "MISS")
+			}
+			writeConditional(w, r, rec.status, rec.header, rec.body.Bytes(), cacheControl)
+		})
+	}
+}
+
+// writeConditional envía una respuesta ya armada, o 304 si el cliente tiene esa misma versión.
+// Solo las respuestas 200 llevan ETag y Cache-Control.
+func writeConditional(w http.ResponseWriter, r *http.Request, status int, header http.Header, body []byte, cacheControl string) {
+	for k, v := range header {
+		w.Header()[k] = v
+	}
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		w.Write(body)
+		return
+	}
+
+	etag := header.Get("ETag")
+	if etag == "" {
+		// Débil porque la compresión cambia los bytes que viajan, no el contenido
+		sum := sha256.Sum256(body)
+		etag = `W/"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+	}
+	if header.Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", cacheControl)
+	}
+
+	if notModified(r, etag, header.Get("Last-Modified")) {
+		w.Header().Del("Content-Type")
+		w.Header().Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// notModified aplica las precondiciones de un GET: If-None-Match tiene prioridad y, si no vino,
+// se usa If-Modified-Since contra Last-Modified.
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || lastModified == "" {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	return err == nil && !modified.After(ims)
+}
+
+// Invalidates descarta del caché las respuestas que dependen de las entidades indicadas (o de
+// todas si no se indica ninguna) cuando una escritura (POST, PUT, PATCH, DELETE) sale bien.
+func (h *AuthHandler) Invalidates(entities ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+			if ww.Status() < http.StatusBadRequest {
+				h.cache.invalidate(entities...)
+			}
+		})
+	}
+}
+
+// setLastModified pone Last-Modified a partir de una columna updated_at ("2006-01-02 15:04:05")
+func setLastModified(w http.ResponseWriter, updatedAt string) {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", updatedAt, time.Local)
+	if err != nil {
+		return
+	}
+	w.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duración que se asume para un evento sin fecha de fin (o con fin anterior al inicio)
+const defaultEventDuration = 3 * time.Hour
+
+// Formatos de fecha que llegan desde el panel, los formularios y la base de datos
+var eventDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// EventConflict describe un evento existente que se superpone en el mismo venue.
+//
+// @Schema
+type EventConflict struct {
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	Slug      string `json:"slug"`
+	DateStart string `json:"date_start"`
+	DateEnd   string `json:"date_end"`
+}
+
+// SubmissionWarning es una advertencia que se muestra en la cola de moderación.
+//
+// @Schema
+type SubmissionWarning struct {
+	Type       string           `json:"type"`
+	Message    string           `json:"message"`
+	Conflicts  []EventConflict  `json:"conflicts,omitempty"`
+	Duplicates []DuplicateMatch `json:"duplicates,omitempty"`
+}
+
+// parseEventDate intenta interpretar una fecha de evento en los formatos conocidos
+func parseEventDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range eventDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("fecha inválida: %s", value)
+}
+
+// eventTimeRange devuelve el rango [inicio, fin) de un evento, completando el fin si falta
+func eventTimeRange(dateStart, dateEnd string) (time.Time, time.Time, error) {
+	start, err := parseEventDate(dateStart)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parseEventDate(dateEnd)
+	if err != nil || !end.After(start) {
+		end = start.Add(defaultEventDuration)
+	}
+	return start, end, nil
+}
+
+// findVenueConflicts busca eventos del mismo venue cuyo horario se superpone con el rango indicado.
+// excludeID permite ignorar el propio evento al editarlo (0 para no excluir ninguno).
+func (h *AuthHandler) findVenueConflicts(venueID int, dateStart, dateEnd string, excludeID int) ([]EventConflict, error) {
+	conflicts := []EventConflict{}
+	if venueID <= 0 {
+		return conflicts, nil
+	}
+
+	start, end, err := eventTimeRange(dateStart, dateEnd)
+	if err != nil {
+		// Sin fecha válida no podemos detectar superposiciones
+		return conflicts, nil
+	}
+
+	rows, err := h.DB.Select(`
+		SELECT id, title, slug, date_start, date_end
+		FROM events
+		WHERE id_venue = ? AND id <> ?
+		  AND date_start < ?
+		  AND ? < IF(date_end IS NULL OR date_end <= date_start,
+		             DATE_ADD(date_start, INTERVAL ? SECOND), date_end)
+		ORDER BY date_start`,
+		venueID, excludeID,
+		end.Format("2006-01-02 15:04:05"),
+		start.Format("2006-01-02 15:04:05"),
+		int(defaultEventDuration.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c EventConflict
+		var dateEndValue sql.NullString
+		if err := rows.Scan(&c.ID, &c.Title, &c.Slug, &c.DateStart, &dateEndValue); err != nil {
+			return nil, err
+		}
+		c.DateEnd = dateEndValue.String
+		conflicts = append(conflicts, c)
+	}
+
+	return conflicts, nil
+}
+
+// conflictIDs devuelve los IDs de los eventos en conflicto como texto, ej: "#12, #15"
+func conflictIDs(conflicts []EventConflict) string {
+	ids := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		ids = append(ids, "#"+strconv.Itoa(c.ID))
+	}
+	return strings.Join(ids, ", ")
+}
+
+// writeVenueConflict responde 409 con la lista de eventos que se superponen
+func writeVenueConflict(w http.ResponseWriter, r *http.Request, conflicts []EventConflict) {
+	writeAPIError(w, r, &APIError{
+		Status:  http.StatusConflict,
+		Code:    ErrCodeVenueConflict,
+		Message: "Ya existen eventos en ese venue con horario superpuesto",
+		Extra: map[string]interface{}{
+			"conflicts": conflicts,
+			"hint":      "Un administrador puede forzar la operación con ?force=true",
+		},
+	})
+}
+
+// eventSubmissionConflicts detecta superposiciones para los datos de una submission de tipo event
+func (h *AuthHandler) eventSubmissionConflicts(dataRaw []byte) []EventConflict {
+	var data struct {
+		IDVenue   interface{} `json:"id_venue"`
+		DateStart string      `json:"date_start"`
+		DateEnd   string      `json:"date_end"`
+	}
+	if err := json.Unmarshal(dataRaw, &data); err != nil {
+		return nil
+	}
+
+	// id_venue puede llegar como número o como texto desde el formulario
+	var venueID int
+	switch v := data.IDVenue.(type) {
+	case float64:
+		venueID = int(v)
+	case string:
+		venueID, _ = strconv.Atoi(v)
+	}
+
+	conflicts, err := h.findVenueConflicts(venueID, data.DateStart, data.DateEnd, 0)
+	if err != nil {
+		fmt.Printf("[Warning] No se pudieron verificar conflictos de horario: %v\n", err)
+		return nil
+	}
+	return conflicts
+}
+
+// submissionWarnings calcula las advertencias para mostrar en la cola de moderación.
+// finder se comparte entre las submissions de un mismo listado.
+func (h *AuthHandler) submissionWarnings(s Submission, finder *duplicateFinder) []SubmissionWarning {
+	warnings := []SubmissionWarning{}
+	if s.Status != "pending" {
+		return warnings
+	}
+
+	if s.Type == "event" {
+		if conflicts := h.eventSubmissionConflicts(s.Data); len(conflicts) > 0 {
+			warnings = append(warnings, SubmissionWarning{
+				Type:      "venue_conflict",
+				Message:   "Se superpone con otros eventos del mismo venue: " + conflictIDs(conflicts),
+				Conflicts: conflicts,
+			})
+		}
+	}
+
+	if matches := finder.find(s.Type, s.Data); len(matches) > 0 {
+		warnings = append(warnings, duplicateWarning(matches))
+	}
+
+	return warnings
+}
+
+// holdSubmissionForConflicts deja la submission pendiente de revisión cuando hay superposición
+func (h *AuthHandler) holdSubmissionForConflicts(submissionID int, conflicts []EventConflict) {
+	comment := "Conflicto de horario con eventos " + conflictIDs(conflicts)
+	_, err := h.DB.Update(false, `
+		UPDATE submissions SET status = 'pending', comment = ?, updated_at = NOW()
+		WHERE id = ?`, comment, submissionID)
+	if err != nil {
+		fmt.Printf("[Error] No se pudo dejar pendiente la submission %d: %v\n", submissionID, err)
+	}
+}
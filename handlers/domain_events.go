@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+
+	"brotecolectivo/models"
+)
+
+// Origen del contenido nuevo: define, por ejemplo, si se anuncia en redes sociales
+const (
+	OriginAPI        = "api"        // alta directa desde el panel
+	OriginSubmission = "submission" // colaboración aprobada por un moderador
+	OriginAdmin      = "admin"      // colaboración de un administrador, aprobada automáticamente
+)
+
+// Actor es el usuario que provocó el evento (UserID 0 si no hay sesión)
+type Actor struct {
+	UserID int `json:"user_id,omitempty"`
+}
+
+func (a Actor) actorID() int { return a.UserID }
+
+// actorFrom devuelve el usuario de la sesión del pedido, si la hay
+func actorFrom(r *http.Request) Actor {
+	if claims, ok := r.Context().Value("user").(*models.Claims); ok {
+		return Actor{UserID: int(claims.UserID)}
+	}
+	return Actor{}
+}
+
+// SubmissionCreated: se recibió una colaboración. Las de administradores llegan ya aprobadas.
+type SubmissionCreated struct {
+	Actor
+	Submission Submission `json:"submission"`
+	IsAdmin    bool       `json:"is_admin"`
+}
+
+// SubmissionApproved: una colaboración pasó a aprobada
+type SubmissionApproved struct {
+	Actor
+	SubmissionID int    `json:"submission_id"`
+	Comment      string `json:"comment,omitempty"`
+}
+
+// SubmissionRejected: una colaboración pasó a rechazada
+type SubmissionRejected struct {
+	Actor
+	SubmissionID int    `json:"submission_id"`
+	Comment      string `json:"comment,omitempty"`
+}
+
+// SubmissionCommented: un moderador comentó una colaboración sin cambiar su estado
+type SubmissionCommented struct {
+	Actor
+	SubmissionID int    `json:"submission_id"`
+	Comment      string `json:"comment"`
+}
+
+// ArtistLinkApproved: se aprobó la vinculación de un usuario con una banda
+type ArtistLinkApproved struct {
+	Actor
+	SubmissionID int    `json:"submission_id"`
+	ArtistID     int    `json:"artist_id"`
+	Rol          string `json:"rol"`
+	ArtistName   string `json:"artist_name"`
+}
+
+// VenueClaimCreated: un usuario pidió vincularse con un venue
+type VenueClaimCreated struct {
+	Actor
+	SubmissionID int `json:"submission_id"`
+	VenueID      int `json:"venue_id"`
+}
+
+// VenueClaimReviewed: se aprobó o rechazó una solicitud venue_link. NotifyWhatsApp indica si
+// también se avisa al WhatsApp de la solicitud o si ya lo hace el aviso general al autor.
+type VenueClaimReviewed struct {
+	Actor
+	SubmissionID   int    `json:"submission_id"`
+	VenueID        int    `json:"venue_id"`
+	Approved       bool   `json:"approved"`
+	Comment        string `json:"comment,omitempty"`
+	NotifyWhatsApp bool   `json:"notify_whatsapp"`
+}
+
+// BandCreated: se dio de alta una banda
+type BandCreated struct {
+	Actor
+	Band         Band   `json:"band"`
+	Origin       string `json:"origin"`
+	SubmissionID int    `json:"submission_id,omitempty"`
+}
+
+// BandUpdated: se editaron los datos de una banda
+type BandUpdated struct {
+	Actor
+	Band Band `json:"band"`
+}
+
+// EventPublished: se publicó un evento. SubmissionType distingue event de eventvenue.
+type EventPublished struct {
+	Actor
+	Event          Event  `json:"event"`
+	Origin         string `json:"origin"`
+	SubmissionID   int    `json:"submission_id,omitempty"`
+	SubmissionType string `json:"submission_type,omitempty"`
+}
+
+// EventCancelled: se eliminó un evento. Snapshot son sus datos leídos antes de borrarlo.
+type EventCancelled struct {
+	Actor
+	EventID  int           `json:"event_id"`
+	Snapshot *webhookEvent `json:"snapshot,omitempty"`
+}
+
+// NewsPublished: se publicó una noticia
+type NewsPublished struct {
+	Actor
+	News         News   `json:"news"`
+	Origin       string `json:"origin"`
+	SubmissionID int    `json:"submission_id,omitempty"`
+}
+
+func (SubmissionCreated) EventName() string   { return "submission.created" }
+func (SubmissionApproved) EventName() string  { return "submission.approved" }
+func (SubmissionRejected) EventName() string  { return "submission.rejected" }
+func (SubmissionCommented) EventName() string { return "submission.commented" }
+func (ArtistLinkApproved) EventName() string  { return "artist_link.approved" }
+func (VenueClaimCreated) EventName() string   { return "venue_claim.created" }
+func (VenueClaimReviewed) EventName() string  { return "venue_claim.reviewed" }
+func (BandCreated) EventName() string         { return "band.created" }
+func (BandUpdated) EventName() string         { return "band.updated" }
+func (EventPublished) EventName() string      { return "event.published" }
+func (EventCancelled) EventName() string      { return "event.cancelled" }
+func (NewsPublished) EventName() string       { return "news.published" }
@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Similitud mínima (0 a 1) para considerar que dos nombres son el mismo con algún error de tipeo
+const duplicateThreshold = 0.82
+
+// Cantidad máxima de coincidencias que se devuelven por submission
+const maxDuplicateMatches = 5
+
+// DuplicateMatch es un registro existente que podría ser el mismo que se está enviando.
+//
+// @Schema
+type DuplicateMatch struct {
+	Type   string  `json:"type"` // band, venue o event
+	ID     int     `json:"id"`
+	Name   string  `json:"name"`
+	Slug   string  `json:"slug"`
+	Score  float64 `json:"score"`  // 1 = coincidencia exacta
+	Reason string  `json:"reason"` // nombre, slug, fecha y venue
+}
+
+// foldForMatch pasa el texto a minúsculas sin tildes ni signos, para comparar nombres.
+// Es la misma idea que normalizeUTF8, pero quitando las marcas diacríticas.
+func foldForMatch(input string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, input)
+	if err != nil {
+		folded = input
+	}
+
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(folded) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space && b.Len() > 0 {
+			b.WriteRune(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// levenshtein calcula la distancia de edición entre dos textos, por runas
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// nameSimilarity compara dos nombres ya normalizados y devuelve un valor entre 0 y 1.
+// Ignora espacios para que "La Renga" y "Larenga" coincidan.
+func nameSimilarity(a, b string) float64 {
+	a = strings.ReplaceAll(a, " ", "")
+	b = strings.ReplaceAll(b, " ", "")
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	longest := max(len([]rune(a)), len([]rune(b)))
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// duplicateCandidate es un registro existente cargado para comparar
+type duplicateCandidate struct {
+	ID     int
+	Name   string
+	Slug   string
+	City   string
+	folded string
+}
+
+// duplicateFinder carga bandas y venues una sola vez por request,
+// así la cola de moderación no repite la consulta por cada submission.
+type duplicateFinder struct {
+	h      *AuthHandler
+	bands  []duplicateCandidate
+	venues []duplicateCandidate
+	loaded map[string]bool
+}
+
+func (h *AuthHandler) newDuplicateFinder() *duplicateFinder {
+	return &duplicateFinder{h: h, loaded: map[string]bool{}}
+}
+
+// candidates devuelve los registros de la tabla indicada (bands o venues)
+func (f *duplicateFinder) candidates(table string) []duplicateCandidate {
+	if f.loaded[table] {
+		if table == "bands" {
+			return f.bands
+		}
+		return f.venues
+	}
+	f.loaded[table] = true
+
+	query := "SELECT id, name, slug, '' FROM bands"
+	if table == "venues" {
+		query = "SELECT id, name, slug, IFNULL(city, '') FROM venues"
+	}
+	rows, err := f.h.DB.Select(query)
+	if err != nil {
+		fmt.Printf("[Warning] No se pudieron cargar %s para detectar duplicados: %v\n", table, err)
+		return nil
+	}
+	defer rows.Close()
+
+	var list []duplicateCandidate
+	for rows.Next() {
+		var c duplicateCandidate
+		if err := rows.Scan(&c.ID, &c.Name, &c.Slug, &c.City); err != nil {
+			continue
+		}
+		c.folded = foldForMatch(c.Name)
+		list = append(list, c)
+	}
+
+	if table == "bands" {
+		f.bands = list
+	} else {
+		f.venues = list
+	}
+	return list
+}
+
+// matchByName busca registros con nombre o slug parecidos
+func (f *duplicateFinder) matchByName(entityType, table, name, slug, city string) []DuplicateMatch {
+	folded := foldForMatch(name)
+	slug = strings.TrimSpace(strings.ToLower(slug))
+	if folded == "" && slug == "" {
+		return nil
+	}
+
+	var matches []DuplicateMatch
+	for _, c := range f.candidates(table) {
+		score := nameSimilarity(folded, c.folded)
+		reason := "nombre similar"
+		if score == 1 {
+			reason = "mismo nombre"
+		}
+		if slug != "" && slug == c.Slug {
+			score, reason = 1, "mismo slug"
+		} else if slug != "" {
+			if s := nameSimilarity(foldForMatch(slug), foldForMatch(c.Slug)); s > score {
+				score, reason = s, "slug similar"
+			}
+		}
+		// En venues, la misma ciudad refuerza la sospecha
+		if city != "" && c.City != "" && foldForMatch(city) == foldForMatch(c.City) && score >= duplicateThreshold-0.07 {
+			score = min(1, score+0.07)
+			reason += " en la misma ciudad"
+		}
+		if score >= duplicateThreshold {
+			matches = append(matches, DuplicateMatch{
+				Type: entityType, ID: c.ID, Name: c.Name, Slug: c.Slug,
+				Score: float64(int(score*100)) / 100, Reason: reason,
+			})
+		}
+	}
+	return matches
+}
+
+// matchEvents busca eventos del mismo día con título parecido o en el mismo venue
+func (f *duplicateFinder) matchEvents(title, slug, dateStart string, venueID int) []DuplicateMatch {
+	var matches []DuplicateMatch
+
+	if slug != "" {
+		row, err := f.h.DB.SelectRow("SELECT id, title, slug FROM events WHERE slug = ?", slug)
+		if err == nil {
+			var m DuplicateMatch
+			if row.Scan(&m.ID, &m.Name, &m.Slug) == nil {
+				m.Type, m.Score, m.Reason = "event", 1, "mismo slug"
+				matches = append(matches, m)
+			}
+		}
+	}
+
+	start, err := parseEventDate(dateStart)
+	if err != nil {
+		return matches
+	}
+	rows, err := f.h.DB.Select(`
+		SELECT id, title, slug, IFNULL(id_venue, 0)
+		FROM events WHERE DATE(date_start) = ?`, start.Format("2006-01-02"))
+	if err != nil {
+		return matches
+	}
+	defer rows.Close()
+
+	folded := foldForMatch(title)
+	for rows.Next() {
+		var m DuplicateMatch
+		var eventVenueID int
+		if err := rows.Scan(&m.ID, &m.Name, &m.Slug, &eventVenueID); err != nil {
+			continue
+		}
+		score := nameSimilarity(folded, foldForMatch(m.Name))
+		sameVenue := venueID > 0 && venueID == eventVenueID
+		switch {
+		case sameVenue && score >= duplicateThreshold:
+			m.Score, m.Reason = 1, "mismo título, fecha y venue"
+		case sameVenue:
+			// Mismo día y lugar con otro título: probable, pero puede ser otra fecha del ciclo
+			m.Score, m.Reason = 0.85, "misma fecha y venue"
+		case score >= duplicateThreshold:
+			m.Score, m.Reason = score, "título similar el mismo día"
+		default:
+			continue
+		}
+		m.Type = "event"
+		m.Score = float64(int(m.Score*100)) / 100
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// jsonInt lee un ID que puede venir como número o como texto desde el formulario
+func jsonInt(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
+
+// find devuelve los posibles duplicados de los datos de una submission
+func (f *duplicateFinder) find(subType string, dataRaw []byte) []DuplicateMatch {
+	type namedData struct {
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+		City string `json:"city"`
+	}
+	type eventData struct {
+		Title     string      `json:"title"`
+		Slug      string      `json:"slug"`
+		DateStart string      `json:"date_start"`
+		IDVenue   interface{} `json:"id_venue"`
+	}
+
+	var matches []DuplicateMatch
+	switch subType {
+	case "band":
+		var d namedData
+		if json.Unmarshal(dataRaw, &d) == nil {
+			matches = f.matchByName("band", "bands", d.Name, d.Slug, "")
+		}
+	case "venue":
+		var d namedData
+		if json.Unmarshal(dataRaw, &d) == nil {
+			matches = f.matchByName("venue", "venues", d.Name, d.Slug, d.City)
+		}
+	case "event":
+		var d eventData
+		if json.Unmarshal(dataRaw, &d) == nil {
+			matches = f.matchEvents(d.Title, d.Slug, d.DateStart, jsonInt(d.IDVenue))
+		}
+	case "eventvenue":
+		var d struct {
+			Venue namedData `json:"venue"`
+			Event eventData `json:"event"`
+		}
+		if json.Unmarshal(dataRaw, &d) == nil {
+			matches = f.matchByName("venue", "venues", d.Venue.Name, d.Venue.Slug, d.Venue.City)
+			matches = append(matches, f.matchEvents(d.Event.Title, d.Event.Slug, d.Event.DateStart, 0)...)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > maxDuplicateMatches {
+		matches = matches[:maxDuplicateMatches]
+	}
+	return matches
+}
+
+// duplicateWarning arma la advertencia "posible duplicado de #id" para la cola de moderación
+func duplicateWarning(matches []DuplicateMatch) SubmissionWarning {
+	ids := make([]string, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, fmt.Sprintf("#%d (%s)", m.ID, m.Name))
+	}
+	return SubmissionWarning{
+		Type:       "possible_duplicate",
+		Message:    "Posible duplicado de " + strings.Join(ids, ", "),
+		Duplicates: matches,
+	}
+}
+
+// CheckSubmissionDuplicates devuelve los registros existentes parecidos a una submission, antes de crearla.
+//
+// @Summary Detectar duplicados
+// @Description Compara nombre y slug (sin tildes y tolerando errores de tipeo) y, para eventos, fecha y venue
+// @Tags submissions
+// @Accept json
+// @Produce json
+// @Param submission body object true "type (band|venue|event|eventvenue) y data"
+// @Success 200 {object} map[string]interface{} "Posibles duplicados"
+// @Failure 400 {string} string "Datos inválidos"
+// @Router /submissions/check-duplicates [post]
+func (h *AuthHandler) CheckSubmissionDuplicates(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar")
+		return
+	}
+
+	matches := h.newDuplicateFinder().find(input.Type, input.Data)
+	if matches == nil {
+		matches = []DuplicateMatch{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"has_duplicates": len(matches) > 0,
+		"matches":        matches,
+	})
+}
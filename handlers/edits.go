@@ -19,7 +19,7 @@ type Edit struct {
 func (h *AuthHandler) GetEdits(w http.ResponseWriter, r *http.Request) {
 	rows, err := h.DB.Select(`SELECT id, user_id, entity_type, entity_id, changes, created_at FROM edits ORDER BY created_at DESC`)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	defer rows.Close()
@@ -39,14 +39,14 @@ func (h *AuthHandler) GetEdits(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) CreateEdit(w http.ResponseWriter, r *http.Request) {
 	var e Edit
 	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
-		http.Error(w, "Error al decodificar", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar")
 		return
 	}
 	_, err := h.DB.Insert(false, `
 		INSERT INTO edits (user_id, entity_type, entity_id, changes)
 		VALUES (?, ?, ?, ?)`, e.UserID, e.EntityType, e.EntityID, string(e.Changes))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
@@ -60,13 +60,13 @@ func (h *AuthHandler) GetEditByID(w http.ResponseWriter, r *http.Request) {
 
 	row, err := h.DB.SelectRow("SELECT id, user_id, entity_type, entity_id, changes, created_at FROM edits WHERE id = ?", id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 
 	err = row.Scan(&e.ID, &e.UserID, &e.EntityType, &e.EntityID, &changesRaw, &e.CreatedAt)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, "", err)
 		return
 	}
 
@@ -83,14 +83,14 @@ func (h *AuthHandler) UpdateEditStatus(w http.ResponseWriter, r *http.Request) {
 		ReviewerID int    `json:"reviewer_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "Error al decodificar", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar")
 		return
 	}
 	_, err := h.DB.Update(false, `
 		UPDATE edits SET status=?, comment=?, reviewed_by=? WHERE id=?`,
 		payload.Status, payload.Comment, payload.ReviewerID, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
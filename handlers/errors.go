@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Códigos de error que puede devolver la API. Son estables: el frontend puede decidir por el código
+// y mostrar el mensaje tal cual.
+const (
+	ErrCodeBadRequest       = "bad_request"
+	ErrCodeValidation       = "validation_failed"
+	ErrCodeUnauthorized     = "unauthorized"
+	ErrCodeForbidden        = "forbidden"
+	ErrCodeNotFound         = "not_found"
+	ErrCodeConflict         = "conflict"
+	ErrCodeVenueConflict    = "venue_conflict"
+	ErrCodeTooManyRequests  = "too_many_requests"
+	ErrCodeInternal         = "internal_error"
+	ErrCodeBadGateway       = "bad_gateway"
+	ErrCodeUnavailable      = "service_unavailable"
+	ErrCodeMethodNotAllowed = "method_not_allowed"
+)
+
+// Mensajes genéricos por código e idioma. En español se prefiere el mensaje puntual del handler;
+// en otros idiomas se usa el genérico del código, que es lo que el cliente puede traducir.
+var errorMessages = map[string]map[string]string{
+	"es": {
+		ErrCodeBadRequest:       "Solicitud inválida",
+		ErrCodeValidation:       "Hay datos inválidos",
+		ErrCodeUnauthorized:     "No autorizado",
+		ErrCodeForbidden:        "No tenés permiso para esta operación",
+		ErrCodeNotFound:         "No encontrado",
+		ErrCodeConflict:         "La operación entra en conflicto con el estado actual",
+		ErrCodeVenueConflict:    "Ya existen eventos en ese venue con horario superpuesto",
+		ErrCodeTooManyRequests:  "Demasiadas solicitudes, probá de nuevo en unos minutos",
+		ErrCodeInternal:         "Error interno del servidor",
+		ErrCodeBadGateway:       "Falló un servicio externo",
+		ErrCodeUnavailable:      "Servicio no disponible",
+		ErrCodeMethodNotAllowed: "Método no permitido",
+	},
+	"en": {
+		ErrCodeBadRequest:       "Invalid request",
+		ErrCodeValidation:       "Some fields are invalid",
+		ErrCodeUnauthorized:     "Unauthorized",
+		ErrCodeForbidden:        "You are not allowed to perform this operation",
+		ErrCodeNotFound:         "Not found",
+		ErrCodeConflict:         "The operation conflicts with the current state",
+		ErrCodeVenueConflict:    "There are already overlapping events at this venue",
+		ErrCodeTooManyRequests:  "Too many requests, try again in a few minutes",
+		ErrCodeInternal:         "Internal server error",
+		ErrCodeBadGateway:       "An external service failed",
+		ErrCodeUnavailable:      "Service unavailable",
+		ErrCodeMethodNotAllowed: "Method not allowed",
+	},
+}
+
+// Mensajes de validación por campo, para los idiomas distintos del español
+var fieldErrorMessages = map[string]string{
+	"required": "This field is required",
+	"invalid":  "Invalid value",
+	"range":    "Value out of range",
+}
+
+// errorCodeForStatus devuelve el código por defecto de cada estado HTTP
+func errorCodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrCodeBadRequest
+	case http.StatusUnprocessableEntity:
+		return ErrCodeValidation
+	case http.StatusUnauthorized:
+		return ErrCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrCodeForbidden
+	case http.StatusNotFound:
+		return ErrCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return ErrCodeMethodNotAllowed
+	case http.StatusConflict:
+		return ErrCodeConflict
+	case http.StatusTooManyRequests:
+		return ErrCodeTooManyRequests
+	case http.StatusBadGateway:
+		return ErrCodeBadGateway
+	case http.StatusServiceUnavailable:
+		return ErrCodeUnavailable
+	}
+	if status >= 500 {
+		return ErrCodeInternal
+	}
+	return ErrCodeBadRequest
+}
+
+// FieldError describe un campo inválido.
+//
+// @Schema
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"` // required, invalid o range
+	Message string `json:"message"`
+}
+
+// validationErrors es un error con el detalle de cada campo inválido.
+// Los normalize() lo devuelven para que la respuesta incluya "details".
+type validationErrors []FieldError
+
+func (v validationErrors) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, f := range v {
+		messages = append(messages, f.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// fieldError arma un error de validación para un solo campo
+func fieldError(field, code, message string) error {
+	return validationErrors{{Field: field, Code: code, Message: message}}
+}
+
+// APIError es el error que devuelven los handlers. Err es la causa interna:
+// se registra en el log pero nunca se envía al cliente en errores 5xx.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	Details []FieldError
+	Extra   map[string]interface{} // campos adicionales del cuerpo (ej. conflicts)
+	Err     error
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error { return e.Err }
+
+// ErrorResponse es el cuerpo de todas las respuestas de error.
+//
+// @Schema
+type ErrorResponse struct {
+	Error     string       `json:"error"`
+	Code      string       `json:"code"`
+	Status    int          `json:"status"`
+	RequestID string       `json:"request_id,omitempty"`
+	Details   []FieldError `json:"details,omitempty"`
+}
+
+// requestLanguage elige el idioma de los mensajes: ?lang= o Accept-Language, español por defecto
+func requestLanguage(r *http.Request) string {
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = r.Header.Get("Accept-Language")
+	}
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if strings.HasPrefix(lang, "en") {
+		return "en"
+	}
+	return "es"
+}
+
+// requestID devuelve el ID que RequestID le asignó al pedido
+func requestID(w http.ResponseWriter, r *http.Request) string {
+	if id := middleware.GetReqID(r.Context()); id != "" {
+		return id
+	}
+	return w.Header().Get("X-Request-ID")
+}
+
+// exposesCause indica si el detalle de la causa se puede mostrar: solo en errores del cliente
+// donde la causa describe lo que envió (JSON mal formado, validaciones).
+func exposesCause(status int) bool {
+	return status == http.StatusBadRequest || status == http.StatusConflict || status == http.StatusUnprocessableEntity
+}
+
+// writeAPIError escribe el error con el formato común y registra la causa interna
+func writeAPIError(w http.ResponseWriter, r *http.Request, e *APIError) {
+	if e.Status == 0 {
+		e.Status = http.StatusInternalServerError
+	}
+	if e.Code == "" {
+		e.Code = errorCodeForStatus(e.Status)
+	}
+
+	var fields validationErrors
+	if errors.As(e.Err, &fields) {
+		e.Details = append(e.Details, fields...)
+		if e.Code == ErrCodeBadRequest {
+			e.Code = ErrCodeValidation
+		}
+	}
+
+	lang := requestLanguage(r)
+	message := e.Message
+	if lang != "es" || message == "" {
+		message = errorMessages[lang][e.Code]
+	}
+	if message == "" {
+		message = http.StatusText(e.Status)
+	}
+	if lang == "es" && e.Err != nil && exposesCause(e.Status) {
+		message += ": " + e.Err.Error()
+	}
+	if lang != "es" {
+		for i := range e.Details {
+			if translated, ok := fieldErrorMessages[e.Details[i].Code]; ok {
+				e.Details[i].Message = translated
+			}
+		}
+	}
+
+	id := requestID(w, r)
+	if e.Err != nil && !exposesCause(e.Status) {
+		log.Printf("[Error] %s %s -> %d %s (request %s): %v", r.Method, r.URL.Path, e.Status, e.Code, id, e.Err)
+	}
+
+	body := map[string]interface{}{
+		"error":  message,
+		"code":   e.Code,
+		"status": e.Status,
+	}
+	if id != "" {
+		body["request_id"] = id
+	}
+	if len(e.Details) > 0 {
+		body["details"] = e.Details
+	}
+	for key, value := range e.Extra {
+		if _, taken := body[key]; !taken {
+			body[key] = value
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError responde con un error de la API. message es el texto en español para el usuario;
+// cause, si se pasa, es el error interno (se registra y en 5xx no se muestra).
+func writeError(w http.ResponseWriter, r *http.Request, status int, message string, cause ...error) {
+	e := &APIError{Status: status, Message: message}
+	if len(cause) > 0 {
+		e.Err = cause[0]
+	}
+	writeAPIError(w, r, e)
+}
+
+// writeValidationError responde 400 con el detalle de los campos inválidos
+func writeValidationError(w http.ResponseWriter, r *http.Request, message string, fields ...FieldError) {
+	writeAPIError(w, r, &APIError{
+		Status:  http.StatusBadRequest,
+		Code:    ErrCodeValidation,
+		Message: message,
+		Details: fields,
+	})
+}
+
+// WriteError es writeError para los middlewares del paquete principal
+func WriteError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	writeError(w, r, status, message)
+}
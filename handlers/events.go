@@ -37,206 +37,154 @@ import (
 //
 // @Schema
 type Event struct {
-	ID        int    `json:"id"`
-	Title     string `json:"title"`
-	Tags      string `json:"tags"`
-	Content   string `json:"content"`
-	Slug      string `json:"slug"`
-	DateStart string `json:"date_start"`
-	DateEnd   string `json:"date_end"`
-	Venue     *Venue `json:"venue"`
-	Bands     []Band `json:"bands"`
-	VenueID   int    `json:"id_venue"` // <--- agregar esto
-	Rol       string `json:"rol"`
+	ID        int     `json:"id"`
+	Title     string  `json:"title"`
+	Tags      string  `json:"tags"`
+	Content   string  `json:"content"`
+	Slug      string  `json:"slug"`
+	DateStart string  `json:"date_start"`
+	DateEnd   string  `json:"date_end"`
+	Venue     *Venue  `json:"venue"`
+	Bands     []Band  `json:"bands"`
+	VenueID   int     `json:"id_venue"` // ID del venue (también viene en venue.id)
+	Rol       string  `json:"rol"`
+	SeriesID  int     `json:"id_series,omitempty"`
+	Series    *Series `json:"series,omitempty"`
+	Genres    []Genre `json:"genres,omitempty"` // con include=genres, los de sus bandas
+	EventTicketing
+}
+
+// insertEvent inserta un evento con sus datos de entradas y devuelve el ID creado.
+// e.Slug queda con el slug definitivo (puede llevar sufijo si el pedido estaba ocupado).
+func (h *AuthHandler) insertEvent(e *Event) (int, error) {
+	// Si el slug está ocupado se prueba primero con el año del evento
+	var yearHint string
+	if start, err := parseEventDate(e.DateStart); err == nil {
+		yearHint = strconv.Itoa(start.Year())
+	}
+	slug, err := h.assignSlug("event", e.Slug, e.Title, yearHint)
+	if err != nil {
+		return 0, err
+	}
+	e.Slug = slug
+	defer h.releaseSlug("event", slug)
+
+	var seriesID interface{}
+	if e.SeriesID > 0 {
+		seriesID = e.SeriesID
+	}
+	args := []interface{}{e.VenueID, seriesID, e.Title, e.Tags, e.Content, e.Slug, e.DateStart, e.DateEnd}
+	args = append(args, e.EventTicketing.sqlValues()...)
+	return h.DB.Insert(false, `
+		INSERT INTO events (id_venue, id_series, title, tags, content, slug, date_start, date_end,
+			price_tiers, is_free, free_until_capacity, ticket_url, min_age, door_time)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, args...)
 }
 
 // GetEventsCount devuelve el número total de eventos en la base de datos.
 //
 // @Summary Obtener conteo de eventos
-// @Description Devuelve el número total de eventos registrados en la plataforma
+// @Description Devuelve el número de eventos que cumplen los mismos filtros que /events/table (q, id, title, date_start, free)
 // @Tags eventos
 // @Produce json
+// @Param free query bool false "Solo eventos gratuitos"
 // @Success 200 {object} map[string]int "Conteo exitoso"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /events/count [get]
 func (h *AuthHandler) GetEventsCount(w http.ResponseWriter, r *http.Request) {
-	search := r.URL.Query().Get("q")
-	idFilter := r.URL.Query().Get("id")
-	titleFilter := r.URL.Query().Get("title")
-	dateFilter := r.URL.Query().Get("date_start")
-
-	query := `
-		SELECT COUNT(*) 
-		FROM events e
-		JOIN venues v ON e.id_venue = v.id
-		WHERE 1=1
-	`
-	var queryParams []interface{}
-
-	if search != "" {
-		pattern := "%" + search + "%"
-		query += ` AND (e.title LIKE ? OR e.tags LIKE ? OR e.content LIKE ? OR e.slug LIKE ?)`
-		queryParams = append(queryParams, pattern, pattern, pattern, pattern)
-	}
-	if idFilter != "" {
-		query += " AND e.id LIKE ?"
-		queryParams = append(queryParams, "%"+idFilter+"%")
-	}
-	if titleFilter != "" {
-		query += " AND e.title LIKE ?"
-		queryParams = append(queryParams, "%"+titleFilter+"%")
-	}
-	if dateFilter != "" {
-		query += " AND e.date_start LIKE ?"
-		queryParams = append(queryParams, "%"+dateFilter+"%")
-	}
-
-	row, err := h.DB.SelectRow(query, queryParams...)
-	if err != nil {
-		http.Error(w, "Error al contar eventos", http.StatusInternalServerError)
-		return
-	}
-
-	var count int
-	if err := row.Scan(&count); err != nil {
-		http.Error(w, "Error al leer el conteo", http.StatusInternalServerError)
-		return
-	}
+	h.writeCount(w, r, eventListSpec)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]int{"count": count})
+// eventListSpec declara los filtros y órdenes del listado de eventos, por defecto de la fecha
+// más nueva a la más vieja. Lo comparten el listado público, la tabla del panel y el conteo.
+var eventListSpec = &listSpec{
+	from:   "events e JOIN venues v ON e.id_venue = v.id",
+	id:     column("e.id"),
+	search: columns("e.title", "e.tags", "e.content", "e.slug"),
+	filters: map[string]filterField{
+		"id":         numberFilter("e.id"),
+		"slug":       textFilter("e.slug"),
+		"title":      textFilter("e.title"),
+		"date_start": dateFilter("e.date_start"),
+		"venue":      enumFilter("e.id_venue"),
+		"city":       textFilter("v.city"),
+	},
+	sorts: map[string]string{
+		"id":         column("e.id"),
+		"title":      column("e.title"),
+		"date_start": column("e.date_start"),
+		"venue":      column("v.name"),
+	},
+	defaultSort: "date_start:desc",
+	apply: func(r *http.Request, q *listQuery) error {
+		if free := strings.TrimPrefix(eventFreeFilter(r.URL.Query().Get("free")), " AND "); free != "" {
+			q.where(free)
+		}
+		return nil
+	},
 }
 
 // GetEventsDatatable devuelve los datos de eventos en formato para DataTables.
 //
 // @Summary Obtener eventos para DataTables
-// @Description Devuelve los datos de eventos formateados para su uso con DataTables
+// @Description Devuelve eventos paginados para la tabla del panel, con búsqueda, filtros por columna y ordenamiento
 // @Tags eventos
 // @Produce json
-// @Param draw query int false "Parámetro draw de DataTables"
-// @Param start query int false "Índice de inicio para paginación"
-// @Param length query int false "Número de registros a mostrar"
-// @Param search[value] query string false "Término de búsqueda"
-// @Param order[0][column] query int false "Índice de la columna para ordenar"
-// @Param order[0][dir] query string false "Dirección de ordenamiento (asc/desc)"
-// @Success 200 {object} DatatableResponse "Datos para DataTables"
+// @Param limit query int false "Cantidad de registros (por defecto 10, máximo 100)"
+// @Param offset query int false "Desplazamiento para paginación"
+// @Param cursor query string false "Cursor de la página siguiente (X-Next-Cursor)"
+// @Param total query bool false "Incluir el total en X-Total-Count"
+// @Param q query string false "Término de búsqueda"
+// @Param sort query string false "Orden: hasta 3 columnas separadas por coma, con - o :desc para descendente (id, title, date_start, venue)"
+// @Param order query string false "Dirección cuando sort tiene una sola columna (asc/desc)"
+// @Param fields query string false "Campos a devolver, separados por coma (ej. id,title,date_start,venue)"
+// @Param include query string false "Relaciones a incluir: venue, bands, genres, series (por defecto bands)"
+// @Success 200 {array} Event "Lista de eventos"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /events/table [get]
 func (h *AuthHandler) GetEventsDatatable(w http.ResponseWriter, r *http.Request) {
-	offsetParam := r.URL.Query().Get("offset")
-	limitParam := r.URL.Query().Get("limit")
-	search := r.URL.Query().Get("q")
-	sortBy := r.URL.Query().Get("sort")
-	order := r.URL.Query().Get("order")
-
-	idFilter := r.URL.Query().Get("id")
-	titleFilter := r.URL.Query().Get("title")
-	dateFilter := r.URL.Query().Get("date_start")
-
-	offset := 0
-	limit := 10
-	var err error
-
-	if offsetParam != "" {
-		offset, err = strconv.Atoi(offsetParam)
-		if err != nil {
-			http.Error(w, "Offset inválido", http.StatusBadRequest)
-			return
-		}
-	}
-	if limitParam != "" {
-		limit, err = strconv.Atoi(limitParam)
-		if err != nil {
-			http.Error(w, "Límite inválido", http.StatusBadRequest)
-			return
-		}
-	}
-
-	query := `
-		SELECT 
-			e.id, e.title, e.tags, e.content, e.slug, e.date_start, e.date_end,
-			v.id, v.name
-		FROM events e
-		JOIN venues v ON e.id_venue = v.id
-		WHERE 1=1
-	`
-	var queryParams []interface{}
-
-	if search != "" {
-		pattern := "%" + search + "%"
-		query += ` AND (e.title LIKE ? OR e.tags LIKE ? OR e.content LIKE ? OR e.slug LIKE ?)`
-		queryParams = append(queryParams, pattern, pattern, pattern, pattern)
-	}
-	if idFilter != "" {
-		query += " AND e.id LIKE ?"
-		queryParams = append(queryParams, "%"+idFilter+"%")
-	}
-	if titleFilter != "" {
-		query += " AND e.title LIKE ?"
-		queryParams = append(queryParams, "%"+titleFilter+"%")
-	}
-	if dateFilter != "" {
-		query += " AND e.date_start LIKE ?"
-		queryParams = append(queryParams, "%"+dateFilter+"%")
+	p, err := parsePageRequest(r, 10)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
 	}
 
-	if sortBy != "" {
-		validSorts := map[string]bool{"id": true, "title": true, "date_start": true}
-		if validSorts[sortBy] {
-			if order != "desc" {
-				order = "asc"
-			}
-			query += fmt.Sprintf(" ORDER BY e.%s %s", sortBy, strings.ToUpper(order))
-		}
+	fs, err := eventFieldSet.parse(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
 	}
 
-	query += " LIMIT ? OFFSET ?"
-	queryParams = append(queryParams, limit, offset)
-	fmt.Println("QUERY:", query)
-	fmt.Println("PARAMS:", queryParams)
-
-	rows, err := h.DB.Select(query, queryParams...)
+	q, err := eventListSpec.query(r, `e.id, e.title, `+fs.pick("tags", "e.tags")+`, `+fs.pick("content", "e.content")+`,
+			e.slug, e.date_start, e.date_end, IFNULL(e.id_series, 0), v.id, v.name`)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
-	defer rows.Close()
-
 	var events []Event
-	for rows.Next() {
+	page, err := h.queryPage(q, p, func(scan func(dest ...interface{}) error) error {
 		var e Event
 		var v Venue
-		err := rows.Scan(&e.ID, &e.Title, &e.Tags, &e.Content, &e.Slug, &e.DateStart, &e.DateEnd,
-			&v.ID, &v.Name)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+		if err := scan(&e.ID, &e.Title, &e.Tags, &e.Content, &e.Slug, &e.DateStart, &e.DateEnd, &e.SeriesID, &v.ID, &v.Name); err != nil {
+			return err
 		}
+		e.VenueID = v.ID
 		e.Venue = &v
-
-		bandRows, err := h.DB.Select(`
-			SELECT b.id, b.name, b.slug
-			FROM bands b
-			JOIN events_bands eb ON b.id = eb.id_band
-			WHERE eb.id_event = ?
-		`, e.ID)
-		if err == nil {
-			var bands []Band
-			for bandRows.Next() {
-				var b Band
-				if err := bandRows.Scan(&b.ID, &b.Name, &b.Slug); err == nil {
-					bands = append(bands, b)
-				}
-			}
-			bandRows.Close()
-			e.Bands = bands
-		}
-
 		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		writeListError(w, r, err)
+		return
+	}
+	if err := h.newLoader().eventRelations(events, fs); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al cargar las relaciones de los eventos", err)
+		return
 	}
 
+	writePageHeaders(w, r, page)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(events)
+	fs.encode(w, events)
 }
 
 // GetEvents devuelve todos los eventos, con opciones de filtrado y paginación.
@@ -245,101 +193,105 @@ func (h *AuthHandler) GetEventsDatatable(w http.ResponseWriter, r *http.Request)
 // @Description Obtiene una lista de eventos con opciones de filtrado y paginación
 // @Tags eventos
 // @Produce json
-// @Param page query int false "Número de página para paginación"
-// @Param limit query int false "Límite de registros por página"
-// @Param search query string false "Término de búsqueda"
+// @Param limit query int false "Límite de registros por página (por defecto 10, máximo 100)"
+// @Param offset query int false "Desplazamiento para paginación"
+// @Param page query int false "Número de página (alternativa a offset)"
+// @Param cursor query string false "Cursor de la página siguiente (X-Next-Cursor, no disponible con near)"
+// @Param total query bool false "Incluir el total en X-Total-Count"
+// @Param q query string false "Término de búsqueda"
 // @Param upcoming query bool false "Solo eventos futuros"
 // @Param past query bool false "Solo eventos pasados"
+// @Param free query bool false "Solo eventos gratuitos o con entrada libre hasta completar capacidad"
+// @Param near query string false "Ordenar por cercanía a un punto (lat,lng)"
+// @Param radius query number false "Radio en kilómetros (requiere near)"
+// @Param fields query string false "Campos a devolver, separados por coma (ej. id,title,date_start,venue)"
+// @Param include query string false "Relaciones a incluir: venue, bands, genres, series (por defecto bands)"
 // @Success 200 {array} Event "Lista de eventos"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /events [get]
 func (h *AuthHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
-	offsetParam := r.URL.Query().Get("offset")
-	limitParam := r.URL.Query().Get("limit")
-	search := r.URL.Query().Get("q")
+	p, err := parsePageRequest(r, 10)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
 
-	offset := 0
-	limit := 10
-	var err error
+	fs, err := eventFieldSet.parse(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
 
-	if offsetParam != "" {
-		offset, err = strconv.Atoi(offsetParam)
-		if err != nil {
-			http.Error(w, "Offset inválido", http.StatusBadRequest)
-			return
-		}
+	events, page, err := h.queryEvents(r, p, fs)
+	if err != nil {
+		writeListError(w, r, err)
+		return
 	}
-	if limitParam != "" {
-		limit, err = strconv.Atoi(limitParam)
-		if err != nil {
-			http.Error(w, "Límite inválido", http.StatusBadRequest)
-			return
-		}
+
+	// Bandas y demás relaciones, una consulta por tipo para toda la página
+	if err := h.newLoader().eventRelations(events, fs); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al cargar las relaciones de los eventos", err)
+		return
 	}
 
-	query := `
-		SELECT 
-			e.id, e.title, e.tags, e.content, e.slug, e.date_start, e.date_end,
-			v.id, v.name
-		FROM events e
-		JOIN venues v ON e.id_venue = v.id
-		WHERE 1=1
-	`
-	var queryParams []interface{}
+	writePageHeaders(w, r, page)
+	w.Header().Set("Content-Type", "application/json")
+	fs.encode(w, events)
+}
 
-	if search != "" {
-		query += " AND (e.title LIKE ? OR e.tags LIKE ? OR e.content LIKE ? OR e.slug LIKE ?)"
-		searchPattern := "%" + search + "%"
-		queryParams = append(queryParams, searchPattern, searchPattern, searchPattern, searchPattern)
+// queryEvents lee una página de eventos con sus datos de entradas y el venue resumido (sin las bandas).
+// Con near ordena por cercanía y cada venue trae la distancia.
+func (h *AuthHandler) queryEvents(r *http.Request, p *pageRequest, fs *fieldSet) ([]Event, *pageInfo, error) {
+	near, radius, err := nearParams(r)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	query += " ORDER BY e.date_start DESC LIMIT ? OFFSET ?"
-	queryParams = append(queryParams, limit, offset)
-
-	rows, err := h.DB.Select(query, queryParams...)
+	q, err := eventListSpec.query(r, `e.id, e.title, `+fs.pick("tags", "e.tags")+`, `+fs.pick("content", "e.content")+`,
+			e.slug, e.date_start, e.date_end, IFNULL(e.id_series, 0),
+			`+eventTicketingColumns+`,
+			v.id, v.name`)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, nil, err
+	}
+	if near != nil {
+		// Solo eventos en venues con coordenadas, del más cercano al más lejano.
+		// La distancia es calculada, así que este orden se pagina con offset.
+		q.columns += ", " + venueDistanceSQL + " AS distance"
+		q.columnArgs = append(q.columnArgs, near.WKT())
+		q.where("v.location IS NOT NULL")
+		if radius > 0 {
+			q.where(venueDistanceSQL+" <= ?", near.WKT(), radius)
+		}
+		q.orderBy("distance", "distance", false)
+		q.keyset = false
 	}
-	defer rows.Close()
 
 	var events []Event
-
-	for rows.Next() {
+	page, err := h.queryPage(q, p, func(scan func(dest ...interface{}) error) error {
 		var e Event
 		var v Venue
-		err := rows.Scan(&e.ID, &e.Title, &e.Tags, &e.Content, &e.Slug, &e.DateStart, &e.DateEnd,
-			&v.ID, &v.Name)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+		var t ticketingScan
+		dest := []interface{}{&e.ID, &e.Title, &e.Tags, &e.Content, &e.Slug, &e.DateStart, &e.DateEnd, &e.SeriesID}
+		dest = append(dest, t.dest()...)
+		dest = append(dest, &v.ID, &v.Name)
+		var distance float64
+		if near != nil {
+			dest = append(dest, &distance)
 		}
-		e.Venue = &v
-
-		// Bandas asociadas
-		bandRows, err := h.DB.Select(`
-			SELECT b.id, b.name, b.slug
-			FROM bands b
-			JOIN events_bands eb ON b.id = eb.id_band
-			WHERE eb.id_event = ?
-		`, e.ID)
-		if err == nil {
-			var bands []Band
-			for bandRows.Next() {
-				var b Band
-				if err := bandRows.Scan(&b.ID, &b.Name, &b.Slug); err == nil {
-					bands = append(bands, b)
-				}
-			}
-			bandRows.Close()
-			e.Bands = bands
+		if err := scan(dest...); err != nil {
+			return err
 		}
-
+		t.apply(&e.EventTicketing)
+		if near != nil {
+			v.Distance = &distance
+		}
+		e.VenueID = v.ID
+		e.Venue = &v
 		events = append(events, e)
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(events)
+		return nil
+	})
+	return events, page, err
 }
 
 // GetEventByID devuelve un evento específico por su ID.
@@ -367,9 +319,12 @@ func (h *AuthHandler) GetEventByID(w http.ResponseWriter, r *http.Request) {
 		row, _ = h.DB.SelectRow(`
 			SELECT
 				e.id, e.title, e.tags, e.content, e.slug, e.date_start, e.date_end,
-				v.id, v.name, v.latlng, v.address, v.city
+				`+eventTicketingColumns+`,
+				v.id, v.name, v.latlng, v.address, v.city,
+				s.id, s.title, s.slug
 			FROM events e
 			JOIN venues v ON e.id_venue = v.id
+			LEFT JOIN event_series s ON e.id_series = s.id
 			WHERE e.id = ?`,
 			id)
 	} else {
@@ -377,24 +332,40 @@ func (h *AuthHandler) GetEventByID(w http.ResponseWriter, r *http.Request) {
 		row, _ = h.DB.SelectRow(`
 			SELECT
 				e.id, e.title, e.tags, e.content, e.slug, e.date_start, e.date_end,
-				v.id, v.name, v.latlng, v.address, v.city
+				`+eventTicketingColumns+`,
+				v.id, v.name, v.latlng, v.address, v.city,
+				s.id, s.title, s.slug
 			FROM events e
 			JOIN venues v ON e.id_venue = v.id
+			LEFT JOIN event_series s ON e.id_series = s.id
 			WHERE e.slug = ?`,
 			id)
 	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	err = row.Scan(&e.ID, &e.Title, &e.Tags, &e.Content, &e.Slug, &e.DateStart, &e.DateEnd,
-		&v.ID, &v.Name, &v.LatLng, &v.Address, &v.City)
+	var t ticketingScan
+	dest := []interface{}{&e.ID, &e.Title, &e.Tags, &e.Content, &e.Slug, &e.DateStart, &e.DateEnd}
+	dest = append(dest, t.dest()...)
+	var seriesID sql.NullInt64
+	var seriesTitle, seriesSlug sql.NullString
+	dest = append(dest, &v.ID, &v.Name, &v.LatLng, &v.Address, &v.City, &seriesID, &seriesTitle, &seriesSlug)
+	err = row.Scan(dest...)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		if h.redirectOldSlug(w, r, "event", id) {
+			return
+		}
+		writeError(w, r, http.StatusNotFound, "", err)
 		return
 	}
+	t.apply(&e.EventTicketing)
 	e.Venue = &v
+	if seriesID.Valid {
+		e.SeriesID = int(seriesID.Int64)
+		e.Series = &Series{ID: e.SeriesID, Title: seriesTitle.String, Slug: seriesSlug.String}
+	}
 
 	// Bandas
 	bandRows, err := h.DB.Select(`
@@ -424,6 +395,8 @@ func (h *AuthHandler) GetEventByID(w http.ResponseWriter, r *http.Request) {
 // @Tags eventos
 // @Produce json
 // @Param id path int true "ID del venue"
+// @Param fields query string false "Campos a devolver, separados por coma (ej. id,title,date_start,venue)"
+// @Param include query string false "Relaciones a incluir: venue, bands, genres, series (por defecto bands)"
 // @Success 200 {array} Event "Lista de eventos del venue"
 // @Failure 400 {string} string "Error en la solicitud"
 // @Failure 404 {string} string "Venue no encontrado"
@@ -431,9 +404,17 @@ func (h *AuthHandler) GetEventByID(w http.ResponseWriter, r *http.Request) {
 // @Router /events/venue/{id} [get]
 func (h *AuthHandler) GetEventsByVenueID(w http.ResponseWriter, r *http.Request) {
 	venueID := chi.URLParam(r, "id")
+	fs, err := eventFieldSet.parse(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+
 	query := `
 		SELECT 
-			e.id, e.title, e.tags, e.content, e.slug, e.date_start, e.date_end,
+			e.id, e.title, ` + fs.pick("tags", "e.tags") + `, ` + fs.pick("content", "e.content") + `,
+			e.slug, e.date_start, e.date_end, IFNULL(e.id_series, 0),
+			` + eventTicketingColumns + `,
 			v.id, v.name
 		FROM events e
 		JOIN venues v ON e.id_venue = v.id
@@ -443,7 +424,7 @@ func (h *AuthHandler) GetEventsByVenueID(w http.ResponseWriter, r *http.Request)
 
 	rows, err := h.DB.Select(query, venueID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	defer rows.Close()
@@ -452,32 +433,28 @@ func (h *AuthHandler) GetEventsByVenueID(w http.ResponseWriter, r *http.Request)
 	for rows.Next() {
 		var e Event
 		var v Venue
-		if err := rows.Scan(&e.ID, &e.Title, &e.Tags, &e.Content, &e.Slug, &e.DateStart, &e.DateEnd,
-			&v.ID, &v.Name); err != nil {
+		var t ticketingScan
+		dest := []interface{}{&e.ID, &e.Title, &e.Tags, &e.Content, &e.Slug, &e.DateStart, &e.DateEnd, &e.SeriesID}
+		dest = append(dest, t.dest()...)
+		dest = append(dest, &v.ID, &v.Name)
+		if err := rows.Scan(dest...); err != nil {
 			continue
 		}
+		t.apply(&e.EventTicketing)
+		e.VenueID = v.ID
 		e.Venue = &v
-
-		// Bandas asociadas
-		bandRows, _ := h.DB.Select(`
-			SELECT b.id, b.name, b.slug
-			FROM bands b
-			JOIN events_bands eb ON b.id = eb.id_band
-			WHERE eb.id_event = ?
-		`, e.ID)
-		defer bandRows.Close()
-		for bandRows.Next() {
-			var b Band
-			if err := bandRows.Scan(&b.ID, &b.Name, &b.Slug); err == nil {
-				e.Bands = append(e.Bands, b)
-			}
-		}
-
 		events = append(events, e)
 	}
+	rows.Close()
+
+	// Bandas y demás relaciones de todos los eventos, una consulta por tipo
+	if err := h.newLoader().eventRelations(events, fs); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al cargar las relaciones de los eventos", err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(events)
+	fs.encode(w, events)
 }
 
 // GetEventsByBandID devuelve todos los eventos asociados a una banda específica.
@@ -487,6 +464,8 @@ func (h *AuthHandler) GetEventsByVenueID(w http.ResponseWriter, r *http.Request)
 // @Tags eventos
 // @Produce json
 // @Param id path int true "ID de la banda"
+// @Param fields query string false "Campos a devolver, separados por coma (ej. id,title,date_start,venue)"
+// @Param include query string false "Relaciones a incluir: venue, bands, genres, series (por defecto bands)"
 // @Success 200 {array} Event "Lista de eventos de la banda"
 // @Failure 400 {string} string "Error en la solicitud"
 // @Failure 404 {string} string "Banda no encontrada"
@@ -494,9 +473,17 @@ func (h *AuthHandler) GetEventsByVenueID(w http.ResponseWriter, r *http.Request)
 // @Router /events/band/{id} [get]
 func (h *AuthHandler) GetEventsByBandID(w http.ResponseWriter, r *http.Request) {
 	bandID := chi.URLParam(r, "id")
+	fs, err := eventFieldSet.parse(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+
 	query := `
 		SELECT 
-			e.id, e.title, e.tags, e.content, e.slug, e.date_start, e.date_end,
+			e.id, e.title, ` + fs.pick("tags", "e.tags") + `, ` + fs.pick("content", "e.content") + `,
+			e.slug, e.date_start, e.date_end, IFNULL(e.id_series, 0),
+			` + eventTicketingColumns + `,
 			v.id, v.name
 		FROM events e
 		JOIN events_bands eb ON e.id = eb.id_event
@@ -507,7 +494,7 @@ func (h *AuthHandler) GetEventsByBandID(w http.ResponseWriter, r *http.Request)
 
 	rows, err := h.DB.Select(query, bandID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	defer rows.Close()
@@ -516,32 +503,28 @@ func (h *AuthHandler) GetEventsByBandID(w http.ResponseWriter, r *http.Request)
 	for rows.Next() {
 		var e Event
 		var v Venue
-		if err := rows.Scan(&e.ID, &e.Title, &e.Tags, &e.Content, &e.Slug, &e.DateStart, &e.DateEnd,
-			&v.ID, &v.Name); err != nil {
+		var t ticketingScan
+		dest := []interface{}{&e.ID, &e.Title, &e.Tags, &e.Content, &e.Slug, &e.DateStart, &e.DateEnd, &e.SeriesID}
+		dest = append(dest, t.dest()...)
+		dest = append(dest, &v.ID, &v.Name)
+		if err := rows.Scan(dest...); err != nil {
 			continue
 		}
+		t.apply(&e.EventTicketing)
+		e.VenueID = v.ID
 		e.Venue = &v
-
-		// Bandas asociadas
-		bandRows, _ := h.DB.Select(`
-			SELECT b.id, b.name, b.slug
-			FROM bands b
-			JOIN events_bands eb ON b.id = eb.id_band
-			WHERE eb.id_event = ?
-		`, e.ID)
-		defer bandRows.Close()
-		for bandRows.Next() {
-			var b Band
-			if err := bandRows.Scan(&b.ID, &b.Name, &b.Slug); err == nil {
-				e.Bands = append(e.Bands, b)
-			}
-		}
-
 		events = append(events, e)
 	}
+	rows.Close()
+
+	// Bandas y demás relaciones de todos los eventos, una consulta por tipo
+	if err := h.newLoader().eventRelations(events, fs); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al cargar las relaciones de los eventos", err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(events)
+	fs.encode(w, events)
 }
 
 // GetEventBands devuelve todas las bandas asociadas a un evento específico.
@@ -563,11 +546,11 @@ func (h *AuthHandler) GetEventBands(w http.ResponseWriter, r *http.Request) {
 	var exists bool
 	row, _ := h.DB.SelectRow("SELECT EXISTS(SELECT 1 FROM events WHERE id = ?)", eventID)
 	if err := row.Scan(&exists); err != nil {
-		http.Error(w, "Error al verificar evento: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al verificar evento", err)
 		return
 	}
 	if !exists {
-		http.Error(w, "Evento no encontrado", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, "Evento no encontrado")
 		return
 	}
 
@@ -581,7 +564,7 @@ func (h *AuthHandler) GetEventBands(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := h.DB.Select(query, eventID)
 	if err != nil {
-		http.Error(w, "Error al obtener bandas: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener bandas", err)
 		return
 	}
 	defer rows.Close()
@@ -591,7 +574,7 @@ func (h *AuthHandler) GetEventBands(w http.ResponseWriter, r *http.Request) {
 		var b Band
 
 		if err := rows.Scan(&b.ID, &b.Name, &b.Bio, &b.Slug); err != nil {
-			http.Error(w, "Error al escanear bandas: "+err.Error(), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al escanear bandas", err)
 			return
 		}
 
@@ -616,30 +599,28 @@ func (h *AuthHandler) GetEventBands(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param file formData file true "Archivo de imagen a subir"
 // @Param slug formData string true "Slug del evento"
-// @Security BearerAuth
 // @Success 200 {object} map[string]string "URL de la imagen subida"
 // @Failure 400 {string} string "Error en la solicitud"
-// @Failure 401 {string} string "No autorizado"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /events/upload-image [post]
 func (h *AuthHandler) UploadEventImage(w http.ResponseWriter, r *http.Request) {
 	r.ParseMultipartForm(10 << 20)
 	file, handler, err := r.FormFile("file")
 	if err != nil {
-		http.Error(w, "No se pudo leer el archivo", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "No se pudo leer el archivo")
 		return
 	}
 	defer file.Close()
 
 	slug := r.FormValue("slug")
 	if slug == "" {
-		http.Error(w, "Slug faltante", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Slug faltante")
 		return
 	}
 
 	tempFile, err := os.CreateTemp("", "upload-*.jpg")
 	if err != nil {
-		http.Error(w, "No se pudo crear archivo temporal", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se pudo crear archivo temporal")
 		return
 	}
 	defer os.Remove(tempFile.Name())
@@ -647,7 +628,7 @@ func (h *AuthHandler) UploadEventImage(w http.ResponseWriter, r *http.Request) {
 
 	err = uploadToSpaces(tempFile.Name(), "events/"+slug+".jpg", handler.Header.Get("Content-Type"))
 	if err != nil {
-		http.Error(w, "Error al subir imagen: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al subir imagen", err)
 		return
 	}
 
@@ -663,10 +644,12 @@ func (h *AuthHandler) UploadEventImage(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param event body Event true "Datos del evento a crear"
+// @Param force query bool false "Ignorar superposición de horarios (solo administradores)"
 // @Security BearerAuth
 // @Success 201 {object} Event "Evento creado exitosamente"
 // @Failure 400 {string} string "Error en la solicitud"
 // @Failure 401 {string} string "No autorizado"
+// @Failure 409 {array} EventConflict "Eventos superpuestos en el mismo venue"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /events [post]
 func (h *AuthHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
@@ -678,32 +661,63 @@ func (h *AuthHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		Slug      string `json:"slug"`
 		DateStart string `json:"date_start"`
 		DateEnd   string `json:"date_end"`
-		BandIDs   []int  `json:"band_ids"` // <-- Lista de bandas
+		BandIDs   []int  `json:"band_ids"`  // <-- Lista de bandas
+		SeriesID  int    `json:"id_series"` // festival o ciclo (opcional)
+		EventTicketing
 	}
 
 	var input EventInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Error al decodificar el cuerpo", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar el cuerpo")
+		return
+	}
+	if err := input.EventTicketing.normalize(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Datos de entradas inválidos", err)
 		return
 	}
 
 	// Obtener el ID del usuario autenticado
 	claims, ok := r.Context().Value("user").(*models.Claims)
 	if !ok {
-		http.Error(w, "Usuario no autenticado", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, "Usuario no autenticado")
 		return
 	}
 	userID := claims.UserID
 
+	// Verificar superposición de horarios en el mismo venue (un admin puede forzarla)
+	force := r.URL.Query().Get("force") == "true" && claims.Role == "admin"
+	if !force {
+		conflicts, err := h.findVenueConflicts(input.IDVenue, input.DateStart, input.DateEnd, 0)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "Error al verificar conflictos de horario")
+			return
+		}
+		if len(conflicts) > 0 {
+			writeVenueConflict(w, r, conflicts)
+			return
+		}
+	}
+
 	// Insertar el evento
-	eventID, err := h.DB.Insert(false, `
-		INSERT INTO events (id_venue, title, tags, content, slug, date_start, date_end)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		input.IDVenue, input.Title, input.Tags, input.Content, input.Slug, input.DateStart, input.DateEnd)
+	event := Event{
+		VenueID:        input.IDVenue,
+		Title:          input.Title,
+		Tags:           input.Tags,
+		Content:        input.Content,
+		Slug:           input.Slug,
+		DateStart:      input.DateStart,
+		DateEnd:        input.DateEnd,
+		SeriesID:       input.SeriesID,
+		EventTicketing: input.EventTicketing,
+	}
+	eventID, err := h.insertEvent(&event)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
+	if input.SeriesID > 0 {
+		h.refreshSeriesDates(input.SeriesID)
+	}
 
 	// Insertar relaciones en events_bands
 	for _, bandID := range input.BandIDs {
@@ -724,19 +738,28 @@ func (h *AuthHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	if linkErr != nil {
 		fmt.Printf("Error al vincular evento %d con usuario %d: %v\n", eventID, userID, linkErr)
 	}
+	event.ID = eventID
+	h.bus.Publish(EventPublished{Actor: actorFrom(r), Event: event, Origin: OriginAPI})
 
 	// Devolver el evento creado
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"id":         eventID,
 		"title":      input.Title,
-		"slug":       input.Slug,
+		"slug":       event.Slug,
 		"tags":       input.Tags,
 		"content":    input.Content,
 		"date_start": input.DateStart,
 		"date_end":   input.DateEnd,
 		"id_venue":   input.IDVenue,
 		"band_ids":   input.BandIDs,
+
+		"price_tiers":         input.PriceTiers,
+		"is_free":             input.IsFree,
+		"free_until_capacity": input.FreeUntilCapacity,
+		"ticket_url":          input.TicketURL,
+		"min_age":             input.MinAge,
+		"door_time":           input.DoorTime,
 	})
 }
 
@@ -749,11 +772,13 @@ func (h *AuthHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path int true "ID del evento a actualizar"
 // @Param event body Event true "Datos actualizados del evento"
+// @Param force query bool false "Ignorar superposición de horarios (solo administradores)"
 // @Security BearerAuth
 // @Success 200 {object} Event "Evento actualizado exitosamente"
 // @Failure 400 {string} string "Error en la solicitud"
 // @Failure 401 {string} string "No autorizado"
 // @Failure 404 {string} string "Evento no encontrado"
+// @Failure 409 {array} EventConflict "Eventos superpuestos en el mismo venue"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /events/{id} [put]
 func (h *AuthHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
@@ -766,24 +791,75 @@ func (h *AuthHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 		DateStart string `json:"date_start"`
 		DateEnd   string `json:"date_end"`
 		BandIDs   []int  `json:"band_ids"`
+		SeriesID  *int   `json:"id_series"` // si no se envía, no se modifica; 0 lo desvincula
+		EventTicketing
 	}
 
 	id := chi.URLParam(r, "id")
 	var input EventInput
 
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Error al decodificar el cuerpo", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar el cuerpo")
+		return
+	}
+	if err := input.EventTicketing.normalize(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Datos de entradas inválidos", err)
+		return
+	}
+
+	// Verificar superposición de horarios en el mismo venue (un admin puede forzarla)
+	claims, _ := r.Context().Value("user").(*models.Claims)
+	force := r.URL.Query().Get("force") == "true" && claims != nil && claims.Role == "admin"
+	if !force {
+		eventID, _ := strconv.Atoi(id)
+		conflicts, err := h.findVenueConflicts(input.IDVenue, input.DateStart, input.DateEnd, eventID)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "Error al verificar conflictos de horario")
+			return
+		}
+		if len(conflicts) > 0 {
+			writeVenueConflict(w, r, conflicts)
+			return
+		}
+	}
+
+	prevSlug := h.currentSlug("event", id)
+	var yearHint string
+	if start, err := parseEventDate(input.DateStart); err == nil {
+		yearHint = strconv.Itoa(start.Year())
+	}
+	slug, err := h.updateSlug("event", prevSlug, input.Slug, input.Title, yearHint)
+	if err != nil {
+		writeSlugError(w, r, err)
 		return
 	}
+	input.Slug = slug
+	defer h.releaseSlug("event", slug)
 
-	_, err := h.DB.Update(false, `
-		UPDATE events SET id_venue=?, title=?, tags=?, content=?, slug=?, date_start=?, date_end=?
-		WHERE id = ?`,
-		input.IDVenue, input.Title, input.Tags, input.Content, input.Slug, input.DateStart, input.DateEnd, id)
+	args := []interface{}{input.IDVenue, input.Title, input.Tags, input.Content, input.Slug, input.DateStart, input.DateEnd}
+	args = append(args, input.EventTicketing.sqlValues()...)
+	args = append(args, id)
+	_, err = h.DB.Update(false, `
+		UPDATE events SET id_venue=?, title=?, tags=?, content=?, slug=?, date_start=?, date_end=?,
+			price_tiers=?, is_free=?, free_until_capacity=?, ticket_url=?, min_age=?, door_time=?
+		WHERE id = ?`, args...)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
+	h.recordSlugChange("event", prevSlug, input.Slug)
+
+	// Festival o ciclo al que pertenece
+	if input.SeriesID != nil {
+		var seriesID interface{}
+		if *input.SeriesID > 0 {
+			seriesID = *input.SeriesID
+		}
+		_, _ = h.DB.Update(false, "UPDATE events SET id_series = ? WHERE id = ?", seriesID, id)
+		if *input.SeriesID > 0 {
+			h.refreshSeriesDates(*input.SeriesID)
+		}
+	}
 
 	// Eliminar asociaciones anteriores
 	_, _ = h.DB.Delete(false, "DELETE FROM events_bands WHERE id_event = ?", id)
@@ -797,6 +873,7 @@ func (h *AuthHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 			fmt.Printf("Error insertando banda %d: %v\n", bandID, err)
 		}
 	}
+	h.reindexSearch("event", prevSlug.ID)
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -817,6 +894,10 @@ func (h *AuthHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 // @Router /events/{id} [delete]
 func (h *AuthHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	eventID, _ := strconv.Atoi(id)
+
+	// Los datos para los suscriptores (webhooks) se leen antes de que el evento desaparezca
+	snapshot, _ := h.webhookEventData(eventID)
 
 	// Primero eliminar las relaciones en events_bands
 	_, _ = h.DB.Delete(false, "DELETE FROM events_bands WHERE id_event = ?", id)
@@ -824,9 +905,10 @@ func (h *AuthHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	// Luego eliminar el evento
 	_, err := h.DB.Delete(false, "DELETE FROM events WHERE id = ?", id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
+	h.bus.Publish(EventCancelled{Actor: actorFrom(r), EventID: eventID, Snapshot: snapshot})
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -839,6 +921,8 @@ func (h *AuthHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param user_id path int true "ID del usuario"
+// @Param fields query string false "Campos a devolver, separados por coma (ej. id,title,date_start,venue)"
+// @Param include query string false "Relaciones a incluir: venue, bands, genres, series (por defecto bands)"
 // @Success 200 {array} Event "Lista de eventos vinculados al usuario"
 // @Failure 400 {string} string "ID inválido"
 // @Failure 500 {string} string "Error al obtener los eventos"
@@ -853,29 +937,35 @@ func (h *AuthHandler) GetUserEvents(w http.ResponseWriter, r *http.Request) {
 	// Verificar que el usuario autenticado tenga permiso para ver estos eventos
 	claims, ok := r.Context().Value("user").(*models.Claims)
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Usuario no autenticado"})
+		writeError(w, r, http.StatusUnauthorized, "Usuario no autenticado")
 		return
 	}
 
 	// Convertir userID de string a uint para comparar con claims.UserID
 	userIDUint, err := strconv.ParseUint(userID, 10, 32)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "ID de usuario inválido"})
+		writeError(w, r, http.StatusBadRequest, "ID de usuario inválido")
 		return
 	}
 
 	// Solo permitir acceso si es el mismo usuario o es admin
 	if claims.UserID != uint(userIDUint) && claims.Role != "admin" {
-		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(map[string]string{"error": "No tienes permiso para ver estos eventos"})
+		writeError(w, r, http.StatusForbidden, "No tienes permiso para ver estos eventos")
+		return
+	}
+
+	fs, err := eventFieldSet.parse(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
 
 	// Consultar los eventos vinculados al usuario
 	rows, err := h.DB.Select(`
-		SELECT DISTINCT e.id, e.title, e.tags, e.content, e.slug, e.date_start, e.date_end, e.id_venue, v.name, v.address, v.slug, el.rol
+		SELECT DISTINCT e.id, e.title, `+fs.pick("tags", "e.tags")+`, `+fs.pick("content", "e.content")+`,
+			e.slug, e.date_start, e.date_end, e.id_venue, IFNULL(e.id_series, 0),
+			`+eventTicketingColumns+`,
+			v.name, v.address, v.slug, el.rol
 		FROM events e
 		INNER JOIN event_links el ON e.id = el.event_id
 		INNER JOIN venues v ON e.id_venue = v.id
@@ -883,8 +973,7 @@ func (h *AuthHandler) GetUserEvents(w http.ResponseWriter, r *http.Request) {
 		ORDER BY e.date_start DESC`, userID)
 
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Error al consultar los eventos: " + err.Error()})
+		writeError(w, r, http.StatusInternalServerError, "Error al consultar los eventos", err)
 		return
 	}
 	defer rows.Close()
@@ -895,51 +984,45 @@ func (h *AuthHandler) GetUserEvents(w http.ResponseWriter, r *http.Request) {
 		var event Event
 		var venue Venue
 		var rol string
+		var t ticketingScan
 
-		err := rows.Scan(
+		dest := []interface{}{
 			&event.ID, &event.Title, &event.Tags, &event.Content, &event.Slug,
-			&event.DateStart, &event.DateEnd, &event.VenueID,
-			&venue.Name, &venue.Address, &venue.Slug, &rol)
+			&event.DateStart, &event.DateEnd, &event.VenueID, &event.SeriesID}
+		dest = append(dest, t.dest()...)
+		dest = append(dest, &venue.Name, &venue.Address, &venue.Slug, &rol)
+		err := rows.Scan(dest...)
 
 		if err != nil {
 			fmt.Printf("Error al escanear evento: %v\n", err)
 			continue
 		}
+		t.apply(&event.EventTicketing)
 
 		// Asignar el venue al evento
+		venue.ID = event.VenueID
 		event.Venue = &venue
 
 		// Agregar el rol como parte de los datos del evento
 		event.Rol = rol
 
-		// Obtener las bandas asociadas al evento
-		bandRows, err := h.DB.Select(`
-			SELECT b.id, b.name, b.slug
-			FROM bands b
-			JOIN events_bands eb ON b.id = eb.id_band
-			WHERE eb.id_event = ?`, event.ID)
-		if err == nil {
-			var bands []Band
-			for bandRows.Next() {
-				var band Band
-				if err := bandRows.Scan(&band.ID, &band.Name, &band.Slug); err == nil {
-					bands = append(bands, band)
-				}
-			}
-			bandRows.Close()
-			event.Bands = bands
-		}
-
 		events = append(events, event)
 	}
+	rows.Close()
+
+	// Bandas y demás relaciones de todos los eventos, una consulta por tipo
+	if err := h.newLoader().eventRelations(events, fs); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al cargar las relaciones de los eventos", err)
+		return
+	}
 
-	json.NewEncoder(w).Encode(events)
+	fs.encode(w, events)
 }
 
 // CheckEventSlug verifica si un slug de evento ya existe en la base de datos.
 //
 // @Summary Verifica disponibilidad de slug
-// @Description Comprueba si un slug de evento ya está en uso
+// @Description Comprueba si un slug de evento ya está en uso. Obsoleto: usar GET /slugs/check?type=event
 // @Tags eventos
 // @Accept json
 // @Produce json
@@ -956,23 +1039,14 @@ func (h *AuthHandler) CheckEventSlug(w http.ResponseWriter, r *http.Request) {
 	// Obtener el slug de la URL
 	slug := chi.URLParam(r, "slug")
 	if slug == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Falta el slug"})
+		writeError(w, r, http.StatusBadRequest, "Falta el slug")
 		return
 	}
 
-	// Verificar si el slug ya existe
-	row, err := h.DB.SelectRow("SELECT EXISTS(SELECT 1 FROM events WHERE slug = ?)", slug)
+	// Verificar si el slug ya existe (incluye slugs anteriores y reservas vigentes)
+	exists, err := h.slugTaken("event", slug)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Error al consultar la base de datos: " + err.Error()})
-		return
-	}
-
-	var exists bool
-	if err := row.Scan(&exists); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Error al consultar la base de datos: " + err.Error()})
+		writeError(w, r, http.StatusInternalServerError, "Error al consultar la base de datos", err)
 		return
 	}
 
@@ -1048,7 +1122,7 @@ func (h *AuthHandler) GenerateStoryImageFromEvent(eventID int, flyerURL string)
 		venueStr += ", " + venue.Address
 	}
 
-	return GenerateStoryImageFromFlyer(event.Title, flyerURL, dateStr, hour, venueStr)
+	return GenerateStoryImageFromFlyer(event.Title, flyerURL, dateStr, hour, venueStr, event.EventTicketing.storyLine())
 }
 func (h *AuthHandler) PublishEventToInstagramByID(eventID int) error {
 	cfg, err := ini.Load("data.conf")
@@ -1067,16 +1141,20 @@ func (h *AuthHandler) PublishEventToInstagramByID(eventID int) error {
 		DateStart string
 		Slug      string
 		VenueName sql.NullString
+		EventTicketing
 	}
 	row, _ := h.DB.SelectRow(`
-		SELECT e.title, e.content, e.date_start, e.slug, v.name as venue_name 
+		SELECT e.title, e.content, e.date_start, e.slug, v.name as venue_name, `+eventTicketingColumns+`
 		FROM events e 
 		LEFT JOIN venues v ON e.id_venue = v.id 
 		WHERE e.id = ?`, eventID)
 
-	if err := row.Scan(&event.Title, &event.Content, &event.DateStart, &event.Slug, &event.VenueName); err != nil {
+	var t ticketingScan
+	dest := append([]interface{}{&event.Title, &event.Content, &event.DateStart, &event.Slug, &event.VenueName}, t.dest()...)
+	if err := row.Scan(dest...); err != nil {
 		return fmt.Errorf("error al obtener datos del evento: %v", err)
 	}
+	t.apply(&event.EventTicketing)
 
 	imageURL := fmt.Sprintf("%s/events/%s.jpg", mediaURL, event.Slug)
 	venueName := "Lugar a confirmar"
@@ -1085,8 +1163,8 @@ func (h *AuthHandler) PublishEventToInstagramByID(eventID int) error {
 	}
 	contentCleaned := cleanHTML(event.Content)
 
-	caption := fmt.Sprintf("🎵 %s\n\n📍 %s\n📅 %s\n\n%s\n\n#BroteColectivo #AgendaCulturalBroteColectivo #AgendaCultural #Música #Eventos",
-		event.Title, venueName, event.DateStart, contentCleaned)
+	caption := fmt.Sprintf("🎵 %s\n\n📍 %s\n📅 %s\n%s\n\n%s\n\n#BroteColectivo #AgendaCulturalBroteColectivo #AgendaCultural #Música #Eventos",
+		event.Title, venueName, event.DateStart, event.EventTicketing.captionLines(), contentCleaned)
 
 	// Crear media container
 	feedURL := fmt.Sprintf("https://graph.facebook.com/v21.0/%s/media?image_url=%s&caption=%s&access_token=%s",
@@ -1199,7 +1277,7 @@ func (h *AuthHandler) PublishEventToInstagram(w http.ResponseWriter, r *http.Req
 	// Verificar autenticación (solo admin)
 	// claims, ok := r.Context().Value("user").(*models.Claims)
 	// if !ok || claims.Role != "admin" {
-	// 	http.Error(w, "No autorizado. Se requiere rol de administrador", http.StatusUnauthorized)
+	// 	writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
 	// 	return
 	// }
 
@@ -1217,7 +1295,7 @@ func (h *AuthHandler) PublishEventToInstagram(w http.ResponseWriter, r *http.Req
 	cfg, err := ini.Load("data.conf")
 	if err != nil {
 		fmt.Printf("[DEBUG] Error al cargar configuración: %v\n", err)
-		http.Error(w, "Error al cargar configuración", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al cargar configuración")
 		return
 	}
 
@@ -1232,19 +1310,23 @@ func (h *AuthHandler) PublishEventToInstagram(w http.ResponseWriter, r *http.Req
 		DateStart string
 		Slug      string
 		VenueName sql.NullString
+		EventTicketing
 	}
 
 	row, _ := h.DB.SelectRow(`
-		SELECT e.title, e.content, e.date_start, e.slug, v.name as venue_name 
+		SELECT e.title, e.content, e.date_start, e.slug, v.name as venue_name, `+eventTicketingColumns+`
 		FROM events e 
 		LEFT JOIN venues v ON e.id_venue = v.id 
 		WHERE e.id = ?`, eventID)
 
-	if err := row.Scan(&event.Title, &event.Content, &event.DateStart, &event.Slug, &event.VenueName); err != nil {
+	var t ticketingScan
+	dest := append([]interface{}{&event.Title, &event.Content, &event.DateStart, &event.Slug, &event.VenueName}, t.dest()...)
+	if err := row.Scan(dest...); err != nil {
 		fmt.Printf("[DEBUG] Error al obtener datos del evento: %v\n", err)
-		http.Error(w, "Error al obtener datos del evento: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener datos del evento", err)
 		return
 	}
+	t.apply(&event.EventTicketing)
 
 	fmt.Printf("[DEBUG] Datos del evento: Title=%s, Slug=%s, DateStart=%s\n", event.Title, event.Slug, event.DateStart)
 
@@ -1263,10 +1345,11 @@ func (h *AuthHandler) PublishEventToInstagram(w http.ResponseWriter, r *http.Req
 	contentCleaned := cleanHTML(event.Content)
 	fmt.Printf("[DEBUG] Contenido limpio: %s\n", contentCleaned)
 
-	caption := fmt.Sprintf("🎵 %s\n\n📍 %s\n📅 %s\n\n%s\n\n#BroteColectivo #AgendaCulturalBroteColectivo #AgendaCultural #Música #Eventos",
+	caption := fmt.Sprintf("🎵 %s\n\n📍 %s\n📅 %s\n%s\n\n%s\n\n#BroteColectivo #AgendaCulturalBroteColectivo #AgendaCultural #Música #Eventos",
 		event.Title,
 		venueName,
 		event.DateStart,
+		event.EventTicketing.captionLines(),
 		contentCleaned,
 	)
 
@@ -1379,7 +1462,7 @@ func (h *AuthHandler) PublishEventToInstagram(w http.ResponseWriter, r *http.Req
 	eventIDInt, err := strconv.Atoi(eventID)
 	if err != nil {
 		log.Printf("Error al convertir eventID a entero: %v", err)
-		http.Error(w, "ID del evento inválido", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "ID del evento inválido")
 		return
 	}
 
@@ -1533,27 +1616,33 @@ func truncateText(text string, maxLength int) string {
 // GetEventByID obtiene un evento por su ID
 func (h *AuthHandler) getEventByIDInternal(id int) (*Event, error) {
 	row, err := h.DB.SelectRow(`
-		SELECT id, id_venue, title, tags, content, slug, date_start, date_end
-		FROM events
-		WHERE id = ?`, id)
+		SELECT e.id, e.id_venue, IFNULL(e.id_series, 0), e.title, e.tags, e.content, e.slug, e.date_start, e.date_end,
+			`+eventTicketingColumns+`
+		FROM events e
+		WHERE e.id = ?`, id)
 	if err != nil {
 		return nil, err
 	}
 
 	var event Event
-	err = row.Scan(
+	var t ticketingScan
+	dest := []interface{}{
 		&event.ID,
 		&event.VenueID,
+		&event.SeriesID,
 		&event.Title,
 		&event.Tags,
 		&event.Content,
 		&event.Slug,
 		&event.DateStart,
 		&event.DateEnd,
-	)
+	}
+	dest = append(dest, t.dest()...)
+	err = row.Scan(dest...)
 	if err != nil {
 		return nil, err
 	}
+	t.apply(&event.EventTicketing)
 
 	return &event, nil
 }
@@ -1584,7 +1673,7 @@ func (h *AuthHandler) getVenueByIDInternal(id int) (*Venue, error) {
 
 	return &venue, nil
 }
-func GenerateStoryImageFromFlyer(title, flyerURL, date, hour, venue string) (string, error) {
+func GenerateStoryImageFromFlyer(title, flyerURL, date, hour, venue, ticketing string) (string, error) {
 	const (
 		width  = 1080
 		height = 1920
@@ -1683,6 +1772,13 @@ func GenerateStoryImageFromFlyer(title, flyerURL, date, hour, venue string) (str
 	d.Dot = fixed.P((width-subtitle2Width)/2, yOffset+70)
 	d.DrawString(subtitle2)
 
+	// Precio, edad mínima y apertura de puertas (si hay datos)
+	if ticketing != "" {
+		ticketingWidth := d.MeasureString(ticketing).Round()
+		d.Dot = fixed.P((width-ticketingWidth)/2, yOffset+120)
+		d.DrawString(ticketing)
+	}
+
 	promoText := "Enterate de este evento y otros en #AgendaCultural"
 	d.Face = promoFace // ¡Muy importante! Definir la fuente antes de medir
 
@@ -1884,7 +1980,7 @@ func (h *AuthHandler) GenerateEventDescription(w http.ResponseWriter, r *http.Re
 
 	var requestData GenerateDescriptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		http.Error(w, "Error al procesar la solicitud", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Error al procesar la solicitud")
 		return
 	}
 
@@ -1944,13 +2040,13 @@ Información proporcionada: %s%s`,
 	if requestData.FlyerURL != "" {
 		resp, err := http.Get(requestData.FlyerURL)
 		if err != nil {
-			http.Error(w, "No se pudo descargar el flyer", http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, "No se pudo descargar el flyer")
 			return
 		}
 		defer resp.Body.Close()
 		imgBytes, err := io.ReadAll(resp.Body)
 		if err != nil {
-			http.Error(w, "No se pudo leer el flyer", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "No se pudo leer el flyer")
 			return
 		}
 		imgBase64 := base64.StdEncoding.EncodeToString(imgBytes)
@@ -1975,13 +2071,13 @@ Información proporcionada: %s%s`,
 
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
-		http.Error(w, "Error al preparar la solicitud", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al preparar la solicitud")
 		return
 	}
 
 	req, err := http.NewRequest("POST", geminiURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		http.Error(w, "Error al crear la solicitud", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al crear la solicitud")
 		return
 	}
 
@@ -1990,7 +2086,7 @@ Información proporcionada: %s%s`,
 	client := &http.Client{}
 	respGemini, err := client.Do(req)
 	if err != nil {
-		http.Error(w, "Error al realizar la solicitud a Gemini", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al realizar la solicitud a Gemini")
 		return
 	}
 	defer respGemini.Body.Close()
@@ -2006,12 +2102,12 @@ Información proporcionada: %s%s`,
 	}
 
 	if err := json.NewDecoder(respGemini.Body).Decode(&geminiResponse); err != nil {
-		http.Error(w, "Error al leer la respuesta de Gemini", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al leer la respuesta de Gemini")
 		return
 	}
 
 	if len(geminiResponse.Candidates) == 0 || len(geminiResponse.Candidates[0].Content.Parts) == 0 {
-		http.Error(w, "No se recibió respuesta de Gemini", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se recibió respuesta de Gemini")
 		return
 	}
 
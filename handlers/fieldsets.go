@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// fieldSet son los campos y relaciones que pidió el cliente con ?fields= e ?include=.
+// Sin fields se devuelven todos los campos; las relaciones se cargan en lote, una consulta por tipo.
+type fieldSet struct {
+	fields   map[string]bool // nil: todos los campos
+	includes map[string]bool
+}
+
+// fieldSetSpec declara los campos y relaciones de un recurso. defaults son las relaciones
+// que se devuelven cuando no se pide fields, para no romper a los clientes actuales.
+type fieldSetSpec struct {
+	fields    []string
+	relations []string
+	defaults  []string
+}
+
+// parse lee fields e include. Un nombre de relación dentro de fields también la incluye.
+func (s fieldSetSpec) parse(r *http.Request) (*fieldSet, error) {
+	f := &fieldSet{includes: map[string]bool{}}
+
+	if fieldsParam := r.URL.Query().Get("fields"); fieldsParam != "" {
+		f.fields = map[string]bool{"id": true}
+		for _, name := range splitParamList(fieldsParam) {
+			switch {
+			case slices.Contains(s.fields, name):
+				f.fields[name] = true
+			case slices.Contains(s.relations, name):
+				f.fields[name] = true
+				f.includes[name] = true
+			default:
+				return nil, fieldError("fields", "invalid", "Campo desconocido: "+name)
+			}
+		}
+	} else {
+		for _, name := range s.defaults {
+			f.includes[name] = true
+		}
+	}
+
+	for _, name := range splitParamList(r.URL.Query().Get("include")) {
+		if !slices.Contains(s.relations, name) {
+			return nil, fieldError("include", "invalid", "Relación desconocida: "+name)
+		}
+		f.includes[name] = true
+	}
+	return f, nil
+}
+
+// splitParamList separa una lista por comas, sin espacios ni elementos vacíos
+func splitParamList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// wants indica si el campo va en la respuesta
+func (f *fieldSet) wants(field string) bool {
+	return f.fields == nil || f.fields[field] || f.includes[field]
+}
+
+// include indica si hay que cargar la relación
+func (f *fieldSet) include(relation string) bool {
+	return f.includes[relation]
+}
+
+// pick devuelve la columna si el campo se pidió, o una cadena vacía para no leer
+// textos largos (contenido HTML, biografías) que no van en la respuesta
+func (f *fieldSet) pick(field, column string) string {
+	if f.wants(field) {
+		return column
+	}
+	return "''"
+}
+
+// encode escribe la lista dejando solo los campos pedidos
+func (f *fieldSet) encode(w io.Writer, items interface{}) error {
+	if f.fields == nil {
+		return json.NewEncoder(w).Encode(items)
+	}
+
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		for key := range row {
+			if !f.wants(key) {
+				delete(row, key)
+			}
+		}
+	}
+	return json.NewEncoder(w).Encode(rows)
+}
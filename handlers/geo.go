@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Las coordenadas se guardan en venues.location como POINT con SRID 4326 (WGS 84).
+// Se usa siempre orden latitud-longitud explícito para no depender del default de MySQL.
+const (
+	geoPointSQL    = "ST_GeomFromText(?, 4326, 'axis-order=lat-long')"
+	venueLatLngSQL = "ST_Latitude(v.location), ST_Longitude(v.location)"
+	// Distancia en kilómetros entre el venue (alias v) y un punto
+	venueDistanceSQL = "ST_Distance_Sphere(v.location, " + geoPointSQL + ") / 1000"
+)
+
+// Radio máximo aceptado en búsquedas por cercanía, en kilómetros
+const maxNearRadiusKm = 500
+
+// GeoPoint es una coordenada geográfica validada
+type GeoPoint struct {
+	Lat float64
+	Lng float64
+}
+
+// WKT devuelve el punto en formato Well-Known Text (orden lat-long)
+func (p GeoPoint) WKT() string {
+	return fmt.Sprintf("POINT(%f %f)", p.Lat, p.Lng)
+}
+
+// String devuelve el punto en el formato "lat,lng" que usa el campo latlng
+func (p GeoPoint) String() string {
+	return strconv.FormatFloat(p.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(p.Lng, 'f', -1, 64)
+}
+
+// parseLatLng interpreta y valida un texto "lat,lng". Devuelve nil si está vacío.
+func parseLatLng(value string) (*GeoPoint, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("las coordenadas deben tener el formato lat,lng")
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("latitud inválida: %s", parts[0])
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("longitud inválida: %s", parts[1])
+	}
+
+	if lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("la latitud debe estar entre -90 y 90")
+	}
+	if lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("la longitud debe estar entre -180 y 180")
+	}
+	if lat == 0 && lng == 0 {
+		return nil, fmt.Errorf("coordenadas inválidas (0,0)")
+	}
+
+	return &GeoPoint{Lat: lat, Lng: lng}, nil
+}
+
+// normalizeVenueLocation valida v.LatLng, lo normaliza y devuelve el WKT a guardar (nil si no hay coordenadas)
+func normalizeVenueLocation(v *Venue) (interface{}, error) {
+	point, err := parseLatLng(v.LatLng)
+	if err != nil {
+		return nil, fieldError("latlng", "invalid", err.Error())
+	}
+	if point == nil {
+		v.LatLng = ""
+		return nil, nil
+	}
+	v.LatLng = point.String()
+	lat, lng := point.Lat, point.Lng
+	v.Lat, v.Lng = &lat, &lng
+	return point.WKT(), nil
+}
+
+// nearParams lee ?near=lat,lng&radius=km. Devuelve nil si no se pidió búsqueda por cercanía.
+func nearParams(r *http.Request) (*GeoPoint, float64, error) {
+	near := r.URL.Query().Get("near")
+	if near == "" {
+		return nil, 0, nil
+	}
+
+	point, err := parseLatLng(near)
+	if err != nil {
+		return nil, 0, fieldError("near", "invalid", err.Error())
+	}
+
+	radius := 0.0
+	if radiusParam := r.URL.Query().Get("radius"); radiusParam != "" {
+		radius, err = strconv.ParseFloat(radiusParam, 64)
+		if err != nil || radius <= 0 {
+			return nil, 0, fieldError("radius", "invalid", "radio inválido")
+		}
+		if radius > maxNearRadiusKm {
+			return nil, 0, fieldError("radius", "range", fmt.Sprintf("el radio máximo es %d km", maxNearRadiusKm))
+		}
+	}
+
+	return point, radius, nil
+}
+
+// applyVenueCoordinates completa Lat/Lng a partir de los valores leídos de la base
+func applyVenueCoordinates(v *Venue, lat, lng sql.NullFloat64) {
+	if lat.Valid && lng.Valid {
+		latValue, lngValue := lat.Float64, lng.Float64
+		v.Lat, v.Lng = &latValue, &lngValue
+	}
+}
+
+// GeoJSONFeature es un punto del mapa con sus propiedades
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   map[string]interface{} `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONFeatureCollection es la colección que consume el mapa del frontend
+//
+// @Schema
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+// GetVenuesGeoJSON devuelve los venues con coordenadas como FeatureCollection GeoJSON.
+//
+// @Summary Mapa de venues
+// @Description Devuelve los venues con coordenadas en formato GeoJSON, con la cantidad de eventos próximos
+// @Tags venues
+// @Produce json
+// @Param near query string false "Centro de búsqueda (lat,lng)"
+// @Param radius query number false "Radio en kilómetros"
+// @Param upcoming query bool false "Solo venues con eventos próximos"
+// @Success 200 {object} GeoJSONFeatureCollection "Colección de puntos"
+// @Failure 400 {string} string "Parámetros inválidos"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Router /venues/geojson [get]
+func (h *AuthHandler) GetVenuesGeoJSON(w http.ResponseWriter, r *http.Request) {
+	near, radius, err := nearParams(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+
+	query := `
+		SELECT v.id, v.name, v.slug, v.address, v.city, ` + venueLatLngSQL + `,
+			(SELECT COUNT(*) FROM events e WHERE e.id_venue = v.id AND e.date_start >= NOW()) AS upcoming
+		FROM venues v
+		WHERE v.location IS NOT NULL
+	`
+	var queryParams []interface{}
+
+	if near != nil && radius > 0 {
+		query += " AND " + venueDistanceSQL + " <= ?"
+		queryParams = append(queryParams, near.WKT(), radius)
+	}
+	if r.URL.Query().Get("upcoming") == "true" {
+		query += " HAVING upcoming > 0"
+	}
+	query += " ORDER BY v.name ASC"
+
+	rows, err := h.DB.Select(query, queryParams...)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener venues", err)
+		return
+	}
+	defer rows.Close()
+
+	collection := GeoJSONFeatureCollection{Type: "FeatureCollection", Features: []GeoJSONFeature{}}
+	for rows.Next() {
+		var id, upcoming int
+		var name, slug, address, city string
+		var lat, lng float64
+		if err := rows.Scan(&id, &name, &slug, &address, &city, &lat, &lng, &upcoming); err != nil {
+			writeError(w, r, http.StatusInternalServerError, "Error al leer venues", err)
+			return
+		}
+		collection.Features = append(collection.Features, GeoJSONFeature{
+			Type: "Feature",
+			Geometry: map[string]interface{}{
+				"type": "Point",
+				// GeoJSON usa orden longitud, latitud
+				"coordinates": []float64{lng, lat},
+			},
+			Properties: map[string]interface{}{
+				"id":              id,
+				"name":            name,
+				"slug":            slug,
+				"address":         address,
+				"city":            city,
+				"upcoming_events": upcoming,
+			},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al leer venues", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	json.NewEncoder(w).Encode(collection)
+}
@@ -0,0 +1,1098 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// Límites de las consultas GraphQL, para que un pedido no dispare miles de consultas a MySQL.
+// La complejidad suma 1 por campo; los campos que devuelven listas multiplican a sus hijos
+// por el limit pedido (o por el valor por defecto).
+const (
+	maxGraphQLDepth      = 6
+	maxGraphQLComplexity = 2000
+
+	// Estimación para listas sin limit (bandas de un evento, géneros): suelen ser pocas
+	graphQLRelationEstimate = 5
+)
+
+type graphQLContextKey struct{}
+
+// graphQLRequest es lo que cada resolver necesita del pedido: el handler y su loader
+type graphQLRequest struct {
+	h      *AuthHandler
+	loader *loader
+}
+
+func gqlRequest(p graphql.ResolveParams) *graphQLRequest {
+	return p.Context.Value(graphQLContextKey{}).(*graphQLRequest)
+}
+
+// source devuelve el objeto padre del resolver, sea valor o puntero
+func source[T any](p graphql.ResolveParams) T {
+	switch v := p.Source.(type) {
+	case T:
+		return v
+	case *T:
+		if v != nil {
+			return *v
+		}
+	}
+	var zero T
+	return zero
+}
+
+// gqlError deja pasar los errores de validación (son del cliente) y registra el resto,
+// que al cliente le llega con el mensaje genérico
+func gqlError(err error) error {
+	var fields validationErrors
+	if errors.As(err, &fields) {
+		return err
+	}
+	log.Printf("[GraphQL] %v", err)
+	return errors.New(errorMessages["es"][ErrCodeInternal])
+}
+
+// listRequest arma un pedido con los argumentos como parámetros de URL, para reutilizar
+// los filtros, el orden y la paginación de los listados REST. filters son los argumentos
+// que se pasan tal cual; q, sort, limit y offset se pasan siempre.
+func listRequest(args map[string]interface{}, filters ...string) (*http.Request, *pageRequest, error) {
+	values := url.Values{}
+	for _, name := range append(filters, "q", "sort", "limit", "offset") {
+		if value, ok := args[name]; ok && value != nil {
+			values.Set(name, fmt.Sprint(value))
+		}
+	}
+	return pageRequestFor(values)
+}
+
+// lookupRequest arma el pedido de un solo elemento por id o slug
+func lookupRequest(args map[string]interface{}) (*http.Request, *pageRequest, error) {
+	values := url.Values{"limit": {"1"}}
+	if id, ok := args["id"].(int); ok {
+		values.Set("id[eq]", strconv.Itoa(id))
+	} else if slug, ok := args["slug"].(string); ok && slug != "" {
+		values.Set("slug[eq]", slug)
+	} else {
+		return nil, nil, fieldError("id", "required", "Indicá id o slug")
+	}
+	return pageRequestFor(values)
+}
+
+func pageRequestFor(values url.Values) (*http.Request, *pageRequest, error) {
+	r := &http.Request{Method: http.MethodGet, URL: &url.URL{RawQuery: values.Encode()}}
+	p, err := parsePageRequest(r, defaultPageLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, p, nil
+}
+
+// window aplica limit y offset a una relación ya cargada
+func window[T any](items []T, args map[string]interface{}) []T {
+	offset, _ := args["offset"].(int)
+	limit, _ := args["limit"].(int)
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	limit = min(limit, maxPageLimit)
+	if offset < 0 || offset >= len(items) {
+		return []T{}
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
+
+// first devuelve el primer elemento de una lista o nil
+func first[T any](items []T) interface{} {
+	if len(items) == 0 {
+		return nil
+	}
+	return items[0]
+}
+
+// Argumentos comunes
+var (
+	gqlPageArgs = graphql.FieldConfigArgument{
+		"limit":  &graphql.ArgumentConfig{Type: graphql.Int, Description: "Cantidad de elementos (por defecto 20, máximo 100)"},
+		"offset": &graphql.ArgumentConfig{Type: graphql.Int, Description: "Desplazamiento"},
+	}
+	gqlLookupArgs = graphql.FieldConfigArgument{
+		"id":   &graphql.ArgumentConfig{Type: graphql.Int},
+		"slug": &graphql.ArgumentConfig{Type: graphql.String},
+	}
+)
+
+// listArgs son los argumentos de un listado de primer nivel: búsqueda, orden, paginación y los filtros dados
+func listArgs(filters graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{
+		"q":    &graphql.ArgumentConfig{Type: graphql.String, Description: "Término de búsqueda"},
+		"sort": &graphql.ArgumentConfig{Type: graphql.String, Description: "Orden, con el mismo formato que ?sort= (ej. -date_start,title)"},
+	}
+	for name, arg := range gqlPageArgs {
+		args[name] = arg
+	}
+	for name, arg := range filters {
+		args[name] = arg
+	}
+	return args
+}
+
+// graphQLSchema es el esquema de solo lectura del catálogo. Los tipos reutilizan los structs
+// de los handlers: los campos simples se resuelven por su etiqueta json.
+var graphQLSchema = mustGraphQLSchema()
+
+func mustGraphQLSchema() graphql.Schema {
+	socialLinkType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "SocialLink",
+		Description: "Enlace a una red social",
+		Fields: graphql.Fields{
+			"network": &graphql.Field{Type: graphql.String},
+			"url":     &graphql.Field{Type: graphql.String},
+		},
+	})
+	socialField := func(social func(p graphql.ResolveParams) map[string]string) *graphql.Field {
+		return &graphql.Field{
+			Type: graphql.NewList(socialLinkType),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				links := []map[string]string{}
+				for network, link := range social(p) {
+					links = append(links, map[string]string{"network": network, "url": link})
+				}
+				sort.Slice(links, func(i, j int) bool { return links[i]["network"] < links[j]["network"] })
+				return links, nil
+			},
+		}
+	}
+
+	genreType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Genre",
+		Fields: graphql.Fields{
+			"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name": &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	albumType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Album",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"id_facebook": &graphql.Field{Type: graphql.String},
+			"title":       &graphql.Field{Type: graphql.String},
+			"slug":        &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	seriesType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Series",
+		Description: "Festival o ciclo",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"type":        &graphql.Field{Type: graphql.String},
+			"title":       &graphql.Field{Type: graphql.String},
+			"slug":        &graphql.Field{Type: graphql.String},
+			"description": &graphql.Field{Type: graphql.String},
+			"image":       &graphql.Field{Type: graphql.String},
+			"date_start":  &graphql.Field{Type: graphql.String},
+			"date_end":    &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	priceTierType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PriceTier",
+		Fields: graphql.Fields{
+			"name":     &graphql.Field{Type: graphql.String},
+			"amount":   &graphql.Field{Type: graphql.Float},
+			"currency": &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	accessibilityType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "VenueAccessibility",
+		Fields: graphql.Fields{
+			"step_free":           &graphql.Field{Type: graphql.Boolean},
+			"accessible_restroom": &graphql.Field{Type: graphql.Boolean},
+			"reserved_seating":    &graphql.Field{Type: graphql.Boolean},
+			"hearing_loop":        &graphql.Field{Type: graphql.Boolean},
+			"notes":               &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	hoursType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "VenueHours",
+		Fields: graphql.Fields{
+			"day":   &graphql.Field{Type: graphql.String},
+			"open":  &graphql.Field{Type: graphql.String},
+			"close": &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	var bandType, eventType, venueType, newsType, songType, videoType *graphql.Object
+
+	bandType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Band",
+		Description: "Artista o banda",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"name":   &graphql.Field{Type: graphql.String},
+				"bio":    &graphql.Field{Type: graphql.String},
+				"slug":   &graphql.Field{Type: graphql.String},
+				"social": socialField(func(p graphql.ResolveParams) map[string]string { return source[Band](p).Social }),
+				"genres": &graphql.Field{
+					Type:        graphql.NewList(genreType),
+					Description: "Géneros de sus canciones",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						id := source[Band](p).ID
+						genres, err := gqlRequest(p).loader.bandGenres.load([]int{id})
+						if err != nil {
+							return nil, gqlError(err)
+						}
+						return genres[id], nil
+					},
+				},
+				"events": &graphql.Field{
+					Type:        graphql.NewList(eventType),
+					Description: "Eventos en los que toca, del más reciente al más viejo",
+					Args:        gqlPageArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						id := source[Band](p).ID
+						events, err := gqlRequest(p).loader.bandEvents.load([]int{id})
+						if err != nil {
+							return nil, gqlError(err)
+						}
+						return window(events[id], p.Args), nil
+					},
+				},
+				"news": &graphql.Field{
+					Type: graphql.NewList(newsType),
+					Args: gqlPageArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						id := source[Band](p).ID
+						news, err := gqlRequest(p).loader.bandNews.load([]int{id})
+						if err != nil {
+							return nil, gqlError(err)
+						}
+						return window(news[id], p.Args), nil
+					},
+				},
+				"videos": &graphql.Field{
+					Type: graphql.NewList(videoType),
+					Args: gqlPageArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						id := source[Band](p).ID
+						videos, err := gqlRequest(p).loader.bandVideos.load([]int{id})
+						if err != nil {
+							return nil, gqlError(err)
+						}
+						return window(videos[id], p.Args), nil
+					},
+				},
+				"songs": &graphql.Field{
+					Type: graphql.NewList(songType),
+					Args: gqlPageArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						id := source[Band](p).ID
+						songs, err := gqlRequest(p).loader.bandSongs.load([]int{id})
+						if err != nil {
+							return nil, gqlError(err)
+						}
+						return window(songs[id], p.Args), nil
+					},
+				},
+			}
+		}),
+	})
+
+	ticketing := func(get func(t EventTicketing) interface{}) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			return get(source[Event](p).EventTicketing), nil
+		}
+	}
+
+	eventType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Event",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"title":      &graphql.Field{Type: graphql.String},
+				"tags":       &graphql.Field{Type: graphql.String},
+				"content":    &graphql.Field{Type: graphql.String},
+				"slug":       &graphql.Field{Type: graphql.String},
+				"date_start": &graphql.Field{Type: graphql.String},
+				"date_end":   &graphql.Field{Type: graphql.String},
+				"price_tiers": &graphql.Field{
+					Type:    graphql.NewList(priceTierType),
+					Resolve: ticketing(func(t EventTicketing) interface{} { return t.PriceTiers }),
+				},
+				"is_free": &graphql.Field{
+					Type:    graphql.Boolean,
+					Resolve: ticketing(func(t EventTicketing) interface{} { return t.IsFree }),
+				},
+				"free_until_capacity": &graphql.Field{
+					Type:    graphql.Boolean,
+					Resolve: ticketing(func(t EventTicketing) interface{} { return t.FreeUntilCapacity }),
+				},
+				"ticket_url": &graphql.Field{
+					Type:    graphql.String,
+					Resolve: ticketing(func(t EventTicketing) interface{} { return t.TicketURL }),
+				},
+				"min_age": &graphql.Field{
+					Type:    graphql.Int,
+					Resolve: ticketing(func(t EventTicketing) interface{} { return t.MinAge }),
+				},
+				"door_time": &graphql.Field{
+					Type:    graphql.String,
+					Resolve: ticketing(func(t EventTicketing) interface{} { return t.DoorTime }),
+				},
+				"venue": &graphql.Field{
+					Type: venueType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						e := source[Event](p)
+						venues, err := gqlRequest(p).loader.venues.load([]int{e.VenueID})
+						if err != nil {
+							return nil, gqlError(err)
+						}
+						v := venues[e.VenueID]
+						if v == nil {
+							return nil, nil
+						}
+						full := *v
+						if e.Venue != nil {
+							full.Distance = e.Venue.Distance
+						}
+						return full, nil
+					},
+				},
+				"bands": &graphql.Field{
+					Type: graphql.NewList(bandType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						id := source[Event](p).ID
+						bands, err := gqlRequest(p).loader.eventBands.load([]int{id})
+						if err != nil {
+							return nil, gqlError(err)
+						}
+						return bands[id], nil
+					},
+				},
+				"genres": &graphql.Field{
+					Type:        graphql.NewList(genreType),
+					Description: "Géneros de sus bandas",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						events := []Event{source[Event](p)}
+						fs := &fieldSet{includes: map[string]bool{"genres": true}}
+						if err := gqlRequest(p).loader.eventRelations(events, fs); err != nil {
+							return nil, gqlError(err)
+						}
+						return events[0].Genres, nil
+					},
+				},
+				"series": &graphql.Field{
+					Type: seriesType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						e := source[Event](p)
+						if e.SeriesID == 0 {
+							return nil, nil
+						}
+						series, err := gqlRequest(p).loader.series.load([]int{e.SeriesID})
+						if err != nil {
+							return nil, gqlError(err)
+						}
+						if s := series[e.SeriesID]; s != nil {
+							return *s, nil
+						}
+						return nil, nil
+					},
+				},
+			}
+		}),
+	})
+
+	profile := func(get func(v VenueProfile) interface{}) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			return get(source[Venue](p).VenueProfile), nil
+		}
+	}
+
+	venueType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Venue",
+		Description: "Espacio cultural",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"name":        &graphql.Field{Type: graphql.String},
+				"address":     &graphql.Field{Type: graphql.String},
+				"description": &graphql.Field{Type: graphql.String},
+				"slug":        &graphql.Field{Type: graphql.String},
+				"city":        &graphql.Field{Type: graphql.String},
+				"lat":         &graphql.Field{Type: graphql.Float},
+				"lng":         &graphql.Field{Type: graphql.Float},
+				"distance_km": &graphql.Field{Type: graphql.Float, Description: "Distancia al punto de near"},
+				"capacity": &graphql.Field{
+					Type:    graphql.Int,
+					Resolve: profile(func(v VenueProfile) interface{} { return v.Capacity }),
+				},
+				"stage_info": &graphql.Field{
+					Type:    graphql.String,
+					Resolve: profile(func(v VenueProfile) interface{} { return v.StageInfo }),
+				},
+				"backline": &graphql.Field{
+					Type:    graphql.NewList(graphql.String),
+					Resolve: profile(func(v VenueProfile) interface{} { return v.Backline }),
+				},
+				"accessibility": &graphql.Field{
+					Type:    accessibilityType,
+					Resolve: profile(func(v VenueProfile) interface{} { return v.Accessibility }),
+				},
+				"opening_hours": &graphql.Field{
+					Type:    graphql.NewList(hoursType),
+					Resolve: profile(func(v VenueProfile) interface{} { return v.OpeningHours }),
+				},
+				"contact_email": &graphql.Field{
+					Type:    graphql.String,
+					Resolve: profile(func(v VenueProfile) interface{} { return v.ContactEmail }),
+				},
+				"contact_phone": &graphql.Field{
+					Type:    graphql.String,
+					Resolve: profile(func(v VenueProfile) interface{} { return v.ContactPhone }),
+				},
+				"social": socialField(func(p graphql.ResolveParams) map[string]string { return source[Venue](p).Social }),
+				"events": &graphql.Field{
+					Type:        graphql.NewList(eventType),
+					Description: "Eventos del venue, del más reciente al más viejo",
+					Args:        gqlPageArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						id := source[Venue](p).ID
+						events, err := gqlRequest(p).loader.venueEvents.load([]int{id})
+						if err != nil {
+							return nil, gqlError(err)
+						}
+						return window(events[id], p.Args), nil
+					},
+				},
+			}
+		}),
+	})
+
+	newsType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "News",
+		Description: "Noticia",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"slug":    &graphql.Field{Type: graphql.String},
+				"date":    &graphql.Field{Type: graphql.String},
+				"title":   &graphql.Field{Type: graphql.String},
+				"content": &graphql.Field{Type: graphql.String},
+				"bands": &graphql.Field{
+					Type: graphql.NewList(bandType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						id := source[News](p).ID
+						bands, err := gqlRequest(p).loader.newsBands.load([]int{id})
+						if err != nil {
+							return nil, gqlError(err)
+						}
+						return bands[id], nil
+					},
+				},
+			}
+		}),
+	})
+
+	songType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Song",
+		Description: "Canción",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"title":     &graphql.Field{Type: graphql.String},
+				"slug":      &graphql.Field{Type: graphql.String},
+				"lyrics_id": &graphql.Field{Type: graphql.Int},
+				"genre":     &graphql.Field{Type: genreType},
+				"band": &graphql.Field{
+					Type: bandType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						s := source[Song](p)
+						bands, err := gqlRequest(p).loader.bands.load([]int{s.BandID})
+						if err != nil {
+							return nil, gqlError(err)
+						}
+						if b := bands[s.BandID]; b != nil {
+							return *b, nil
+						}
+						return nil, nil
+					},
+				},
+			}
+		}),
+	})
+
+	videoType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Video",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"title":      &graphql.Field{Type: graphql.String},
+				"slug":       &graphql.Field{Type: graphql.String},
+				"youtube_id": &graphql.Field{Type: graphql.String},
+				"bands": &graphql.Field{
+					Type: graphql.NewList(bandType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						id := source[Video](p).ID
+						bands, err := gqlRequest(p).loader.videoBands.load([]int{id})
+						if err != nil {
+							return nil, gqlError(err)
+						}
+						return bands[id], nil
+					},
+				},
+			}
+		}),
+	})
+
+	stringArg := &graphql.ArgumentConfig{Type: graphql.String}
+	intArg := &graphql.ArgumentConfig{Type: graphql.Int}
+	boolArg := &graphql.ArgumentConfig{Type: graphql.Boolean}
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"bands": &graphql.Field{
+				Type: graphql.NewList(bandType),
+				Args: listArgs(graphql.FieldConfigArgument{"name": stringArg, "slug": stringArg}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r, page, err := listRequest(p.Args, "name", "slug")
+					if err != nil {
+						return nil, err
+					}
+					return resolveBands(p, r, page, false)
+				},
+			},
+			"band": &graphql.Field{
+				Type: bandType,
+				Args: gqlLookupArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r, page, err := lookupRequest(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return resolveBands(p, r, page, true)
+				},
+			},
+			"events": &graphql.Field{
+				Type: graphql.NewList(eventType),
+				Args: listArgs(graphql.FieldConfigArgument{
+					"title":  stringArg,
+					"venue":  intArg,
+					"city":   stringArg,
+					"free":   boolArg,
+					"from":   &graphql.ArgumentConfig{Type: graphql.String, Description: "Desde esta fecha (YYYY-MM-DD)"},
+					"to":     &graphql.ArgumentConfig{Type: graphql.String, Description: "Hasta esta fecha (YYYY-MM-DD)"},
+					"near":   &graphql.ArgumentConfig{Type: graphql.String, Description: "Ordenar por cercanía a lat,lng"},
+					"radius": &graphql.ArgumentConfig{Type: graphql.Float, Description: "Radio en kilómetros (requiere near)"},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r, page, err := listRequest(p.Args, "title", "venue", "city", "free", "near", "radius")
+					if err != nil {
+						return nil, err
+					}
+					from, _ := p.Args["from"].(string)
+					to, _ := p.Args["to"].(string)
+					if from != "" || to != "" {
+						query := r.URL.Query()
+						query.Set("date_start[range]", from+","+to)
+						r.URL.RawQuery = query.Encode()
+					}
+					return resolveEvents(p, r, page, false)
+				},
+			},
+			"event": &graphql.Field{
+				Type: eventType,
+				Args: gqlLookupArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r, page, err := lookupRequest(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return resolveEvents(p, r, page, true)
+				},
+			},
+			"venues": &graphql.Field{
+				Type: graphql.NewList(venueType),
+				Args: listArgs(graphql.FieldConfigArgument{
+					"name":         stringArg,
+					"city":         stringArg,
+					"accessible":   boolArg,
+					"min_capacity": intArg,
+					"near":         &graphql.ArgumentConfig{Type: graphql.String, Description: "Ordenar por cercanía a lat,lng"},
+					"radius":       &graphql.ArgumentConfig{Type: graphql.Float, Description: "Radio en kilómetros (requiere near)"},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r, page, err := listRequest(p.Args, "name", "city", "accessible", "min_capacity", "near", "radius")
+					if err != nil {
+						return nil, err
+					}
+					return resolveVenues(p, r, page, false)
+				},
+			},
+			"venue": &graphql.Field{
+				Type: venueType,
+				Args: gqlLookupArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r, page, err := lookupRequest(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return resolveVenues(p, r, page, true)
+				},
+			},
+			"news": &graphql.Field{
+				Type: graphql.NewList(newsType),
+				Args: listArgs(graphql.FieldConfigArgument{"title": stringArg}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r, page, err := listRequest(p.Args, "title")
+					if err != nil {
+						return nil, err
+					}
+					return resolveNews(p, r, page, false)
+				},
+			},
+			"news_item": &graphql.Field{
+				Type: newsType,
+				Args: gqlLookupArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r, page, err := lookupRequest(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return resolveNews(p, r, page, true)
+				},
+			},
+			"songs": &graphql.Field{
+				Type: graphql.NewList(songType),
+				Args: listArgs(graphql.FieldConfigArgument{"title": stringArg, "band": intArg, "genre": intArg}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r, page, err := listRequest(p.Args, "title", "band", "genre")
+					if err != nil {
+						return nil, err
+					}
+					return resolveSongs(p, r, page, false)
+				},
+			},
+			"song": &graphql.Field{
+				Type: songType,
+				Args: gqlLookupArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r, page, err := lookupRequest(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return resolveSongs(p, r, page, true)
+				},
+			},
+			"videos": &graphql.Field{
+				Type: graphql.NewList(videoType),
+				Args: listArgs(graphql.FieldConfigArgument{"title": stringArg, "band": intArg}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r, page, err := listRequest(p.Args, "title", "band")
+					if err != nil {
+						return nil, err
+					}
+					return resolveVideos(p, r, page, false)
+				},
+			},
+			"video": &graphql.Field{
+				Type: videoType,
+				Args: gqlLookupArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r, page, err := lookupRequest(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return resolveVideos(p, r, page, true)
+				},
+			},
+			"albums": &graphql.Field{
+				Type:    graphql.NewList(albumType),
+				Args:    gqlPageArgs,
+				Resolve: resolveAlbums,
+			},
+			"album": &graphql.Field{
+				Type:    albumType,
+				Args:    graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}},
+				Resolve: resolveAlbums,
+			},
+			"genres": &graphql.Field{
+				Type:    graphql.NewList(genreType),
+				Resolve: resolveGenres,
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+	if err != nil {
+		panic("esquema GraphQL inválido: " + err.Error())
+	}
+	return schema
+}
+
+// Resolvers de primer nivel: usan las mismas consultas que los listados REST y anotan
+// los resultados en el loader para que sus relaciones se carguen en lote.
+
+func resolveBands(p graphql.ResolveParams, r *http.Request, page *pageRequest, single bool) (interface{}, error) {
+	g := gqlRequest(p)
+	bands, _, err := g.h.queryBands(r, page, &fieldSet{})
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	for _, b := range bands {
+		g.loader.queueBands(b.ID)
+	}
+	if single {
+		return first(bands), nil
+	}
+	return bands, nil
+}
+
+func resolveEvents(p graphql.ResolveParams, r *http.Request, page *pageRequest, single bool) (interface{}, error) {
+	g := gqlRequest(p)
+	events, _, err := g.h.queryEvents(r, page, &fieldSet{})
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	g.loader.queueEvents(events)
+	if single {
+		return first(events), nil
+	}
+	return events, nil
+}
+
+func resolveVenues(p graphql.ResolveParams, r *http.Request, page *pageRequest, single bool) (interface{}, error) {
+	g := gqlRequest(p)
+	venues, _, err := g.h.queryVenues(r, page)
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	g.loader.queueVenues(venues)
+	if single {
+		return first(venues), nil
+	}
+	return venues, nil
+}
+
+func resolveNews(p graphql.ResolveParams, r *http.Request, page *pageRequest, single bool) (interface{}, error) {
+	g := gqlRequest(p)
+	news, _, err := g.h.queryNewsPage(r, page, &fieldSet{})
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	g.loader.queueNews(news)
+	if single {
+		return first(news), nil
+	}
+	return news, nil
+}
+
+func resolveSongs(p graphql.ResolveParams, r *http.Request, page *pageRequest, single bool) (interface{}, error) {
+	g := gqlRequest(p)
+	songs, _, err := g.h.querySongs(r, page)
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	for _, s := range songs {
+		g.loader.bands.queue(s.BandID)
+	}
+	if single {
+		return first(songs), nil
+	}
+	return songs, nil
+}
+
+func resolveVideos(p graphql.ResolveParams, r *http.Request, page *pageRequest, single bool) (interface{}, error) {
+	g := gqlRequest(p)
+	videos, _, err := g.h.queryVideos(r, page)
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	g.loader.queueVideos(videos)
+	if single {
+		return first(videos), nil
+	}
+	return videos, nil
+}
+
+// resolveAlbums resuelve albums (paginado) y album(id)
+func resolveAlbums(p graphql.ResolveParams) (interface{}, error) {
+	h := gqlRequest(p).h
+	query := "SELECT id, IFNULL(id_Facebook, ''), title, slug FROM albums"
+	var args []interface{}
+	id, single := p.Args["id"].(int)
+	if single {
+		query += " WHERE id = ?"
+		args = append(args, id)
+	} else {
+		limit, _ := p.Args["limit"].(int)
+		if limit <= 0 {
+			limit = defaultPageLimit
+		}
+		offset, _ := p.Args["offset"].(int)
+		query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+		args = append(args, min(limit, maxPageLimit), max(offset, 0))
+	}
+
+	rows, err := h.DB.Select(query, args...)
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	defer rows.Close()
+	albums := []Album{}
+	for rows.Next() {
+		var a Album
+		if err := rows.Scan(&a.ID, &a.IDFacebook, &a.Title, &a.Slug); err != nil {
+			return nil, gqlError(err)
+		}
+		albums = append(albums, a)
+	}
+	if single {
+		return first(albums), nil
+	}
+	return albums, nil
+}
+
+func resolveGenres(p graphql.ResolveParams) (interface{}, error) {
+	rows, err := gqlRequest(p).h.DB.Select("SELECT id, name FROM genres ORDER BY name")
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	defer rows.Close()
+	genres := []Genre{}
+	for rows.Next() {
+		var g Genre
+		if err := rows.Scan(&g.ID, &g.Name); err != nil {
+			return nil, gqlError(err)
+		}
+		genres = append(genres, g)
+	}
+	return genres, nil
+}
+
+// graphQLLimits recorre la operación y rechaza las que superan la profundidad o la complejidad
+// máximas, antes de ejecutar ninguna consulta. Los campos de introspección (__schema, __type) no cuentan.
+type graphQLLimits struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+func checkGraphQLLimits(doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	limits := &graphQLLimits{fragments: map[string]*ast.FragmentDefinition{}, variables: variables}
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			limits.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		}
+	}
+	if operation == nil {
+		// La ejecución responde el error correspondiente
+		return nil
+	}
+	if operation.Operation != ast.OperationTypeQuery {
+		return errors.New("El endpoint es de solo lectura: solo se admiten consultas (query)")
+	}
+
+	cost, err := limits.selectionCost(graphQLSchema.QueryType(), operation.SelectionSet, 1, map[string]bool{})
+	if err != nil {
+		return err
+	}
+	if cost > maxGraphQLComplexity {
+		return fmt.Errorf("La consulta es demasiado compleja (%d, máximo %d): pedí menos elementos o menos relaciones", cost, maxGraphQLComplexity)
+	}
+	return nil
+}
+
+// selectionCost devuelve la complejidad de un conjunto de campos de parent
+func (l *graphQLLimits) selectionCost(parent *graphql.Object, set *ast.SelectionSet, depth int, visiting map[string]bool) (int, error) {
+	if set == nil {
+		return 0, nil
+	}
+	total := 0
+	for _, selection := range set.Selections {
+		var cost int
+		var err error
+		switch selection := selection.(type) {
+		case *ast.Field:
+			cost, err = l.fieldCost(parent, selection, depth, visiting)
+		case *ast.InlineFragment:
+			cost, err = l.selectionCost(parent, selection.SelectionSet, depth, visiting)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := l.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			cost, err = l.selectionCost(parent, fragment.SelectionSet, depth, visiting)
+			delete(visiting, name)
+		}
+		if err != nil {
+			return 0, err
+		}
+		total += cost
+	}
+	return total, nil
+}
+
+func (l *graphQLLimits) fieldCost(parent *graphql.Object, field *ast.Field, depth int, visiting map[string]bool) (int, error) {
+	name := field.Name.Value
+	if len(name) > 1 && name[:2] == "__" {
+		return 0, nil
+	}
+	if depth > maxGraphQLDepth {
+		return 0, fmt.Errorf("La consulta es demasiado profunda (máximo %d niveles)", maxGraphQLDepth)
+	}
+	definition, ok := parent.Fields()[name]
+	if !ok {
+		return 1, nil
+	}
+
+	multiplier := 1
+	fieldType := definition.Type
+	if nonNull, ok := fieldType.(*graphql.NonNull); ok {
+		fieldType = nonNull.OfType
+	}
+	if _, isList := fieldType.(*graphql.List); isList {
+		multiplier = graphQLRelationEstimate
+		for _, arg := range definition.Args {
+			if arg.Name() == "limit" {
+				multiplier = defaultPageLimit
+			}
+		}
+		if limit, ok := l.intArgument(field, "limit"); ok {
+			multiplier = min(max(limit, 1), maxPageLimit)
+		}
+	}
+
+	object, ok := graphql.GetNamed(definition.Type).(*graphql.Object)
+	if !ok {
+		return 1, nil
+	}
+	children, err := l.selectionCost(object, field.SelectionSet, depth+1, visiting)
+	if err != nil {
+		return 0, err
+	}
+	return 1 + multiplier*children, nil
+}
+
+// intArgument lee un argumento entero, escrito en la consulta o pasado como variable
+func (l *graphQLLimits) intArgument(field *ast.Field, name string) (int, bool) {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != name {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			n, err := strconv.Atoi(value.Value)
+			return n, err == nil
+		case *ast.Variable:
+			switch v := l.variables[value.Name.Value].(type) {
+			case float64:
+				return int(v), true
+			case int:
+				return v, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// graphQLBody es el pedido GraphQL: por POST en JSON o por GET en la URL
+type graphQLBody struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// writeGraphQLErrors responde errores en el formato de GraphQL ({"errors": [...]}),
+// que es el que esperan los clientes
+func writeGraphQLErrors(w http.ResponseWriter, status int, errs ...gqlerrors.FormattedError) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": errs})
+}
+
+// GraphQL ejecuta consultas de solo lectura sobre el catálogo: bandas, eventos, venues,
+// noticias, canciones, álbumes, videos y géneros, con sus relaciones.
+//
+// @Summary Consulta GraphQL
+// @Description Endpoint GraphQL de solo lectura. Admite introspección. Las consultas tienen un máximo de 6 niveles y de complejidad 2000 (cada lista multiplica a sus campos por su limit)
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param query query string false "Consulta, en pedidos GET"
+// @Param variables query string false "Variables en JSON, en pedidos GET"
+// @Param body body graphQLBody true "Consulta, variables y operationName"
+// @Success 200 {object} map[string]interface{} "data y, si hubo, errors"
+// @Failure 400 {object} map[string]interface{} "Consulta inválida o que supera los límites"
+// @Router /graphql [get]
+// @Router /graphql [post]
+func (h *AuthHandler) GraphQL(w http.ResponseWriter, r *http.Request) {
+	var body graphQLBody
+	if r.Method == http.MethodGet {
+		body.Query = r.URL.Query().Get("query")
+		body.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &body.Variables); err != nil {
+				writeGraphQLErrors(w, http.StatusBadRequest, gqlerrors.NewFormattedError("variables no es un JSON válido"))
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeGraphQLErrors(w, http.StatusBadRequest, gqlerrors.NewFormattedError("El cuerpo tiene que ser JSON con query"))
+		return
+	}
+	if body.Query == "" {
+		writeGraphQLErrors(w, http.StatusBadRequest, gqlerrors.NewFormattedError("Falta query"))
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: body.Query})
+	if err != nil {
+		writeGraphQLErrors(w, http.StatusBadRequest, gqlerrors.FormatError(err))
+		return
+	}
+	if validation := graphql.ValidateDocument(&graphQLSchema, doc, nil); !validation.IsValid {
+		writeGraphQLErrors(w, http.StatusBadRequest, validation.Errors...)
+		return
+	}
+	if err := checkGraphQLLimits(doc, body.OperationName, body.Variables); err != nil {
+		writeGraphQLErrors(w, http.StatusBadRequest, gqlerrors.NewFormattedError(err.Error()))
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), graphQLContextKey{}, &graphQLRequest{h: h, loader: h.newLoader()})
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        graphQLSchema,
+		AST:           doc,
+		OperationName: body.OperationName,
+		Args:          body.Variables,
+		Context:       ctx,
+	})
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Tipos de trabajo en segundo plano. Lo que se publica en servicios externos va a la cola social
+// de a uno; los avisos por WhatsApp, a notifications; las conversiones con ffmpeg, a media;
+// las tareas programadas (scheduled_tasks.go), a maintenance.
+var (
+	// Con un solo intento: si el procesamiento falla a mitad de camino, reintentarlo podría
+	// duplicar contenido. La colaboración queda pendiente para que la revise un moderador.
+	jobAutoApprove = JobType[autoApprovePayload]{Name: "submission.auto_approve", Queue: "submissions", MaxAttempts: 1}
+
+	jobInstagramEvent = JobType[instagramEventPayload]{Name: "instagram.publish_event", Queue: "social", MaxAttempts: 3}
+	jobSocialAnnounce = JobType[socialAnnouncePayload]{Name: "social.announce", Queue: "social", MaxAttempts: 3}
+
+	jobNotifyAuthor = JobType[notifyAuthorPayload]{Name: "whatsapp.notify_author", Queue: "notifications", MaxAttempts: 3}
+	jobNotifyAdmin  = JobType[notifyAdminPayload]{Name: "whatsapp.notify_admin", Queue: "notifications", MaxAttempts: 3}
+
+	jobNotifyArtistLink = JobType[notifyArtistLinkPayload]{Name: "whatsapp.notify_artist_link", Queue: "notifications", MaxAttempts: 3}
+
+	// WhatsApp y email salen en el mismo trabajo: reintentarlo repetiría el aviso que sí llegó
+	jobNotifyVenueClaim       = JobType[notifyVenueClaimPayload]{Name: "venue_claim.notify_admin", Queue: "notifications", MaxAttempts: 1}
+	jobNotifyVenueClaimResult = JobType[notifyVenueClaimResultPayload]{Name: "venue_claim.notify_result", Queue: "notifications", MaxAttempts: 1}
+
+	jobConvertSongAudio = JobType[convertSongAudioPayload]{Name: "song.convert_audio", Queue: "media", MaxAttempts: 3}
+
+	// Las tareas programadas no se reintentan: vuelven a correr en su próximo horario
+	jobScheduledTask = JobType[scheduledTaskPayload]{Name: "scheduled.run", Queue: "maintenance", MaxAttempts: 1}
+)
+
+type autoApprovePayload struct {
+	SubmissionID int `json:"submission_id"`
+	UserID       int `json:"user_id"`
+}
+
+type instagramEventPayload struct {
+	EventID int `json:"event_id"`
+}
+
+// socialAnnouncePayload guarda el contenido ya creado (Band, Event o News según el tipo)
+type socialAnnouncePayload struct {
+	SubmissionID   int             `json:"submission_id"`
+	SubmissionType string          `json:"submission_type"`
+	Data           json.RawMessage `json:"data"`
+	ImagePath      string          `json:"image_path"`
+}
+
+type notifyAuthorPayload struct {
+	SubmissionID int  `json:"submission_id"`
+	Approved     bool `json:"approved"`
+}
+
+type notifyAdminPayload struct {
+	SubmissionID int `json:"submission_id"`
+}
+
+type notifyArtistLinkPayload struct {
+	SubmissionID int    `json:"submission_id"`
+	Rol          string `json:"rol"`
+	ArtistName   string `json:"artist_name"`
+}
+
+type notifyVenueClaimPayload struct {
+	SubmissionID int `json:"submission_id"`
+}
+
+type notifyVenueClaimResultPayload struct {
+	SubmissionID int    `json:"submission_id"`
+	Approved     bool   `json:"approved"`
+	Comment      string `json:"comment"`
+	WhatsApp     bool   `json:"whatsapp"`
+}
+
+type scheduledTaskPayload struct {
+	Task string `json:"task"`
+}
+
+// convertSongAudioPayload apunta al audio subido, guardado en songAudioDir
+type convertSongAudioPayload struct {
+	Slug      string `json:"slug"`
+	InputPath string `json:"input_path"`
+}
+
+// registerJobs asocia cada tipo de trabajo con la función que lo ejecuta
+func (h *AuthHandler) registerJobs() {
+	q := h.jobs
+
+	RegisterJob(q, jobAutoApprove, func(p autoApprovePayload) error {
+		return h.autoApproveSubmission(p.SubmissionID, p.UserID)
+	})
+	RegisterJob(q, jobInstagramEvent, func(p instagramEventPayload) error {
+		return h.PublishEventToInstagramByID(p.EventID)
+	})
+	RegisterJob(q, jobSocialAnnounce, func(p socialAnnouncePayload) error {
+		var data interface{}
+		var err error
+		switch p.SubmissionType {
+		case "band":
+			var band Band
+			err = json.Unmarshal(p.Data, &band)
+			data = band
+		case "event", "eventvenue":
+			var event Event
+			err = json.Unmarshal(p.Data, &event)
+			data = event
+		case "news":
+			var news News
+			err = json.Unmarshal(p.Data, &news)
+			data = news
+		default:
+			return fmt.Errorf("tipo de submission no soportado: %s", p.SubmissionType)
+		}
+		if err != nil {
+			return err
+		}
+		return h.announceSubmission(p.SubmissionID, p.SubmissionType, data, p.ImagePath)
+	})
+	RegisterJob(q, jobNotifyAuthor, func(p notifyAuthorPayload) error {
+		return h.notifySubmissionAuthor(p.SubmissionID, p.Approved)
+	})
+	RegisterJob(q, jobNotifyAdmin, func(p notifyAdminPayload) error {
+		row, err := h.DB.SelectRow("SELECT id, type, data, user_id FROM submissions WHERE id = ?", p.SubmissionID)
+		if err != nil {
+			return err
+		}
+		var s Submission
+		if err := row.Scan(&s.ID, &s.Type, &s.Data, &s.UserID); err == sql.ErrNoRows {
+			return nil // ya se revisó y eliminó
+		} else if err != nil {
+			return err
+		}
+		return h.notifyAdminOfSubmission(s)
+	})
+	RegisterJob(q, jobNotifyArtistLink, func(p notifyArtistLinkPayload) error {
+		return h.notifyArtistLinkApproved(p.SubmissionID, p.Rol, p.ArtistName)
+	})
+	RegisterJob(q, jobNotifyVenueClaim, func(p notifyVenueClaimPayload) error {
+		sub, claim, err := h.venueClaimSubmission(p.SubmissionID)
+		if err == sql.ErrNoRows {
+			return nil // ya se revisó y eliminó
+		} else if err != nil {
+			return err
+		}
+		return h.notifyVenueClaimCreated(sub, claim)
+	})
+	RegisterJob(q, jobNotifyVenueClaimResult, func(p notifyVenueClaimResultPayload) error {
+		sub, claim, err := h.venueClaimSubmission(p.SubmissionID)
+		if err == sql.ErrNoRows {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		return h.notifyVenueClaimResult(sub.UserID, claim, p.Approved, p.Comment, p.WhatsApp)
+	})
+	RegisterJob(q, jobConvertSongAudio, convertSongAudio)
+	RegisterJob(q, jobScheduledTask, h.runScheduledTask)
+}
+
+// enqueue encola un trabajo desde un suscriptor del bus. Si no se puede guardar, lo registra en el log.
+func enqueue[P any](h *AuthHandler, t JobType[P], payload P, delay time.Duration) {
+	if _, err := EnqueueJob(h.jobs, t, payload, delay); err != nil {
+		fmt.Printf("[Warning] No se pudo encolar el trabajo %s: %v\n", t.Name, err)
+	}
+}
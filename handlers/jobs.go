@@ -0,0 +1,610 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"brotecolectivo/database"
+	"brotecolectivo/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	jobPollInterval = 5 * time.Second
+	jobHeartbeat    = time.Minute      // cada cuánto renueva locked_at el worker de un trabajo en curso
+	jobLockTimeout  = 5 * time.Minute  // un trabajo en running sin renovar locked_at se da por perdido
+	jobRetryBase    = 30 * time.Second // 30s, 1m, 2m, 4m... entre intentos
+	jobRetryMax     = time.Hour
+	jobRetention    = 30 * 24 * time.Hour // los trabajos terminados se borran pasado este tiempo
+)
+
+// Colas y cuántos trabajos de cada una corren a la vez en cada instancia de la API
+var jobQueues = []struct {
+	Name        string
+	Concurrency int
+}{
+	{"submissions", 1},
+	{"social", 1}, // las redes limitan las publicaciones seguidas
+	{"notifications", 2},
+	{"media", 1}, // ffmpeg usa toda la CPU que encuentra
+	{"maintenance", 1},
+}
+
+// Job es un trabajo en segundo plano guardado en la tabla jobs.
+//
+// @Schema
+type Job struct {
+	ID          int             `json:"id"`
+	Queue       string          `json:"queue"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"` // pending, running, done o dead
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       string          `json:"run_at"`
+	LockedBy    string          `json:"locked_by,omitempty"`
+	LastError   string          `json:"last_error,omitempty"`
+	CreatedAt   string          `json:"created_at"`
+	FinishedAt  string          `json:"finished_at,omitempty"`
+}
+
+// JobType describe un tipo de trabajo y el payload que recibe. Se registra una vez con
+// RegisterJob y se encola con EnqueueJob, así el payload queda tipado en los dos extremos.
+type JobType[P any] struct {
+	Name        string
+	Queue       string
+	MaxAttempts int
+}
+
+type jobDefinition struct {
+	queue       string
+	maxAttempts int
+	run         func(payload []byte) error
+}
+
+// JobQueue ejecuta los trabajos de la tabla jobs. Varias instancias de la API pueden compartir
+// la tabla: cada trabajo lo toma una sola.
+type JobQueue struct {
+	db          *database.DatabaseStruct
+	workerID    string
+	definitions map[string]jobDefinition
+	wake        map[string]chan struct{}
+}
+
+func NewJobQueue(db *database.DatabaseStruct) *JobQueue {
+	host, _ := os.Hostname()
+	q := &JobQueue{
+		db:          db,
+		workerID:    fmt.Sprintf("%s-%d", host, os.Getpid()),
+		definitions: map[string]jobDefinition{},
+		wake:        map[string]chan struct{}{},
+	}
+	for _, queue := range jobQueues {
+		q.wake[queue.Name] = make(chan struct{}, 1)
+	}
+	return q
+}
+
+// RegisterJob asocia un tipo de trabajo con la función que lo ejecuta. Si la función devuelve
+// un error (o entra en pánico) el trabajo se reintenta.
+func RegisterJob[P any](q *JobQueue, t JobType[P], fn func(P) error) {
+	q.definitions[t.Name] = jobDefinition{
+		queue:       t.Queue,
+		maxAttempts: t.MaxAttempts,
+		run: func(payload []byte) error {
+			var p P
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return fmt.Errorf("payload inválido: %v", err)
+			}
+			return fn(p)
+		},
+	}
+}
+
+// EnqueueJob guarda un trabajo para que corra después de delay (0 para lo antes posible)
+func EnqueueJob[P any](q *JobQueue, t JobType[P], payload P, delay time.Duration) (int, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	maxAttempts := t.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	id, err := q.db.Insert(false, `
+		INSERT INTO jobs (queue, type, payload, max_attempts, run_at)
+		VALUES (?, ?, ?, ?, DATE_ADD(NOW(), INTERVAL ? SECOND))`,
+		t.Queue, t.Name, string(data), maxAttempts, int(delay.Seconds()))
+	if err != nil {
+		return 0, err
+	}
+	if delay <= 0 {
+		q.notify(t.Queue)
+	}
+	return id, nil
+}
+
+func (q *JobQueue) notify(queue string) {
+	if ch, ok := q.wake[queue]; ok {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Start lanza los workers de cada cola y el mantenimiento (trabajos trabados y limpieza)
+func (q *JobQueue) Start() {
+	for _, queue := range jobQueues {
+		for i := 0; i < queue.Concurrency; i++ {
+			go q.work(queue.Name)
+		}
+	}
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			q.maintain()
+			<-ticker.C
+		}
+	}()
+}
+
+func (q *JobQueue) work(queue string) {
+	for {
+		job, err := q.claim(queue)
+		if err != nil {
+			fmt.Printf("[Warning] No se pudo tomar un trabajo de la cola %s: %v\n", queue, err)
+		}
+		if job == nil {
+			select {
+			case <-time.After(jobPollInterval):
+			case <-q.wake[queue]:
+			}
+			continue
+		}
+		q.run(job)
+	}
+}
+
+// claim toma el próximo trabajo pendiente de la cola. Devuelve nil si no hay ninguno.
+func (q *JobQueue) claim(queue string) (*Job, error) {
+	for {
+		row, err := q.db.SelectRow(`
+			SELECT id FROM jobs WHERE queue = ? AND status = 'pending' AND run_at <= NOW()
+			ORDER BY run_at, id LIMIT 1`, queue)
+		if err != nil {
+			return nil, err
+		}
+		var id int
+		if err := row.Scan(&id); err == sql.ErrNoRows {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		// Solo uno de los workers que vieron el mismo trabajo logra pasarlo a running
+		claimed, err := q.db.Update(false, `
+			UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_by = ?, locked_at = NOW()
+			WHERE id = ? AND status = 'pending'`, q.workerID, id)
+		if err != nil {
+			return nil, err
+		}
+		if claimed == 1 {
+			return q.get(id)
+		}
+	}
+}
+
+func (q *JobQueue) get(id int) (*Job, error) {
+	row, err := q.db.SelectRow("SELECT "+jobColumns+" FROM jobs WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	return scanJob(row)
+}
+
+// Columnas de un Job en el orden que espera scanJob
+const jobColumns = `id, queue, type, payload, status, attempts, max_attempts, run_at, IFNULL(locked_by, ''),
+		IFNULL(last_error, ''), created_at, IFNULL(finished_at, '')`
+
+// scanJob lee un trabajo de una fila con jobColumns (sirve para *sql.Row y *sql.Rows)
+func scanJob(row interface{ Scan(...interface{}) error }) (*Job, error) {
+	var j Job
+	var payload []byte
+	if err := row.Scan(&j.ID, &j.Queue, &j.Type, &payload, &j.Status, &j.Attempts, &j.MaxAttempts, &j.RunAt,
+		&j.LockedBy, &j.LastError, &j.CreatedAt, &j.FinishedAt); err != nil {
+		return nil, err
+	}
+	j.Payload = payload
+	return &j, nil
+}
+
+// run ejecuta el trabajo y registra el resultado: done, reintento o dead
+func (q *JobQueue) run(job *Job) {
+	stop := q.heartbeat(job.ID)
+	err := q.execute(job)
+	stop()
+	if err == nil {
+		_, err = q.db.Update(false, `
+			UPDATE jobs SET status = 'done', last_error = NULL, locked_by = NULL, locked_at = NULL, finished_at = NOW()
+			WHERE id = ?`, job.ID)
+		if err != nil {
+			fmt.Printf("[Warning] No se pudo marcar como terminado el trabajo #%d: %v\n", job.ID, err)
+		}
+		return
+	}
+
+	fmt.Printf("[Warning] Falló el trabajo #%d (%s), intento %d de %d: %v\n", job.ID, job.Type, job.Attempts, job.MaxAttempts, err)
+	if job.Attempts >= job.MaxAttempts {
+		_, err = q.db.Update(false, `
+			UPDATE jobs SET status = 'dead', last_error = ?, locked_by = NULL, locked_at = NULL, finished_at = NOW()
+			WHERE id = ?`, err.Error(), job.ID)
+	} else {
+		_, err = q.db.Update(false, `
+			UPDATE jobs SET status = 'pending', last_error = ?, locked_by = NULL, locked_at = NULL,
+			       run_at = DATE_ADD(NOW(), INTERVAL ? SECOND)
+			WHERE id = ?`, err.Error(), int(jobBackoff(job.Attempts).Seconds()), job.ID)
+	}
+	if err != nil {
+		fmt.Printf("[Warning] No se pudo registrar la falla del trabajo #%d: %v\n", job.ID, err)
+	}
+}
+
+// heartbeat renueva locked_at mientras el trabajo corre, así uno largo (ffmpeg, Instagram) no
+// se confunde con uno perdido. Devuelve la función que lo detiene.
+func (q *JobQueue) heartbeat(id int) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(jobHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_, err := q.db.Update(false, "UPDATE jobs SET locked_at = NOW() WHERE id = ? AND status = 'running' AND locked_by = ?",
+					id, q.workerID)
+				if err != nil {
+					fmt.Printf("[Warning] No se pudo renovar el trabajo #%d: %v\n", id, err)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func (q *JobQueue) execute(job *Job) (err error) {
+	def, ok := q.definitions[job.Type]
+	if !ok {
+		// Sin reintentos: otra versión de la API puede conocerlo, pero esta no
+		job.Attempts = job.MaxAttempts
+		return fmt.Errorf("tipo de trabajo desconocido: %s", job.Type)
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			fmt.Printf("[Warning] El trabajo #%d (%s) entró en pánico: %v\n%s\n", job.ID, job.Type, rec, debug.Stack())
+			err = fmt.Errorf("pánico: %v", rec)
+		}
+	}()
+	return def.run(job.Payload)
+}
+
+func jobBackoff(attempts int) time.Duration {
+	delay := jobRetryBase << (attempts - 1)
+	if delay <= 0 || delay > jobRetryMax {
+		return jobRetryMax
+	}
+	return delay
+}
+
+// maintain recupera los trabajos que quedaron en running porque la instancia que los tomó se
+// cayó: vuelven a pending si les quedan intentos y pasan a dead si no (un trabajo que tira abajo
+// el proceso no se reintenta para siempre). También borra los terminados viejos; los muertos se
+// conservan para revisarlos.
+func (q *JobQueue) maintain() {
+	lockTimeout := int(jobLockTimeout.Seconds())
+	dead, err := q.db.Update(false, `
+		UPDATE jobs SET status = 'dead', locked_by = NULL, locked_at = NULL, finished_at = NOW(),
+		       last_error = 'Se interrumpió mientras corría y no le quedan intentos'
+		WHERE status = 'running' AND locked_at < DATE_SUB(NOW(), INTERVAL ? SECOND) AND attempts >= max_attempts`, lockTimeout)
+	if err != nil {
+		fmt.Printf("[Warning] No se pudieron recuperar los trabajos trabados: %v\n", err)
+		return
+	}
+	recovered, err := q.db.Update(false, `
+		UPDATE jobs SET status = 'pending', locked_by = NULL, locked_at = NULL, last_error = 'Se interrumpió mientras corría'
+		WHERE status = 'running' AND locked_at < DATE_SUB(NOW(), INTERVAL ? SECOND)`, lockTimeout)
+	if err != nil {
+		fmt.Printf("[Warning] No se pudieron recuperar los trabajos trabados: %v\n", err)
+		return
+	}
+	if dead+recovered > 0 {
+		fmt.Printf("[Info] Trabajos interrumpidos: %d vuelven a la cola, %d sin intentos pasan a dead\n", recovered, dead)
+	}
+	_, _ = q.db.Delete(false, `DELETE FROM jobs WHERE status = 'done' AND finished_at < DATE_SUB(NOW(), INTERVAL ? SECOND)`,
+		int(jobRetention.Seconds()))
+}
+
+// StartJobWorkers inicia la ejecución de la cola de trabajos
+func (h *AuthHandler) StartJobWorkers() {
+	h.jobs.Start()
+}
+
+// GetJobs lista los trabajos en segundo plano, del más reciente al más antiguo (solo administradores).
+//
+// @Summary Listar trabajos
+// @Tags trabajos
+// @Produce json
+// @Param status query string false "Filtrar por estado" Enums(pending, running, done, dead)
+// @Param queue query string false "Filtrar por cola"
+// @Param type query string false "Filtrar por tipo de trabajo"
+// @Param limit query int false "Cantidad máxima (por defecto 50, máximo 200)"
+// @Success 200 {array} Job "Trabajos"
+// @Failure 400 {string} string "Filtro inválido"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /jobs [get]
+func (h *AuthHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	query := "SELECT " + jobColumns + " FROM jobs WHERE 1 = 1"
+	var args []interface{}
+	if status := r.URL.Query().Get("status"); status != "" {
+		if status != "pending" && status != "running" && status != "done" && status != "dead" {
+			writeError(w, r, http.StatusBadRequest, "", fieldError("status", "invalid", "status debe ser pending, running, done o dead"))
+			return
+		}
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	if queue := r.URL.Query().Get("queue"); queue != "" {
+		query += " AND queue = ?"
+		args = append(args, queue)
+	}
+	if jobType := r.URL.Query().Get("type"); jobType != "" {
+		query += " AND type = ?"
+		args = append(args, jobType)
+	}
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > 200 {
+			writeError(w, r, http.StatusBadRequest, "", fieldError("limit", "range", "limit debe estar entre 1 y 200"))
+			return
+		}
+		limit = n
+	}
+	query += " ORDER BY id DESC LIMIT " + strconv.Itoa(limit)
+
+	rows, err := h.DB.Select(query, args...)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener los trabajos", err)
+		return
+	}
+	defer rows.Close()
+
+	jobs := []Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "Error al leer los trabajos", err)
+			return
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al leer los trabajos", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// JobQueueStats es el estado de una cola
+type JobQueueStats struct {
+	Queue       string         `json:"queue"`
+	Concurrency int            `json:"concurrency"` // trabajos a la vez por instancia
+	Counts      map[string]int `json:"counts"`      // cantidad por estado
+}
+
+// GetJobStats devuelve cuántos trabajos hay en cada estado por cola (solo administradores).
+//
+// @Summary Estado de las colas
+// @Tags trabajos
+// @Produce json
+// @Success 200 {array} JobQueueStats "Colas con la cantidad de trabajos por estado"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /jobs/stats [get]
+func (h *AuthHandler) GetJobStats(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	rows, err := h.DB.Select(`SELECT queue, status, COUNT(*) FROM jobs GROUP BY queue, status`)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener el estado de las colas", err)
+		return
+	}
+	defer rows.Close()
+
+	stats := []JobQueueStats{}
+	index := map[string]int{}
+	for _, queue := range jobQueues {
+		index[queue.Name] = len(stats)
+		stats = append(stats, JobQueueStats{
+			Queue:       queue.Name,
+			Concurrency: queue.Concurrency,
+			Counts:      map[string]int{"pending": 0, "running": 0, "done": 0, "dead": 0},
+		})
+	}
+	for rows.Next() {
+		var queue, status string
+		var count int
+		if rows.Scan(&queue, &status, &count) != nil {
+			continue
+		}
+		// Una cola que ya no existe en esta versión igual se muestra, para poder limpiarla
+		i, ok := index[queue]
+		if !ok {
+			i = len(stats)
+			index[queue] = i
+			stats = append(stats, JobQueueStats{Queue: queue, Counts: map[string]int{}})
+		}
+		stats[i].Counts[status] = count
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetJob devuelve un trabajo con su payload y el último error (solo administradores).
+//
+// @Summary Obtener trabajo
+// @Tags trabajos
+// @Produce json
+// @Param id path int true "ID del trabajo"
+// @Success 200 {object} Job "Trabajo"
+// @Failure 400 {string} string "ID inválido"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Trabajo no encontrado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /jobs/{id} [get]
+func (h *AuthHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	job, err := h.jobs.get(id)
+	if err == sql.ErrNoRows {
+		writeError(w, r, http.StatusNotFound, "Trabajo no encontrado")
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener el trabajo", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// RetryJob vuelve a encolar un trabajo muerto (o adelanta uno pendiente) con los intentos en cero.
+//
+// @Summary Reintentar trabajo
+// @Description Un trabajo dead vuelve a pending con todos sus intentos; uno pending se ejecuta en el momento
+// @Tags trabajos
+// @Produce json
+// @Param id path int true "ID del trabajo"
+// @Success 200 {object} Job "Trabajo encolado"
+// @Failure 400 {string} string "ID inválido"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Trabajo no encontrado"
+// @Failure 409 {string} string "El trabajo está corriendo o ya terminó"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /jobs/{id}/retry [post]
+func (h *AuthHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	job, err := h.jobs.get(id)
+	if err == sql.ErrNoRows {
+		writeError(w, r, http.StatusNotFound, "Trabajo no encontrado")
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener el trabajo", err)
+		return
+	}
+	updated, err := h.DB.Update(false, `
+		UPDATE jobs SET status = 'pending', attempts = 0, run_at = NOW(), finished_at = NULL
+		WHERE id = ? AND status IN ('pending', 'dead')`, id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al reintentar el trabajo", err)
+		return
+	}
+	if updated == 0 {
+		writeError(w, r, http.StatusConflict, "El trabajo está corriendo o ya terminó")
+		return
+	}
+	h.jobs.notify(job.Queue)
+
+	job, err = h.jobs.get(id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// CancelJob elimina un trabajo pendiente o muerto (solo administradores).
+//
+// @Summary Cancelar trabajo
+// @Tags trabajos
+// @Param id path int true "ID del trabajo"
+// @Success 204 "Trabajo eliminado"
+// @Failure 400 {string} string "ID inválido"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Trabajo no encontrado"
+// @Failure 409 {string} string "El trabajo está corriendo"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /jobs/{id} [delete]
+func (h *AuthHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	job, err := h.jobs.get(id)
+	if err == sql.ErrNoRows {
+		writeError(w, r, http.StatusNotFound, "Trabajo no encontrado")
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener el trabajo", err)
+		return
+	}
+	if job.Status == "running" {
+		writeError(w, r, http.StatusConflict, "El trabajo está corriendo")
+		return
+	}
+	if _, err := h.DB.Delete(false, "DELETE FROM jobs WHERE id = ? AND status <> 'running'", id); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al cancelar el trabajo", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Operadores de filtro de los listados: ?title=x usa el operador por defecto del campo,
+// ?title[eq]=x elige uno explícito.
+const (
+	filterEq    = "eq"    // igual
+	filterLike  = "like"  // contiene
+	filterRange = "range" // desde,hasta (cualquiera de los dos puede faltar)
+	filterIn    = "in"    // lista separada por comas
+)
+
+var filterOperators = map[string]bool{filterEq: true, filterLike: true, filterRange: true, filterIn: true}
+
+// Límites para que un pedido no arme consultas enormes
+const (
+	maxFilterValues = 100
+	maxSortColumns  = 3
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// column valida y cita un identificador de un listado ("e.date_start" → `e`.`date_start`).
+// Los identificadores salen solo de las declaraciones de cada listado, nunca del pedido;
+// uno inválido es un error de programación y se detecta al iniciar.
+func column(name string) string {
+	if !identifierPattern.MatchString(name) {
+		panic("identificador inválido en un listado: " + name)
+	}
+	return "`" + strings.ReplaceAll(name, ".", "`.`") + "`"
+}
+
+// columns cita varios identificadores
+func columns(names ...string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = column(name)
+	}
+	return quoted
+}
+
+// likeEscaper escapa los comodines de LIKE para que el texto del usuario se busque literal
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func likePattern(text string) string {
+	return "%" + likeEscaper.Replace(text) + "%"
+}
+
+// filterField es un campo filtrable: su columna y los operadores que acepta (el primero es el por defecto)
+type filterField struct {
+	column    string
+	operators []string
+}
+
+// textFilter busca por coincidencia parcial; también acepta eq e in
+func textFilter(name string) filterField {
+	return filterField{column(name), []string{filterLike, filterEq, filterIn}}
+}
+
+// numberFilter también busca por coincidencia parcial por defecto, como lo hacían las tablas del panel
+func numberFilter(name string) filterField {
+	return filterField{column(name), []string{filterLike, filterEq, filterIn, filterRange}}
+}
+
+// dateFilter acepta coincidencia parcial ("2024-05") y rangos ("2024-05-01,2024-05-31")
+func dateFilter(name string) filterField {
+	return filterField{column(name), []string{filterLike, filterRange, filterEq}}
+}
+
+// enumFilter es para valores exactos: IDs de relaciones, estados, tipos
+func enumFilter(name string) filterField {
+	return filterField{column(name), []string{filterEq, filterIn}}
+}
+
+// listSpec declara un listado: de dónde sale, por qué columnas se busca, filtra y ordena.
+// Con eso se arman la consulta paginada, la de la tabla del panel y la de /count.
+type listSpec struct {
+	from        string
+	id          string
+	search      []string               // columnas del parámetro q
+	filters     map[string]filterField // parámetro → campo
+	sorts       map[string]string      // nombre público → columna
+	defaultSort string                 // mismo formato que ?sort=, ej. "date_start:desc"
+
+	// apply agrega los filtros que no son una columna (ej. free en eventos)
+	apply func(r *http.Request, q *listQuery) error
+}
+
+// query arma el listado con los filtros y el orden del pedido
+func (s *listSpec) query(r *http.Request, selectColumns string) (*listQuery, error) {
+	q := newListQuery(selectColumns, s.from, s.id)
+
+	if text := r.URL.Query().Get("q"); text != "" && len(s.search) > 0 {
+		parts := make([]string, len(s.search))
+		for i, col := range s.search {
+			parts[i] = col + " LIKE ?"
+			q.args = append(q.args, likePattern(text))
+		}
+		q.conditions = append(q.conditions, "("+strings.Join(parts, " OR ")+")")
+	}
+
+	if err := s.applyFilters(r, q); err != nil {
+		return nil, err
+	}
+	if s.apply != nil {
+		if err := s.apply(r, q); err != nil {
+			return nil, err
+		}
+	}
+
+	orders, err := s.parseSort(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) > 0 {
+		q.orders = orders
+	}
+	return q, nil
+}
+
+// applyFilters agrega una condición por cada parámetro declarado como filtro
+func (s *listSpec) applyFilters(r *http.Request, q *listQuery) error {
+	values := r.URL.Query()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name, operator := key, ""
+		if open := strings.Index(key, "["); open > 0 && strings.HasSuffix(key, "]") {
+			name, operator = key[:open], key[open+1:len(key)-1]
+		}
+
+		field, ok := s.filters[name]
+		if !ok {
+			// Otros parámetros (limit, q, etc.) se ignoran, pero un filtro explícito desconocido es un error
+			if filterOperators[operator] {
+				return fieldError(name, "invalid", "No se puede filtrar por "+name)
+			}
+			continue
+		}
+		if operator == "" {
+			operator = field.operators[0]
+		}
+		if !slices.Contains(field.operators, operator) {
+			return fieldError(name, "invalid", fmt.Sprintf("El filtro %s no admite el operador %s", name, operator))
+		}
+
+		value := strings.TrimSpace(values.Get(key))
+		if value == "" {
+			continue
+		}
+
+		switch operator {
+		case filterEq:
+			q.where(field.column+" = ?", value)
+		case filterLike:
+			q.where(field.column+" LIKE ?", likePattern(value))
+		case filterIn:
+			var items []interface{}
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			if len(items) == 0 {
+				continue
+			}
+			if len(items) > maxFilterValues {
+				return fieldError(name, "range", fmt.Sprintf("El filtro %s admite hasta %d valores", name, maxFilterValues))
+			}
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(items)), ", ")
+			q.where(field.column+" IN ("+placeholders+")", items...)
+		case filterRange:
+			from, to, found := strings.Cut(value, ",")
+			if !found {
+				return fieldError(name, "invalid", "El rango de "+name+" tiene que tener el formato desde,hasta")
+			}
+			if from = strings.TrimSpace(from); from != "" {
+				q.where(field.column+" >= ?", from)
+			}
+			if to = strings.TrimSpace(to); to != "" {
+				q.where(field.column+" <= ?", to)
+			}
+		}
+	}
+	return nil
+}
+
+// parseSort lee ?sort=campo,-campo o campo:desc (hasta maxSortColumns columnas).
+// Por compatibilidad, ?sort=campo&order=desc sigue funcionando con una sola columna.
+func (s *listSpec) parseSort(r *http.Request) ([]sortTerm, error) {
+	value := r.URL.Query().Get("sort")
+	order := r.URL.Query().Get("order")
+	if value == "" {
+		value, order = s.defaultSort, ""
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) > maxSortColumns {
+		return nil, fieldError("sort", "range", fmt.Sprintf("Se puede ordenar por hasta %d columnas", maxSortColumns))
+	}
+
+	terms := make([]sortTerm, 0, len(parts))
+	for _, part := range parts {
+		name := strings.TrimSpace(part)
+		desc := len(parts) == 1 && order == "desc"
+		if strings.HasPrefix(name, "-") {
+			name, desc = name[1:], true
+		} else if field, direction, found := strings.Cut(name, ":"); found {
+			if direction != "asc" && direction != "desc" {
+				return nil, fieldError("sort", "invalid", "Dirección de orden inválida: "+direction)
+			}
+			name, desc = field, direction == "desc"
+		}
+
+		col, ok := s.sorts[name]
+		if !ok {
+			return nil, fieldError("sort", "invalid", "No se puede ordenar por "+name)
+		}
+		terms = append(terms, sortTerm{name: name, column: col, desc: desc})
+	}
+	return terms, nil
+}
+
+// writeCount responde {"count": n} para los endpoints /count, con los mismos filtros que el listado
+func (h *AuthHandler) writeCount(w http.ResponseWriter, r *http.Request, spec *listSpec) {
+	q, err := spec.query(r, spec.id)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+	count, err := h.countList(q)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al contar", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"count": count})
+}
@@ -0,0 +1,331 @@
+package handlers
+
+import "sync"
+
+// batch carga un tipo de relación por lotes: recibe todos los IDs de una vez, consulta solo
+// los que todavía no tiene con un único IN (...) y guarda el resultado para el resto del pedido.
+// Los IDs sin filas quedan guardados con el valor vacío para no volver a consultarlos.
+type batch[V any] struct {
+	mu      sync.Mutex
+	loaded  map[int]V
+	pending []int
+	fetch   func(ids []int) (map[int]V, error)
+}
+
+func newBatch[V any](fetch func(ids []int) (map[int]V, error)) *batch[V] {
+	return &batch[V]{loaded: map[int]V{}, fetch: fetch}
+}
+
+// queue anota IDs que probablemente se pidan después (ej. los hermanos de una lista en GraphQL,
+// donde cada elemento resuelve su relación por separado): el próximo load los trae en la misma consulta.
+func (b *batch[V]) queue(ids ...int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, ids...)
+}
+
+// load devuelve la relación de cada ID pedido
+func (b *batch[V]) load(ids []int) (map[int]V, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missing []int
+	for _, id := range append(b.pending, ids...) {
+		if _, ok := b.loaded[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	b.pending = nil
+	if len(missing) > 0 {
+		fetched, err := b.fetch(missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range missing {
+			b.loaded[id] = fetched[id]
+		}
+	}
+
+	result := make(map[int]V, len(ids))
+	for _, id := range ids {
+		result[id] = b.loaded[id]
+	}
+	return result, nil
+}
+
+// loader reúne las relaciones que se cargan por lotes durante un pedido. Se crea uno por pedido
+// (REST o GraphQL) para que lo ya consultado no se repita y no quede cacheado entre pedidos.
+// Cada lote cargado anota sus elementos en los lotes de sus propias relaciones (ver queue),
+// así un nivel más de anidamiento en GraphQL sigue siendo una consulta por tipo.
+type loader struct {
+	eventBands *batch[[]Band]
+	newsBands  *batch[[]Band]
+	videoBands *batch[[]*Band]
+	bandGenres *batch[[]Genre]
+	bands      *batch[*Band]
+	venues     *batch[*Venue]
+	series     *batch[*Series]
+
+	bandEvents  *batch[[]Event]
+	venueEvents *batch[[]Event]
+	bandNews    *batch[[]News]
+	bandVideos  *batch[[]Video]
+	bandSongs   *batch[[]Song]
+}
+
+func (h *AuthHandler) newLoader() *loader {
+	l := &loader{
+		bandGenres: newBatch(h.genresByBand),
+		series:     newBatch(h.seriesByID),
+	}
+	l.eventBands = newBatch(func(ids []int) (map[int][]Band, error) {
+		result, err := h.bandsByEvent(ids)
+		for _, bands := range result {
+			for _, b := range bands {
+				l.queueBands(b.ID)
+			}
+		}
+		return result, err
+	})
+	l.newsBands = newBatch(func(ids []int) (map[int][]Band, error) {
+		result, err := h.bandsByNews(ids)
+		for _, bands := range result {
+			for _, b := range bands {
+				l.queueBands(b.ID)
+			}
+		}
+		return result, err
+	})
+	l.videoBands = newBatch(func(ids []int) (map[int][]*Band, error) {
+		result, err := h.bandsByVideo(ids)
+		for _, bands := range result {
+			for _, b := range bands {
+				l.queueBands(b.ID)
+			}
+		}
+		return result, err
+	})
+	l.bands = newBatch(func(ids []int) (map[int]*Band, error) {
+		result, err := h.bandsByID(ids)
+		for id := range result {
+			l.queueBands(id)
+		}
+		return result, err
+	})
+	l.venues = newBatch(func(ids []int) (map[int]*Venue, error) {
+		result, err := h.venuesByID(ids)
+		for id := range result {
+			l.venueEvents.queue(id)
+		}
+		return result, err
+	})
+	l.bandEvents = newBatch(func(ids []int) (map[int][]Event, error) {
+		result, err := h.eventsByBand(ids)
+		for _, events := range result {
+			l.queueEvents(events)
+		}
+		return result, err
+	})
+	l.venueEvents = newBatch(func(ids []int) (map[int][]Event, error) {
+		result, err := h.eventsByVenue(ids)
+		for _, events := range result {
+			l.queueEvents(events)
+		}
+		return result, err
+	})
+	l.bandNews = newBatch(func(ids []int) (map[int][]News, error) {
+		result, err := h.newsByBand(ids)
+		for _, news := range result {
+			l.queueNews(news)
+		}
+		return result, err
+	})
+	l.bandVideos = newBatch(func(ids []int) (map[int][]Video, error) {
+		result, err := h.videosByBand(ids)
+		for _, videos := range result {
+			l.queueVideos(videos)
+		}
+		return result, err
+	})
+	l.bandSongs = newBatch(h.songsByBand)
+	return l
+}
+
+// queueBands anota bandas para que sus relaciones se carguen juntas cuando se pida la primera
+func (l *loader) queueBands(ids ...int) {
+	l.bandGenres.queue(ids...)
+	l.bandEvents.queue(ids...)
+	l.bandNews.queue(ids...)
+	l.bandVideos.queue(ids...)
+	l.bandSongs.queue(ids...)
+}
+
+// queueEvents anota eventos (y sus venues y series) para cargar sus relaciones juntas
+func (l *loader) queueEvents(events []Event) {
+	for _, e := range events {
+		l.eventBands.queue(e.ID)
+		l.venues.queue(e.VenueID)
+		if e.SeriesID > 0 {
+			l.series.queue(e.SeriesID)
+		}
+	}
+}
+
+// queueVenues anota venues para cargar sus eventos juntos
+func (l *loader) queueVenues(venues []Venue) {
+	for _, v := range venues {
+		l.venueEvents.queue(v.ID)
+	}
+}
+
+// queueNews anota noticias para cargar sus bandas juntas
+func (l *loader) queueNews(news []News) {
+	for _, n := range news {
+		l.newsBands.queue(n.ID)
+	}
+}
+
+// queueVideos anota videos para cargar sus bandas juntas
+func (l *loader) queueVideos(videos []Video) {
+	for _, v := range videos {
+		l.videoBands.queue(v.ID)
+	}
+}
+
+// eventRelations carga las relaciones pedidas para una lista de eventos.
+// venue sin include trae solo id y nombre (del JOIN del listado); con include trae el venue completo.
+func (l *loader) eventRelations(events []Event, fs *fieldSet) error {
+	if len(events) == 0 {
+		return nil
+	}
+	ids := make([]int, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+
+	if fs.include("bands") || fs.include("genres") {
+		bands, err := l.eventBands.load(ids)
+		if err != nil {
+			return err
+		}
+		for i := range events {
+			events[i].Bands = bands[events[i].ID]
+		}
+	}
+
+	if fs.include("genres") {
+		var bandIDs []int
+		for _, e := range events {
+			for _, b := range e.Bands {
+				bandIDs = append(bandIDs, b.ID)
+			}
+		}
+		genres, err := l.bandGenres.load(bandIDs)
+		if err != nil {
+			return err
+		}
+		for i := range events {
+			seen := map[int]bool{}
+			events[i].Genres = []Genre{}
+			for _, b := range events[i].Bands {
+				for _, g := range genres[b.ID] {
+					if !seen[g.ID] {
+						seen[g.ID] = true
+						events[i].Genres = append(events[i].Genres, g)
+					}
+				}
+			}
+		}
+	}
+
+	if fs.include("venue") {
+		venueIDs := make([]int, 0, len(events))
+		for _, e := range events {
+			if e.Venue != nil {
+				venueIDs = append(venueIDs, e.Venue.ID)
+			}
+		}
+		venues, err := l.venues.load(venueIDs)
+		if err != nil {
+			return err
+		}
+		for i := range events {
+			if events[i].Venue == nil {
+				continue
+			}
+			if v := venues[events[i].Venue.ID]; v != nil {
+				full := *v
+				full.Distance = events[i].Venue.Distance
+				events[i].Venue = &full
+			}
+		}
+	}
+
+	if fs.include("series") {
+		var seriesIDs []int
+		for _, e := range events {
+			if e.SeriesID > 0 {
+				seriesIDs = append(seriesIDs, e.SeriesID)
+			}
+		}
+		series, err := l.series.load(seriesIDs)
+		if err != nil {
+			return err
+		}
+		for i := range events {
+			events[i].Series = series[events[i].SeriesID]
+		}
+	}
+	return nil
+}
+
+// bandGenresFor completa los géneros de una lista de bandas
+func (l *loader) bandGenresFor(bands []Band) error {
+	ids := make([]int, len(bands))
+	for i, b := range bands {
+		ids[i] = b.ID
+	}
+	genres, err := l.bandGenres.load(ids)
+	if err != nil {
+		return err
+	}
+	for i := range bands {
+		bands[i].Genres = genres[bands[i].ID]
+	}
+	return nil
+}
+
+// newsBandsFor completa las bandas de una lista de noticias (vacía en lugar de null)
+func (l *loader) newsBandsFor(newsList []News) error {
+	ids := make([]int, len(newsList))
+	for i, n := range newsList {
+		ids[i] = n.ID
+	}
+	bands, err := l.newsBands.load(ids)
+	if err != nil {
+		return err
+	}
+	for i := range newsList {
+		newsList[i].Bands = bands[newsList[i].ID]
+		if newsList[i].Bands == nil {
+			newsList[i].Bands = []Band{}
+		}
+	}
+	return nil
+}
+
+// videoBandsFor completa las bandas de una lista de videos
+func (l *loader) videoBandsFor(videos []Video) error {
+	ids := make([]int, len(videos))
+	for i, v := range videos {
+		ids[i] = v.ID
+	}
+	bands, err := l.videoBands.load(ids)
+	if err != nil {
+		return err
+	}
+	for i := range videos {
+		videos[i].Bands = bands[videos[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"brotecolectivo/database"
+)
+
+// countingDriver es una base falsa que cuenta las consultas y responde dos bandas por evento
+// a las consultas sobre events_bands (la de a uno y la de IN (...)). El resto no devuelve filas.
+type countingDriver struct{ queries atomic.Int64 }
+
+func (d *countingDriver) Open(string) (driver.Conn, error) { return &countingConn{d}, nil }
+
+type countingConn struct{ d *countingDriver }
+
+func (c *countingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("no se usan sentencias preparadas")
+}
+func (c *countingConn) Close() error              { return nil }
+func (c *countingConn) Begin() (driver.Tx, error) { return nil, fmt.Errorf("sin transacciones") }
+
+func (c *countingConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.queries.Add(1)
+	rows := &fakeRows{}
+	switch {
+	case strings.Contains(query, "eb.id_event IN"):
+		rows.columns = []string{"id_event", "id", "name", "slug"}
+		for _, arg := range args {
+			id := arg.Value.(int64)
+			for b := int64(1); b <= 2; b++ {
+				rows.values = append(rows.values, []driver.Value{id, id*10 + b, "Banda", "banda"})
+			}
+		}
+	case strings.Contains(query, "eb.id_event = ?"):
+		rows.columns = []string{"id", "name", "slug"}
+		id := args[0].Value.(int64)
+		for b := int64(1); b <= 2; b++ {
+			rows.values = append(rows.values, []driver.Value{id*10 + b, "Banda", "banda"})
+		}
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+var benchDriver = &countingDriver{}
+
+func init() {
+	sql.Register("counting", benchDriver)
+}
+
+func benchHandler(b *testing.B) *AuthHandler {
+	db, err := sql.Open("counting", "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+	return &AuthHandler{DB: database.Wrap(db)}
+}
+
+func benchEvents(n int) []Event {
+	events := make([]Event, n)
+	for i := range events {
+		events[i].ID = i + 1
+	}
+	return events
+}
+
+// embedEventBandsPerRow es como se cargaban las bandas antes del loader: una consulta por evento
+func embedEventBandsPerRow(h *AuthHandler, events []Event) error {
+	for i := range events {
+		rows, err := h.DB.Select(`
+			SELECT b.id, b.name, b.slug
+			FROM bands b
+			JOIN events_bands eb ON b.id = eb.id_band
+			WHERE eb.id_event = ?
+		`, events[i].ID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var band Band
+			if err := rows.Scan(&band.ID, &band.Name, &band.Slug); err == nil {
+				events[i].Bands = append(events[i].Bands, band)
+			}
+		}
+		rows.Close()
+	}
+	return nil
+}
+
+// Una página de 50 eventos con sus bandas: go test -bench EventBands -run ^$ ./handlers
+// reporta las consultas por página de cada forma.
+
+func BenchmarkEventBandsPerRow(b *testing.B) {
+	h := benchHandler(b)
+	start := benchDriver.queries.Load()
+	for i := 0; i < b.N; i++ {
+		if err := embedEventBandsPerRow(h, benchEvents(50)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(benchDriver.queries.Load()-start)/float64(b.N), "queries/page")
+}
+
+func BenchmarkEventBandsLoader(b *testing.B) {
+	h := benchHandler(b)
+	fs := &fieldSet{includes: map[string]bool{"bands": true}}
+	start := benchDriver.queries.Load()
+	for i := 0; i < b.N; i++ {
+		events := benchEvents(50)
+		if err := h.newLoader().eventRelations(events, fs); err != nil {
+			b.Fatal(err)
+		}
+		if len(events[49].Bands) != 2 {
+			b.Fatalf("el evento 50 tiene %d bandas, se esperaban 2", len(events[49].Bands))
+		}
+	}
+	b.ReportMetric(float64(benchDriver.queries.Load()-start)/float64(b.N), "queries/page")
+}
@@ -0,0 +1,622 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"brotecolectivo/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-sql-driver/mysql"
+)
+
+// mergeReference es una columna de otra tabla que apunta a la entidad fusionada
+type mergeReference struct {
+	Table  string // tabla que referencia a la entidad
+	Column string // columna con el ID de la entidad
+	Key    string // columna que identifica la fila junto con Column
+	Unique bool   // tabla de relación: (Key, Column) no puede repetirse
+}
+
+// mergeEntity describe una entidad que se puede fusionar y todas sus referencias
+type mergeEntity struct {
+	Table      string
+	References []mergeReference
+}
+
+// Entidades fusionables. Los nombres de tabla y columna son fijos: nunca vienen del cliente.
+var mergeEntities = map[string]mergeEntity{
+	"band": {
+		Table: "bands",
+		References: []mergeReference{
+			{Table: "events_bands", Column: "id_band", Key: "id_event", Unique: true},
+			{Table: "news_bands", Column: "id_band", Key: "id_news", Unique: true},
+			{Table: "videos_bands", Column: "id_band", Key: "id_video", Unique: true},
+			{Table: "songs", Column: "id_band", Key: "id"},
+			{Table: "artist_links", Column: "artist_id", Key: "user_id", Unique: true},
+		},
+	},
+	"venue": {
+		Table: "venues",
+		References: []mergeReference{
+			{Table: "events", Column: "id_venue", Key: "id"},
+			{Table: "venue_links", Column: "venue_id", Key: "user_id", Unique: true},
+		},
+	},
+}
+
+var (
+	errMergeNotFound   = errors.New("registro no encontrado")
+	errMergeUndone     = errors.New("la fusión ya fue deshecha")
+	errMergeTargetGone = errors.New("el registro de destino ya no existe")
+	errMergeConflict   = errors.New("un dato único del registro a restaurar ya está en uso")
+)
+
+// mysqlDuplicateEntry es el código de MySQL para una clave única repetida
+const mysqlDuplicateEntry = 1062
+
+// restoreError envuelve un error al restaurar filas; las claves repetidas pasan a errMergeConflict
+func restoreError(what string, err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return fmt.Errorf("%s: %w: %v", what, errMergeConflict, err)
+	}
+	return fmt.Errorf("%s: %w", what, err)
+}
+
+// MergeOperation es el registro de una fusión, con lo necesario para deshacerla.
+//
+// @Schema
+type MergeOperation struct {
+	ID         int            `json:"id"`
+	EntityType string         `json:"entity_type"`
+	SourceID   int            `json:"source_id"`
+	TargetID   int            `json:"target_id"`
+	SourceSlug string         `json:"source_slug"`
+	TargetSlug string         `json:"target_slug"`
+	Moved      map[string]int `json:"moved"` // filas reasignadas por tabla
+	CreatedBy  int            `json:"created_by"`
+	CreatedAt  string         `json:"created_at"`
+	UndoneAt   string         `json:"undone_at,omitempty"`
+}
+
+// rowSnapshot es una fila completa; los valores NULL quedan en nil
+type rowSnapshot map[string][]byte
+
+// mergeUndoData se guarda como JSON en merge_operations.undo_data
+type mergeUndoData struct {
+	Source       rowSnapshot              `json:"source"`
+	TargetSocial []byte                   `json:"target_social"`
+	Moved        map[string][]int         `json:"moved"`   // tabla -> claves reasignadas al destino
+	Dropped      map[string][]rowSnapshot `json:"dropped"` // filas borradas por estar repetidas en el destino
+	Redirects    []int                    `json:"redirects"`
+	RedirectID   int                      `json:"redirect_id"`
+}
+
+// selectSnapshots lee filas completas de forma genérica
+func selectSnapshots(tx *sql.Tx, query string, args ...interface{}) ([]rowSnapshot, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []rowSnapshot
+	for rows.Next() {
+		values := make([]sql.RawBytes, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		snapshot := rowSnapshot{}
+		for i, column := range columns {
+			if values[i] == nil {
+				snapshot[column] = nil
+				continue
+			}
+			snapshot[column] = append([]byte{}, values[i]...)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+// snapshotValue convierte un valor guardado en un parámetro para el INSERT.
+// Los textos van como string para que MySQL los acepte en columnas JSON.
+func snapshotValue(value []byte) interface{} {
+	if value == nil {
+		return nil
+	}
+	if utf8.Valid(value) {
+		return string(value)
+	}
+	return value
+}
+
+// insertSnapshot vuelve a insertar una fila leída con selectSnapshots
+func insertSnapshot(tx *sql.Tx, table string, row rowSnapshot) error {
+	columns := make([]string, 0, len(row))
+	placeholders := make([]string, 0, len(row))
+	values := make([]interface{}, 0, len(row))
+	for column, value := range row {
+		columns = append(columns, "`"+strings.ReplaceAll(column, "`", "")+"`")
+		placeholders = append(placeholders, "?")
+		values = append(values, snapshotValue(value))
+	}
+	_, err := tx.Exec(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		table, strings.Join(columns, ", "), strings.Join(placeholders, ", ")), values...)
+	return err
+}
+
+// intPlaceholders arma "?, ?, ?" y los argumentos para un IN
+func intPlaceholders(ids []int) (string, []interface{}) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+// selectInts devuelve la primera columna de cada fila como entero
+func selectInts(tx *sql.Tx, query string, args ...interface{}) ([]int, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// mergeSocial une las redes de ambos registros; ante la misma plataforma gana el destino
+func mergeSocial(source, target []byte) string {
+	merged := map[string]string{}
+	sourceSocial := map[string]string{}
+	targetSocial := map[string]string{}
+	if len(source) > 0 {
+		json.Unmarshal(source, &sourceSocial)
+	}
+	if len(target) > 0 {
+		json.Unmarshal(target, &targetSocial)
+	}
+	for platform, link := range sourceSocial {
+		merged[platform] = link
+	}
+	for platform, link := range targetSocial {
+		if link != "" {
+			merged[platform] = link
+		}
+	}
+	data, _ := json.Marshal(merged)
+	return string(data)
+}
+
+// mergeRecords fusiona sourceID dentro de targetID en una sola transacción
+func (h *AuthHandler) mergeRecords(entityType string, sourceID, targetID, userID int) (*MergeOperation, error) {
+	entity, ok := mergeEntities[entityType]
+	if !ok {
+		return nil, fmt.Errorf("tipo de entidad no soportado: %s", entityType)
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	sources, err := selectSnapshots(tx, "SELECT * FROM "+entity.Table+" WHERE id = ? FOR UPDATE", sourceID)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := selectSnapshots(tx, "SELECT * FROM "+entity.Table+" WHERE id = ? FOR UPDATE", targetID)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 || len(targets) == 0 {
+		return nil, errMergeNotFound
+	}
+	source, target := sources[0], targets[0]
+
+	undo := mergeUndoData{
+		Source:       source,
+		TargetSocial: target["social"],
+		Moved:        map[string][]int{},
+		Dropped:      map[string][]rowSnapshot{},
+		Redirects:    []int{},
+	}
+	op := &MergeOperation{
+		EntityType: entityType,
+		SourceID:   sourceID,
+		TargetID:   targetID,
+		SourceSlug: string(source["slug"]),
+		TargetSlug: string(target["slug"]),
+		Moved:      map[string]int{},
+		CreatedBy:  userID,
+	}
+
+	for _, ref := range entity.References {
+		if ref.Unique {
+			// Si el destino ya tiene la misma relación, la fila del origen se borra en lugar de moverse
+			dropped, err := selectSnapshots(tx, fmt.Sprintf(
+				"SELECT * FROM %s WHERE %s = ? AND %s IN (SELECT %s FROM (SELECT %s FROM %s WHERE %s = ?) AS t)",
+				ref.Table, ref.Column, ref.Key, ref.Key, ref.Key, ref.Table, ref.Column), sourceID, targetID)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", ref.Table, err)
+			}
+			if len(dropped) > 0 {
+				keys := make([]int, 0, len(dropped))
+				for _, row := range dropped {
+					key, _ := strconv.Atoi(string(row[ref.Key]))
+					keys = append(keys, key)
+				}
+				in, args := intPlaceholders(keys)
+				if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = ? AND %s IN (%s)",
+					ref.Table, ref.Column, ref.Key, in), append([]interface{}{sourceID}, args...)...); err != nil {
+					return nil, fmt.Errorf("%s: %w", ref.Table, err)
+				}
+				undo.Dropped[ref.Table] = dropped
+			}
+		}
+
+		moved, err := selectInts(tx, fmt.Sprintf("SELECT %s FROM %s WHERE %s = ?", ref.Key, ref.Table, ref.Column), sourceID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ref.Table, err)
+		}
+		if len(moved) > 0 {
+			if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", ref.Table, ref.Column, ref.Column),
+				targetID, sourceID); err != nil {
+				return nil, fmt.Errorf("%s: %w", ref.Table, err)
+			}
+		}
+		undo.Moved[ref.Table] = moved
+		op.Moved[ref.Table] = len(moved)
+	}
+
+	if _, err := tx.Exec("UPDATE "+entity.Table+" SET social = ? WHERE id = ?",
+		mergeSocial(source["social"], target["social"]), targetID); err != nil {
+		return nil, err
+	}
+
+	// Las redirecciones que apuntaban al origen pasan al destino, y el slug del origen redirige al destino
+	undo.Redirects, err = selectInts(tx, "SELECT id FROM slug_history WHERE entity_type = ? AND entity_id = ?", entityType, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("UPDATE slug_history SET entity_id = ? WHERE entity_type = ? AND entity_id = ?",
+		targetID, entityType, sourceID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM "+entity.Table+" WHERE id = ?", sourceID); err != nil {
+		return nil, err
+	}
+
+	if op.SourceSlug != "" && op.SourceSlug != op.TargetSlug {
+		result, err := tx.Exec(`INSERT INTO slug_history (entity_type, entity_id, old_slug, created_at) VALUES (?, ?, ?, NOW())`,
+			entityType, targetID, op.SourceSlug)
+		if err != nil {
+			return nil, err
+		}
+		redirectID, _ := result.LastInsertId()
+		undo.RedirectID = int(redirectID)
+	}
+
+	undoJSON, err := json.Marshal(undo)
+	if err != nil {
+		return nil, err
+	}
+	movedJSON, _ := json.Marshal(op.Moved)
+	result, err := tx.Exec(`
+		INSERT INTO merge_operations (entity_type, source_id, target_id, source_slug, target_slug, moved, undo_data, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW())`,
+		entityType, sourceID, targetID, op.SourceSlug, op.TargetSlug, string(movedJSON), string(undoJSON), userID)
+	if err != nil {
+		return nil, err
+	}
+	operationID, _ := result.LastInsertId()
+	op.ID = int(operationID)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	h.reindexMergedSearch(entityType, sourceID, targetID)
+	return op, nil
+}
+
+// reindexMergedSearch actualiza el índice de búsqueda de los dos registros de una fusión
+// y del contenido que los nombra. El que ya no existe se quita del índice.
+func (h *AuthHandler) reindexMergedSearch(entityType string, ids ...int) {
+	for _, id := range ids {
+		switch entityType {
+		case "band":
+			h.reindexBandSearch(id)
+		case "venue":
+			h.reindexSearch("venue", id)
+			h.reindexSearchQuery("event", "SELECT id FROM events WHERE id_venue = ?", id)
+		}
+	}
+}
+
+// undoMerge restaura el registro de origen y devuelve cada referencia a su lugar
+func (h *AuthHandler) undoMerge(operationID, userID int) error {
+	tx, err := h.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var entityType string
+	var sourceID, targetID int
+	var undoJSON []byte
+	var undoneAt sql.NullString
+	err = tx.QueryRow(`
+		SELECT entity_type, source_id, target_id, undo_data, undone_at
+		FROM merge_operations WHERE id = ? FOR UPDATE`, operationID).
+		Scan(&entityType, &sourceID, &targetID, &undoJSON, &undoneAt)
+	if err == sql.ErrNoRows {
+		return errMergeNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if undoneAt.Valid {
+		return errMergeUndone
+	}
+
+	entity, ok := mergeEntities[entityType]
+	if !ok {
+		return fmt.Errorf("tipo de entidad no soportado: %s", entityType)
+	}
+
+	var undo mergeUndoData
+	if err := json.Unmarshal(undoJSON, &undo); err != nil {
+		return fmt.Errorf("datos de la fusión inválidos: %w", err)
+	}
+
+	var targetExists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM "+entity.Table+" WHERE id = ?)", targetID).Scan(&targetExists); err != nil {
+		return err
+	}
+	if !targetExists {
+		return errMergeTargetGone
+	}
+
+	if undo.RedirectID > 0 {
+		if _, err := tx.Exec("DELETE FROM slug_history WHERE id = ?", undo.RedirectID); err != nil {
+			return err
+		}
+	}
+
+	if err := insertSnapshot(tx, entity.Table, undo.Source); err != nil {
+		return restoreError("no se pudo restaurar el registro de origen", err)
+	}
+
+	for _, ref := range entity.References {
+		if keys := undo.Moved[ref.Table]; len(keys) > 0 {
+			in, args := intPlaceholders(keys)
+			if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ? AND %s IN (%s)",
+				ref.Table, ref.Column, ref.Column, ref.Key, in),
+				append([]interface{}{sourceID, targetID}, args...)...); err != nil {
+				return restoreError(ref.Table, err)
+			}
+		}
+		for _, row := range undo.Dropped[ref.Table] {
+			if err := insertSnapshot(tx, ref.Table, row); err != nil {
+				return restoreError(ref.Table, err)
+			}
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE "+entity.Table+" SET social = ? WHERE id = ?",
+		snapshotValue(undo.TargetSocial), targetID); err != nil {
+		return err
+	}
+
+	if len(undo.Redirects) > 0 {
+		in, args := intPlaceholders(undo.Redirects)
+		if _, err := tx.Exec("UPDATE slug_history SET entity_id = ? WHERE id IN ("+in+")",
+			append([]interface{}{sourceID}, args...)...); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE merge_operations SET undone_at = NOW(), undone_by = ? WHERE id = ?",
+		userID, operationID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	h.reindexMergedSearch(entityType, sourceID, targetID)
+	return nil
+}
+
+// CreateMerge fusiona un registro duplicado dentro de otro (solo administradores).
+//
+// @Summary Fusionar duplicados
+// @Description Reasigna todas las referencias del registro de origen al de destino, une las redes sociales, deja una redirección del slug viejo y elimina el origen. La operación queda registrada y se puede deshacer.
+// @Tags merges
+// @Accept json
+// @Produce json
+// @Param merge body object true "entity_type (band|venue), source_id y target_id"
+// @Success 201 {object} MergeOperation "Fusión realizada"
+// @Failure 400 {string} string "Datos inválidos"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Registro no encontrado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /merges [post]
+func (h *AuthHandler) CreateMerge(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+
+	var input struct {
+		EntityType string `json:"entity_type"`
+		SourceID   int    `json:"source_id"`
+		TargetID   int    `json:"target_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar")
+		return
+	}
+	if _, ok := mergeEntities[input.EntityType]; !ok {
+		writeError(w, r, http.StatusBadRequest, "entity_type debe ser band o venue")
+		return
+	}
+	if input.SourceID <= 0 || input.TargetID <= 0 || input.SourceID == input.TargetID {
+		writeError(w, r, http.StatusBadRequest, "source_id y target_id deben ser distintos y válidos")
+		return
+	}
+
+	op, err := h.mergeRecords(input.EntityType, input.SourceID, input.TargetID, int(claims.UserID))
+	if errors.Is(err, errMergeNotFound) {
+		writeError(w, r, http.StatusNotFound, "Registro de origen o destino no encontrado")
+		return
+	}
+	if err != nil {
+		fmt.Printf("[Error] No se pudo fusionar %s #%d en #%d: %v\n", input.EntityType, input.SourceID, input.TargetID, err)
+		writeError(w, r, http.StatusInternalServerError, "Error al fusionar", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(op)
+}
+
+// GetMerges lista las fusiones realizadas, de la más reciente a la más antigua.
+//
+// @Summary Listar fusiones
+// @Description Devuelve el historial de fusiones (solo administradores)
+// @Tags merges
+// @Produce json
+// @Param entity_type query string false "Filtrar por tipo (band|venue)"
+// @Success 200 {array} MergeOperation "Historial de fusiones"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /merges [get]
+func (h *AuthHandler) GetMerges(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+
+	query := `
+		SELECT id, entity_type, source_id, target_id, source_slug, target_slug, moved, created_by, created_at, undone_at
+		FROM merge_operations WHERE 1=1`
+	var queryParams []interface{}
+	if entityType := r.URL.Query().Get("entity_type"); entityType != "" {
+		query += " AND entity_type = ?"
+		queryParams = append(queryParams, entityType)
+	}
+	query += " ORDER BY id DESC LIMIT 200"
+
+	rows, err := h.DB.Select(query, queryParams...)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener fusiones")
+		return
+	}
+	defer rows.Close()
+
+	operations := []MergeOperation{}
+	for rows.Next() {
+		var op MergeOperation
+		var moved []byte
+		var undoneAt sql.NullString
+		if err := rows.Scan(&op.ID, &op.EntityType, &op.SourceID, &op.TargetID, &op.SourceSlug, &op.TargetSlug,
+			&moved, &op.CreatedBy, &op.CreatedAt, &undoneAt); err != nil {
+			continue
+		}
+		op.Moved = map[string]int{}
+		json.Unmarshal(moved, &op.Moved)
+		op.UndoneAt = undoneAt.String
+		operations = append(operations, op)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(operations)
+}
+
+// UndoMerge deshace una fusión: restaura el registro de origen y sus referencias.
+//
+// @Summary Deshacer fusión
+// @Description Restaura el registro eliminado, devuelve sus referencias, el mapa de redes anterior del destino y quita la redirección de slug
+// @Tags merges
+// @Produce json
+// @Param id path int true "ID de la fusión"
+// @Success 200 {object} map[string]string "Fusión deshecha"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Fusión no encontrada"
+// @Failure 409 {string} string "La fusión ya fue deshecha o no se puede restaurar"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /merges/{id}/undo [post]
+func (h *AuthHandler) UndoMerge(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+
+	operationID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	err = h.undoMerge(operationID, int(claims.UserID))
+	if errors.Is(err, errMergeNotFound) {
+		writeError(w, r, http.StatusNotFound, "Fusión no encontrada")
+		return
+	}
+	// Los conflictos conocidos se responden con un mensaje fijo; el detalle de la base solo va al log
+	switch {
+	case errors.Is(err, errMergeUndone):
+		writeError(w, r, http.StatusConflict, "La fusión ya fue deshecha")
+		return
+	case errors.Is(err, errMergeTargetGone):
+		writeError(w, r, http.StatusConflict, "El registro de destino ya no existe")
+		return
+	case errors.Is(err, errMergeConflict):
+		// El registro pudo cambiar desde la fusión (ej: el slug viejo ya está en uso)
+		fmt.Printf("[Error] No se pudo deshacer la fusión %d: %v\n", operationID, err)
+		writeError(w, r, http.StatusConflict, "No se puede restaurar: el slug u otro dato único ya está en uso")
+		return
+	case err != nil:
+		writeError(w, r, http.StatusInternalServerError, "No se pudo deshacer la fusión", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": "Fusión deshecha"})
+}
@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -32,148 +31,106 @@ type News struct {
 // GetNewsCount devuelve el número total de noticias en la base de datos.
 //
 // @Summary Obtener conteo de noticias
-// @Description Devuelve el número total de noticias registradas en la plataforma
+// @Description Devuelve el número de noticias que cumplen los mismos filtros que /news/table (q, id, title, date)
 // @Tags noticias
 // @Produce json
 // @Success 200 {object} map[string]int "Conteo exitoso"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /news/count [get]
 func (h *AuthHandler) GetNewsCount(w http.ResponseWriter, r *http.Request) {
-	search := r.URL.Query().Get("q")
-	idFilter := r.URL.Query().Get("id")
-	titleFilter := r.URL.Query().Get("title")
-	dateFilter := r.URL.Query().Get("date")
+	h.writeCount(w, r, newsListSpec)
+}
 
-	query := `SELECT COUNT(*) FROM news WHERE 1=1`
-	var queryParams []interface{}
+// newsListSpec declara los filtros y órdenes del listado de noticias, por defecto de la más nueva
+// a la más vieja. Lo comparten el listado, la tabla del panel y el conteo.
+var newsListSpec = &listSpec{
+	from:   "news n",
+	id:     column("n.id"),
+	search: columns("n.title", "n.content", "n.slug"),
+	filters: map[string]filterField{
+		"id":    numberFilter("n.id"),
+		"slug":  textFilter("n.slug"),
+		"title": textFilter("n.title"),
+		"date":  dateFilter("n.date"),
+	},
+	sorts: map[string]string{"id": column("n.id"), "title": column("n.title"), "date": column("n.date")},
+}
 
-	if search != "" {
-		pattern := "%" + search + "%"
-		query += ` AND (title LIKE ? OR content LIKE ? OR slug LIKE ?)`
-		queryParams = append(queryParams, pattern, pattern, pattern)
-	}
-	if idFilter != "" {
-		query += " AND id LIKE ?"
-		queryParams = append(queryParams, "%"+idFilter+"%")
-	}
-	if titleFilter != "" {
-		query += " AND title LIKE ?"
-		queryParams = append(queryParams, "%"+titleFilter+"%")
-	}
-	if dateFilter != "" {
-		query += " AND date LIKE ?"
-		queryParams = append(queryParams, "%"+dateFilter+"%")
-	}
+// Campos y relaciones de los listados de noticias. El listado público trae las bandas por defecto.
+var newsFieldSet = fieldSetSpec{
+	fields:    []string{"id", "slug", "date", "title", "content"},
+	relations: []string{"bands"},
+	defaults:  []string{"bands"},
+}
 
-	row, err := h.DB.SelectRow(query, queryParams...)
+// queryNewsPage lee una página de noticias, sin las bandas
+func (h *AuthHandler) queryNewsPage(r *http.Request, p *pageRequest, fs *fieldSet) ([]News, *pageInfo, error) {
+	q, err := newsListSpec.query(r, "n.id, n.slug, n.date, n.title, "+fs.pick("content", "n.content"))
 	if err != nil {
-		http.Error(w, "Error al contar noticias", http.StatusInternalServerError)
-		return
-	}
-
-	var count int
-	if err := row.Scan(&count); err != nil {
-		http.Error(w, "Error al leer el conteo", http.StatusInternalServerError)
-		return
+		return nil, nil, err
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]int{"count": count})
+	var newsList []News
+	page, err := h.queryPage(q, p, func(scan func(dest ...interface{}) error) error {
+		var n News
+		if err := scan(&n.ID, &n.Slug, &n.Date, &n.Title, &n.Content); err != nil {
+			return err
+		}
+		newsList = append(newsList, n)
+		return nil
+	})
+	return newsList, page, err
 }
 
 // GetNewsDatatable devuelve los datos de noticias en formato para DataTables.
 //
 // @Summary Obtener noticias para DataTables
-// @Description Devuelve los datos de noticias formateados para su uso con DataTables
+// @Description Devuelve noticias paginadas para la tabla del panel, con búsqueda, filtros por columna y ordenamiento
 // @Tags noticias
 // @Produce json
-// @Param draw query int false "Parámetro draw de DataTables"
-// @Param start query int false "Índice de inicio para paginación"
-// @Param length query int false "Número de registros a mostrar"
-// @Param search[value] query string false "Término de búsqueda"
-// @Param order[0][column] query int false "Índice de la columna para ordenar"
-// @Param order[0][dir] query string false "Dirección de ordenamiento (asc/desc)"
-// @Success 200 {object} DatatableResponse "Datos para DataTables"
+// @Param limit query int false "Cantidad de registros (por defecto 10, máximo 100)"
+// @Param offset query int false "Desplazamiento para paginación"
+// @Param cursor query string false "Cursor de la página siguiente (X-Next-Cursor)"
+// @Param total query bool false "Incluir el total en X-Total-Count"
+// @Param q query string false "Término de búsqueda"
+// @Param sort query string false "Orden: hasta 3 columnas separadas por coma, con - o :desc para descendente (id, title, date)"
+// @Param order query string false "Dirección cuando sort tiene una sola columna (asc/desc)"
+// @Param fields query string false "Campos a devolver, separados por coma (id, slug, date, title, content, bands)"
+// @Param include query string false "Relaciones a incluir: bands"
+// @Success 200 {array} News "Lista de noticias"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /news/table [get]
 func (h *AuthHandler) GetNewsDatatable(w http.ResponseWriter, r *http.Request) {
-	offsetParam := r.URL.Query().Get("offset")
-	limitParam := r.URL.Query().Get("limit")
-	search := r.URL.Query().Get("q")
-	sortBy := r.URL.Query().Get("sort")
-	order := r.URL.Query().Get("order")
-
-	idFilter := r.URL.Query().Get("id")
-	titleFilter := r.URL.Query().Get("title")
-	dateFilter := r.URL.Query().Get("date")
-
-	offset := 0
-	limit := 10
-	var err error
-
-	if offsetParam != "" {
-		offset, _ = strconv.Atoi(offsetParam)
-	}
-	if limitParam != "" {
-		limit, _ = strconv.Atoi(limitParam)
-	}
-
-	query := `
-		SELECT n.id, n.slug, n.date, n.title, n.content
-		FROM news n
-		WHERE 1=1
-	`
-	var queryParams []interface{}
-
-	if search != "" {
-		pattern := "%" + search + "%"
-		query += ` AND (n.title LIKE ? OR n.content LIKE ? OR n.slug LIKE ?)`
-		queryParams = append(queryParams, pattern, pattern, pattern)
-	}
-	if idFilter != "" {
-		query += " AND n.id LIKE ?"
-		queryParams = append(queryParams, "%"+idFilter+"%")
-	}
-	if titleFilter != "" {
-		query += " AND n.title LIKE ?"
-		queryParams = append(queryParams, "%"+titleFilter+"%")
-	}
-	if dateFilter != "" {
-		query += " AND n.date LIKE ?"
-		queryParams = append(queryParams, "%"+dateFilter+"%")
+	p, err := parsePageRequest(r, 10)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
 	}
 
-	if sortBy != "" {
-		validSorts := map[string]bool{"id": true, "title": true, "date": true}
-		if validSorts[sortBy] {
-			if order != "desc" {
-				order = "asc"
-			}
-			query += fmt.Sprintf(" ORDER BY n.%s %s", sortBy, strings.ToUpper(order))
-		}
+	// La tabla del panel no trae las bandas salvo que se pidan con include
+	spec := newsFieldSet
+	spec.defaults = nil
+	fs, err := spec.parse(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
 	}
 
-	query += " LIMIT ? OFFSET ?"
-	queryParams = append(queryParams, limit, offset)
-
-	rows, err := h.DB.Select(query, queryParams...)
+	newsList, page, err := h.queryNewsPage(r, p, fs)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeListError(w, r, err)
 		return
 	}
-	defer rows.Close()
-
-	var newsList []News
-	for rows.Next() {
-		var n News
-		if err := rows.Scan(&n.ID, &n.Slug, &n.Date, &n.Title, &n.Content); err != nil {
-			continue
+	if fs.include("bands") {
+		if err := h.newLoader().newsBandsFor(newsList); err != nil {
+			writeError(w, r, http.StatusInternalServerError, "", err)
+			return
 		}
-		newsList = append(newsList, n)
 	}
 
+	writePageHeaders(w, r, page)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(newsList)
+	fs.encode(w, newsList)
 }
 
 // UploadNewsImage maneja la subida de imágenes para noticias.
@@ -185,10 +142,8 @@ func (h *AuthHandler) GetNewsDatatable(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param file formData file true "Archivo de imagen a subir"
 // @Param slug formData string true "Slug de la noticia"
-// @Security BearerAuth
 // @Success 200 {object} map[string]string "URL de la imagen subida"
 // @Failure 400 {string} string "Error en la solicitud"
-// @Failure 401 {string} string "No autorizado"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /news/upload-image [post]
 func (h *AuthHandler) UploadNewsImage(w http.ResponseWriter, r *http.Request) {
@@ -196,21 +151,21 @@ func (h *AuthHandler) UploadNewsImage(w http.ResponseWriter, r *http.Request) {
 
 	file, handler, err := r.FormFile("file")
 	if err != nil {
-		http.Error(w, "No se pudo leer el archivo", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "No se pudo leer el archivo")
 		return
 	}
 	defer file.Close()
 
 	slug := r.FormValue("slug")
 	if slug == "" {
-		http.Error(w, "Slug faltante", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Slug faltante")
 		return
 	}
 
 	// Crear archivo temporal
 	tempFile, err := os.CreateTemp("", "upload-*.jpg")
 	if err != nil {
-		http.Error(w, "No se pudo crear archivo temporal", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se pudo crear archivo temporal")
 		return
 	}
 	defer os.Remove(tempFile.Name())
@@ -219,7 +174,7 @@ func (h *AuthHandler) UploadNewsImage(w http.ResponseWriter, r *http.Request) {
 	// Subir a Spaces o tu servicio de almacenamiento
 	err = uploadToSpaces(tempFile.Name(), "news/"+slug+".jpg", handler.Header.Get("Content-Type"))
 	if err != nil {
-		http.Error(w, "Error al subir imagen: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al subir imagen", err)
 		return
 	}
 
@@ -235,87 +190,45 @@ func (h *AuthHandler) UploadNewsImage(w http.ResponseWriter, r *http.Request) {
 // @Description Obtiene una lista de noticias con opciones de filtrado y paginación
 // @Tags noticias
 // @Produce json
+// @Param limit query int false "Límite de registros por página (por defecto 10, máximo 100)"
 // @Param page query int false "Número de página para paginación"
-// @Param limit query int false "Límite de registros por página"
-// @Param search query string false "Término de búsqueda"
+// @Param offset query int false "Desplazamiento (alternativa a page)"
+// @Param cursor query string false "Cursor de la página siguiente (X-Next-Cursor)"
+// @Param total query bool false "Incluir el total en X-Total-Count"
+// @Param q query string false "Término de búsqueda"
+// @Param fields query string false "Campos a devolver, separados por coma (id, slug, date, title, content, bands)"
+// @Param include query string false "Relaciones a incluir: bands"
 // @Success 200 {array} News "Lista de noticias"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /news [get]
 func (h *AuthHandler) GetNews(w http.ResponseWriter, r *http.Request) {
-	pageStr := r.URL.Query().Get("page")
-	limit := 10 // cantidad de noticias por página
-	page := 1
-
-	if pageStr != "" {
-		p, err := strconv.Atoi(pageStr)
-		if err == nil && p > 0 {
-			page = p
-		}
+	p, err := parsePageRequest(r, 10)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
 	}
-	offset := (page - 1) * limit
-
-	rows, err := h.DB.Select(`
-	SELECT n.id, n.slug, n.date, n.title, n.content, b.id, b.name, b.slug
-	FROM (
-		SELECT * FROM news
-		ORDER BY id DESC
-		LIMIT ? OFFSET ?
-	) AS n
-	LEFT JOIN news_bands nb ON n.id = nb.id_news
-	LEFT JOIN bands b ON nb.id_band = b.id
-`, limit, offset)
 
+	fs, err := newsFieldSet.parse(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
-	defer rows.Close()
-	var allNews []News
-	newsMap := make(map[int]*News)
-
-	for rows.Next() {
-		var nid int
-		var slug, title, content, date string
-		var bID sql.NullInt64
-		var bName, bSlug sql.NullString
-
-		err := rows.Scan(&nid, &slug, &date, &title, &content, &bID, &bName, &bSlug)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		if _, ok := newsMap[nid]; !ok {
-			n := News{
-				ID:      nid,
-				Slug:    slug,
-				Date:    date,
-				Title:   title,
-				Content: content,
-				Bands:   []Band{},
-			}
-			newsMap[nid] = &n
-			allNews = append(allNews, n) // guardás la referencia al orden
-		}
 
-		if bID.Valid {
-			newsMap[nid].Bands = append(newsMap[nid].Bands, Band{
-				ID:   int(bID.Int64),
-				Name: bName.String,
-				Slug: bSlug.String,
-			})
-		}
+	allNews, page, err := h.queryNewsPage(r, p, fs)
+	if err != nil {
+		writeListError(w, r, err)
+		return
 	}
-
-	// como `allNews` tiene copias de los structs, y los actualizás en `newsMap`, tenés que reconstruir con referencias
-	for i, n := range allNews {
-		if updated, ok := newsMap[n.ID]; ok {
-			allNews[i] = *updated
+	if fs.include("bands") {
+		if err := h.newLoader().newsBandsFor(allNews); err != nil {
+			writeError(w, r, http.StatusInternalServerError, "", err)
+			return
 		}
 	}
 
-	json.NewEncoder(w).Encode(allNews)
-
+	writePageHeaders(w, r, page)
+	w.Header().Set("Content-Type", "application/json")
+	fs.encode(w, allNews)
 }
 
 // CreateNews crea una nueva noticia en la base de datos.
@@ -326,33 +239,39 @@ func (h *AuthHandler) GetNews(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param news body News true "Datos de la noticia a crear"
-// @Security BearerAuth
 // @Success 201 {object} News "Noticia creada exitosamente"
 // @Failure 400 {string} string "Error en la solicitud"
-// @Failure 401 {string} string "No autorizado"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /news [post]
 func (h *AuthHandler) CreateNews(w http.ResponseWriter, r *http.Request) {
 	var n News
 	if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
 
 	// Convertir string "YYYY-MM-DD" a timestamp UNIX
 	t, err := time.Parse("2006-01-02", n.Date)
 	if err != nil {
-		http.Error(w, "Formato de fecha inválido. Usá YYYY-MM-DD", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Formato de fecha inválido. Usá YYYY-MM-DD")
 		return
 	}
 	timestamp := t.Unix()
 
+	slug, err := h.assignSlug("news", n.Slug, n.Title, strconv.Itoa(t.Year()))
+	if err != nil {
+		writeSlugError(w, r, err)
+		return
+	}
+	n.Slug = slug
+	defer h.releaseSlug("news", slug)
+
 	lastID, err := h.DB.Insert(true, `
 		INSERT INTO news (slug, title, content, date) VALUES (?, ?, ?, ?)`,
 		n.Slug, n.Title, n.Content, timestamp,
 	)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	n.ID = int(lastID)
@@ -364,10 +283,11 @@ func (h *AuthHandler) CreateNews(w http.ResponseWriter, r *http.Request) {
 			n.ID, bandID,
 		)
 		if err != nil {
-			http.Error(w, "Error al vincular bandas", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al vincular bandas")
 			return
 		}
 	}
+	h.bus.Publish(NewsPublished{Actor: actorFrom(r), News: n, Origin: OriginAPI})
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(n)
@@ -398,7 +318,7 @@ func (h *AuthHandler) GetNewsByBandID(w http.ResponseWriter, r *http.Request) {
 	`, bandID)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	defer rows.Close()
@@ -413,7 +333,7 @@ func (h *AuthHandler) GetNewsByBandID(w http.ResponseWriter, r *http.Request) {
 
 		err := rows.Scan(&nid, &date, &slug, &title, &content, &bID, &bName, &bSlug)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "", err)
 			return
 		}
 
@@ -483,7 +403,7 @@ func (h *AuthHandler) GetNewsByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	defer rows.Close()
@@ -497,7 +417,7 @@ func (h *AuthHandler) GetNewsByID(w http.ResponseWriter, r *http.Request) {
 
 		err := rows.Scan(&nid, &slug, &date, &title, &content, &bID, &bName, &bSlug)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "", err)
 			return
 		}
 
@@ -523,7 +443,10 @@ func (h *AuthHandler) GetNewsByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if n == nil {
-		http.Error(w, "Noticia no encontrada", http.StatusNotFound)
+		if h.redirectOldSlug(w, r, "news", idOrSlug) {
+			return
+		}
+		writeError(w, r, http.StatusNotFound, "Noticia no encontrada")
 		return
 	}
 
@@ -539,10 +462,8 @@ func (h *AuthHandler) GetNewsByID(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path int true "ID de la noticia a actualizar"
 // @Param news body News true "Datos actualizados de la noticia"
-// @Security BearerAuth
 // @Success 200 {object} News "Noticia actualizada exitosamente"
 // @Failure 400 {string} string "Error en la solicitud"
-// @Failure 401 {string} string "No autorizado"
 // @Failure 404 {string} string "Noticia no encontrada"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /news/{id} [put]
@@ -550,9 +471,18 @@ func (h *AuthHandler) UpdateNews(w http.ResponseWriter, r *http.Request) {
 	idOrSlug := chi.URLParam(r, "id")
 	var n News
 	if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+
+	prevSlug := h.currentSlug("news", idOrSlug)
+	slug, err := h.updateSlug("news", prevSlug, n.Slug, n.Title, strconv.Itoa(time.Now().Year()))
+	if err != nil {
+		writeSlugError(w, r, err)
 		return
 	}
+	n.Slug = slug
+	defer h.releaseSlug("news", slug)
 
 	query := "UPDATE news SET slug = ?, title = ?, content = ? WHERE "
 	var args []interface{}
@@ -566,25 +496,25 @@ func (h *AuthHandler) UpdateNews(w http.ResponseWriter, r *http.Request) {
 		args = append(args, idOrSlug)
 	}
 
-	_, err := h.DB.Update(true, query, args...)
+	_, err = h.DB.Update(true, query, args...)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
+	h.recordSlugChange("news", prevSlug, n.Slug)
+	h.reindexSearch("news", prevSlug.ID)
 
 	// Obtener el ID numérico para usarlo en la tabla intermedia
-	var newsID int
+	// (si se buscó por slug, el slug ya pudo haber cambiado)
+	newsID := prevSlug.ID
 	if isNumeric(idOrSlug) {
 		newsID, _ = strconv.Atoi(idOrSlug)
-	} else {
-		row, _ := h.DB.SelectRow("SELECT id FROM news WHERE slug = ?", idOrSlug)
-		_ = row.Scan(&newsID)
 	}
 
 	// Eliminar bandas anteriores
 	_, err = h.DB.Delete(false, "DELETE FROM news_bands WHERE id_news = ?", newsID)
 	if err != nil {
-		http.Error(w, "Error al limpiar relaciones previas", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al limpiar relaciones previas")
 		return
 	}
 
@@ -595,7 +525,7 @@ func (h *AuthHandler) UpdateNews(w http.ResponseWriter, r *http.Request) {
 			newsID, bandID,
 		)
 		if err != nil {
-			http.Error(w, "Error al vincular bandas", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al vincular bandas")
 			return
 		}
 	}
@@ -610,10 +540,8 @@ func (h *AuthHandler) UpdateNews(w http.ResponseWriter, r *http.Request) {
 // @Tags noticias
 // @Produce json
 // @Param id path int true "ID de la noticia a eliminar"
-// @Security BearerAuth
 // @Success 200 {object} map[string]string "Mensaje de éxito"
 // @Failure 400 {string} string "Error en la solicitud"
-// @Failure 401 {string} string "No autorizado"
 // @Failure 404 {string} string "Noticia no encontrada"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /news/{id} [delete]
@@ -626,26 +554,28 @@ func (h *AuthHandler) DeleteNews(w http.ResponseWriter, r *http.Request) {
 	} else {
 		row, err := h.DB.SelectRow("SELECT id FROM news WHERE slug = ?", idOrSlug)
 		if err != nil {
-			http.Error(w, "Error al buscar noticia", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al buscar noticia")
 			return
 		}
 		if err := row.Scan(&id); err != nil {
-			http.Error(w, "Noticia no encontrada", http.StatusNotFound)
+			writeError(w, r, http.StatusNotFound, "Noticia no encontrada")
 			return
 		}
 	}
 
 	_, err := h.DB.Delete(false, "DELETE FROM news_bands WHERE id_news = ?", id)
 	if err != nil {
-		http.Error(w, "Error al eliminar relaciones", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al eliminar relaciones")
 		return
 	}
 
 	_, err = h.DB.Delete(true, "DELETE FROM news WHERE id = ?", id)
 	if err != nil {
-		http.Error(w, "Error al eliminar noticia", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al eliminar noticia")
 		return
 	}
+	newsID, _ := strconv.Atoi(id)
+	h.removeFromSearch("news", newsID)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -668,16 +598,15 @@ type GenerateNewsContentResponse struct {
 // @Accept json
 // @Produce json
 // @Param request body GenerateNewsContentRequest true "Datos para generar el contenido"
-// @Security BearerAuth
 // @Success 200 {object} GenerateNewsContentResponse
 // @Failure 400 {string} string "Error en la solicitud"
-// @Failure 401 {string} string "No autorizado"
 // @Failure 500 {string} string "Error interno del servidor"
 // @Router /news/generate-content [post]
+// @Router /submissions/generate-content [post]
 func (h *AuthHandler) GenerateNewsContent(w http.ResponseWriter, r *http.Request) {
 	var req GenerateNewsContentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
 
@@ -713,13 +642,13 @@ func (h *AuthHandler) GenerateNewsContent(w http.ResponseWriter, r *http.Request
 
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
-		http.Error(w, "Error al preparar la solicitud", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al preparar la solicitud")
 		return
 	}
 
 	httpReq, err := http.NewRequest("POST", openaiURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		http.Error(w, "Error al crear la solicitud", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al crear la solicitud")
 		return
 	}
 
@@ -729,7 +658,7 @@ func (h *AuthHandler) GenerateNewsContent(w http.ResponseWriter, r *http.Request
 	client := &http.Client{}
 	resp, err := client.Do(httpReq)
 	if err != nil {
-		http.Error(w, "Error al realizar la solicitud a OpenAI", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al realizar la solicitud a OpenAI")
 		return
 	}
 	defer resp.Body.Close()
@@ -743,12 +672,12 @@ func (h *AuthHandler) GenerateNewsContent(w http.ResponseWriter, r *http.Request
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&openaiResponse); err != nil {
-		http.Error(w, "Error al procesar la respuesta de OpenAI", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al procesar la respuesta de OpenAI")
 		return
 	}
 
 	if len(openaiResponse.Choices) == 0 {
-		http.Error(w, "No se recibió contenido de OpenAI", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se recibió contenido de OpenAI")
 		return
 	}
 
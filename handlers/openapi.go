@@ -0,0 +1,831 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Los fuentes del paquete se incluyen en el binario para leer las anotaciones de los handlers
+// (@Summary, @Param, @Router...) y los structs de request/response al armar la especificación.
+//
+//go:embed *.go
+var handlerSources embed.FS
+
+var (
+	annotationParam    = regexp.MustCompile(`^(\S+)\s+(\w+)\s+(\S+)\s+(true|false)\s+"([^"]*)"(.*)$`)
+	annotationResponse = regexp.MustCompile(`^(\d+)(?:\s+\{(\w+)\}\s+(\S+))?(?:\s+"([^"]*)")?$`)
+	annotationRouter   = regexp.MustCompile(`^(\S+)\s+\[(\w+)\]$`)
+	annotationEnums    = regexp.MustCompile(`Enums\(([^)]*)\)`)
+	routePathParam     = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+	operationIDChars   = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// apiAnnotation son las anotaciones swag del comentario de un handler
+type apiAnnotation struct {
+	summary     string
+	description string
+	tags        []string
+	accept      []string
+	produce     []string
+	params      []apiParam
+	responses   []apiResponse
+	routers     []string // "GET /bands/{id}"
+	security    bool
+}
+
+type apiParam struct {
+	name        string
+	in          string // query, path, header, body o formData
+	typ         string
+	required    bool
+	description string
+	enum        []string
+}
+
+type apiResponse struct {
+	code        string
+	kind        string // object, array, string...
+	typ         string
+	description string
+}
+
+// apiSources es lo que se lee de los fuentes: anotaciones por función ("paquete.Nombre"),
+// los tipos del paquete handlers y los comentarios de cada ruta en InitRoutes.
+type apiSources struct {
+	funcs         map[string]*apiAnnotation
+	types         map[string]*ast.TypeSpec
+	typeDocs      map[string]string
+	schemas       []string // tipos marcados con @Schema
+	routeComments map[string]string
+	general       map[string]string // @title, @version, etc. de InitRoutes
+}
+
+// parse lee los .go de fsys como parte del paquete pkg
+func (s *apiSources) parse(pkg string, fsys fs.FS) error {
+	names, err := fs.Glob(fsys, "*.go")
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, name, data, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Doc != nil {
+					s.funcs[pkg+"."+d.Name.Name] = parseAnnotations(d.Doc.Text())
+				}
+				if d.Name.Name == "InitRoutes" && d.Body != nil {
+					for _, line := range strings.Split(d.Doc.Text(), "\n") {
+						if key, value, ok := strings.Cut(strings.TrimPrefix(line, "@"), " "); ok && strings.HasPrefix(line, "@") {
+							s.general[key] = strings.TrimSpace(value)
+						}
+					}
+					s.collectRouteComments(fset, data, file, d.Body, "")
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					doc := ts.Doc
+					if doc == nil && len(d.Specs) == 1 {
+						doc = d.Doc
+					}
+					s.types[ts.Name.Name] = ts
+					if doc == nil {
+						continue
+					}
+					text := doc.Text()
+					if strings.Contains(text, "@Schema") {
+						s.schemas = append(s.schemas, ts.Name.Name)
+					}
+					s.typeDocs[ts.Name.Name] = firstSentence(text)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseAnnotations interpreta las líneas @ de un comentario; el resto del texto se ignora
+func parseAnnotations(doc string) *apiAnnotation {
+	a := &apiAnnotation{}
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+		key, value, _ := strings.Cut(line[1:], " ")
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Summary":
+			a.summary = value
+		case "Description":
+			a.description = value
+		case "Tags":
+			a.tags = splitList(value)
+		case "Accept":
+			a.accept = splitList(value)
+		case "Produce":
+			a.produce = splitList(value)
+		case "Security":
+			a.security = true
+		case "Param":
+			if m := annotationParam.FindStringSubmatch(value); m != nil {
+				p := apiParam{name: m[1], in: m[2], typ: m[3], required: m[4] == "true", description: m[5]}
+				if e := annotationEnums.FindStringSubmatch(m[6]); e != nil {
+					p.enum = splitList(e[1])
+				}
+				a.params = append(a.params, p)
+			}
+		case "Success", "Failure":
+			if m := annotationResponse.FindStringSubmatch(value); m != nil {
+				a.responses = append(a.responses, apiResponse{code: m[1], kind: m[2], typ: m[3], description: m[4]})
+			}
+		case "Router":
+			if m := annotationRouter.FindStringSubmatch(value); m != nil {
+				a.routers = append(a.routers, strings.ToUpper(m[2])+" "+normalizeRoutePath(m[1]))
+			}
+		}
+	}
+	return a
+}
+
+// collectRouteComments anota el comentario de cada r.Get/Post/... de InitRoutes: el que sigue a
+// la llamada en la misma línea o, si no hay, el que está en la línea de arriba.
+func (s *apiSources) collectRouteComments(fset *token.FileSet, src []byte, file *ast.File, body *ast.BlockStmt, prefix string) {
+	trailing := map[int]string{}
+	above := map[int]string{}
+	for _, cg := range file.Comments {
+		pos := fset.Position(cg.Pos())
+		lineStart := strings.LastIndexByte(string(src[:pos.Offset]), '\n') + 1
+		text := strings.TrimSpace(cg.Text())
+		if strings.TrimSpace(string(src[lineStart:pos.Offset])) == "" {
+			above[fset.Position(cg.End()).Line] = text
+		} else {
+			trailing[pos.Line] = text
+		}
+	}
+
+	var visit func(n ast.Node, prefix string)
+	visit = func(n ast.Node, prefix string) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			path, _ := strconv.Unquote(lit.Value)
+			if sel.Sel.Name == "Route" && len(call.Args) == 2 {
+				if fn, ok := call.Args[1].(*ast.FuncLit); ok {
+					visit(fn.Body, prefix+path)
+				}
+				return false
+			}
+			switch sel.Sel.Name {
+			case "Get", "Post", "Put", "Patch", "Delete", "Head", "Options":
+				start := fset.Position(call.Pos()).Line
+				comment := trailing[fset.Position(call.End()).Line]
+				if comment == "" {
+					comment = trailing[start]
+				}
+				if comment == "" {
+					comment = above[start-1]
+				}
+				s.routeComments[strings.ToUpper(sel.Sel.Name)+" "+normalizeRoutePath(prefix+path)] = comment
+				return false
+			}
+			return true
+		})
+	}
+	visit(body, prefix)
+}
+
+// normalizeRoutePath deja la ruta como la espera OpenAPI: sin barra final ni expresiones en los parámetros
+func normalizeRoutePath(path string) string {
+	path = routePathParam.ReplaceAllString(path, "{$1}")
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	return path
+}
+
+func routeParams(path string) []string {
+	var params []string
+	for _, m := range routePathParam.FindAllStringSubmatch(path, -1) {
+		params = append(params, m[1])
+	}
+	return params
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func firstSentence(doc string) string {
+	for _, line := range strings.Split(doc, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "@") {
+			return line
+		}
+	}
+	return ""
+}
+
+// handlerName devuelve "paquete.Nombre" de la función detrás del handler, o "" si es una
+// función anónima (esas se documentan solo con el comentario de routes.go).
+func handlerName(h http.Handler) string {
+	v := reflect.ValueOf(h)
+	if v.Kind() != reflect.Func {
+		return ""
+	}
+	fn := runtime.FuncForPC(v.Pointer())
+	if fn == nil {
+		return ""
+	}
+	name := strings.TrimSuffix(fn.Name(), "-fm")
+	slash := strings.LastIndexByte(name, '/')
+	dot := strings.IndexByte(name[slash+1:], '.')
+	if dot < 0 {
+		return ""
+	}
+	pkg, rest := name[:slash+1+dot], name[slash+2+dot:]
+	if strings.Contains(rest, ".func") || strings.HasPrefix(rest, "func") {
+		return ""
+	}
+	return pkg + "." + rest[strings.LastIndexByte(rest, '.')+1:]
+}
+
+// walkRoutes recorre las rutas como chi.Walk, pero también conserva los middlewares de un
+// r.With(...).Group que monta un r.Route: chi los guarda en el handler del montaje y Walk los pierde.
+func walkRoutes(r chi.Routes, prefix string, middlewares []func(http.Handler) http.Handler, fn func(method, route string, handler http.Handler, middlewares []func(http.Handler) http.Handler)) {
+	mws := append(slices.Clone(middlewares), r.Middlewares()...)
+	for _, route := range r.Routes() {
+		if route.SubRoutes != nil {
+			sub := mws
+			for _, h := range route.Handlers {
+				if chain, ok := h.(*chi.ChainHandler); ok {
+					sub = append(slices.Clone(mws), chain.Middlewares...)
+					break
+				}
+			}
+			walkRoutes(route.SubRoutes, prefix+strings.TrimSuffix(route.Pattern, "/*"), sub, fn)
+			continue
+		}
+		for method, h := range route.Handlers {
+			if method == "*" {
+				continue
+			}
+			if chain, ok := h.(*chi.ChainHandler); ok {
+				fn(method, prefix+route.Pattern, chain.Endpoint, append(slices.Clone(mws), chain.Middlewares...))
+			} else {
+				fn(method, prefix+route.Pattern, h, mws)
+			}
+		}
+	}
+}
+
+// apiRoute es una ruta registrada en el router
+type apiRoute struct {
+	method  string
+	path    string
+	handler string
+	secured bool
+}
+
+// specBuilder arma la especificación y junta las diferencias con las anotaciones
+type specBuilder struct {
+	src     *apiSources
+	schemas map[string]any
+	drift   []string
+}
+
+// BuildOpenAPI arma la especificación OpenAPI 3 recorriendo las rutas registradas en router.
+// Las operaciones se completan con las anotaciones de cada handler y los comentarios de
+// InitRoutes (mainSources son los fuentes del paquete main) y los modelos salen de los structs.
+// drift lista las diferencias entre las rutas y sus anotaciones: @Router que no coinciden con
+// la ruta donde está montado el handler, parámetros de ruta distintos, modelos inexistentes
+// y rutas sin ninguna documentación.
+func BuildOpenAPI(router chi.Routes, mainSources fs.FS, auth func(http.Handler) http.Handler) (spec map[string]any, drift []string, err error) {
+	src := &apiSources{
+		funcs:         map[string]*apiAnnotation{},
+		types:         map[string]*ast.TypeSpec{},
+		typeDocs:      map[string]string{},
+		routeComments: map[string]string{},
+		general:       map[string]string{},
+	}
+	if err := src.parse(reflect.TypeOf(AuthHandler{}).PkgPath(), handlerSources); err != nil {
+		return nil, nil, err
+	}
+	if err := src.parse("main", mainSources); err != nil {
+		return nil, nil, err
+	}
+
+	authPtr := reflect.ValueOf(auth).Pointer()
+	var routes []apiRoute
+	walkRoutes(router, "", nil, func(method, route string, handler http.Handler, middlewares []func(http.Handler) http.Handler) {
+		rt := apiRoute{method: method, path: normalizeRoutePath(route), handler: handlerName(handler)}
+		for _, mw := range middlewares {
+			if reflect.ValueOf(mw).Pointer() == authPtr {
+				rt.secured = true
+			}
+		}
+		routes = append(routes, rt)
+	})
+	slices.SortFunc(routes, func(a, b apiRoute) int {
+		return strings.Compare(a.path+" "+a.method, b.path+" "+b.method)
+	})
+
+	b := &specBuilder{src: src, schemas: map[string]any{}}
+	paths := map[string]map[string]any{}
+	operationIDs := map[string]int{}
+	mounted := map[string][]string{}
+	for _, rt := range routes {
+		mounted[rt.handler] = append(mounted[rt.handler], rt.method+" "+rt.path)
+	}
+
+	for _, rt := range routes {
+		if paths[rt.path] == nil {
+			paths[rt.path] = map[string]any{}
+		}
+		paths[rt.path][strings.ToLower(rt.method)] = b.operation(rt, operationIDs)
+	}
+
+	// @Router de handlers que no están montados en ninguna ruta
+	for name, a := range src.funcs {
+		if len(a.routers) == 0 || len(mounted[name]) > 0 {
+			continue
+		}
+		for _, r := range a.routers {
+			b.drift = append(b.drift, fmt.Sprintf("%s: @Router %s no corresponde a ninguna ruta registrada", shortName(name), r))
+		}
+	}
+
+	for _, name := range src.schemas {
+		b.ref(name)
+	}
+	slices.Sort(b.drift)
+
+	info := map[string]any{
+		"title":       src.general["title"],
+		"version":     src.general["version"],
+		"description": src.general["description"],
+	}
+	if src.general["contact.name"] != "" {
+		info["contact"] = map[string]any{"name": src.general["contact.name"], "url": src.general["contact.url"]}
+	}
+	if src.general["license.name"] != "" {
+		info["license"] = map[string]any{"name": src.general["license.name"]}
+	}
+	securitySchemes := map[string]any{}
+	if name := src.general["securityDefinitions.apikey"]; name != "" {
+		securitySchemes[name] = map[string]any{"type": "apiKey", "in": src.general["in"], "name": src.general["name"]}
+	}
+	basePath := src.general["BasePath"]
+	if basePath == "" {
+		basePath = "/"
+	}
+
+	spec = map[string]any{
+		"openapi": "3.0.3",
+		"info":    info,
+		"servers": []any{map[string]any{"url": basePath}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas":         b.schemas,
+			"securitySchemes": securitySchemes,
+		},
+	}
+	return spec, b.drift, nil
+}
+
+func shortName(handler string) string {
+	return handler[strings.LastIndexByte(handler, '.')+1:]
+}
+
+// operation arma la operación de una ruta y anota sus diferencias con las anotaciones
+func (b *specBuilder) operation(rt apiRoute, operationIDs map[string]int) map[string]any {
+	key := rt.method + " " + rt.path
+	comment := b.src.routeComments[key]
+	a := b.src.funcs[rt.handler]
+	if a != nil && len(a.routers) == 0 {
+		a = nil
+	}
+
+	switch {
+	case a == nil && comment == "":
+		b.drift = append(b.drift, key+": la ruta no tiene anotaciones ni comentario en routes.go")
+	case a != nil && !slices.Contains(a.routers, key):
+		b.drift = append(b.drift, fmt.Sprintf("%s: %s documenta %s", key, shortName(rt.handler), strings.Join(a.routers, ", ")))
+	}
+	if a == nil {
+		a = &apiAnnotation{}
+	}
+
+	segments := strings.Split(strings.Trim(rt.path, "/"), "/")
+	opID := shortName(rt.handler)
+	if rt.handler == "" {
+		opID = strings.ToLower(rt.method) + strings.Trim(operationIDChars.ReplaceAllString(rt.path, "_"), "_")
+	}
+	if n := operationIDs[opID]; n > 0 {
+		operationIDs[opID]++
+		opID = fmt.Sprintf("%s%d", opID, n+1)
+	} else {
+		operationIDs[opID] = 1
+	}
+
+	op := map[string]any{"operationId": opID}
+	op["summary"] = a.summary
+	if a.summary == "" {
+		op["summary"] = comment
+	}
+	if a.description != "" {
+		op["description"] = a.description
+	}
+	op["tags"] = a.tags
+	if len(a.tags) == 0 {
+		op["tags"] = []string{segments[0]}
+		if segments[0] == "" {
+			op["tags"] = []string{"general"}
+		}
+	}
+	if rt.secured {
+		op["security"] = []any{map[string]any{"BearerAuth": []string{}}}
+	} else if a.security {
+		b.drift = append(b.drift, key+": documenta @Security pero la ruta no pasa por AuthMiddleware")
+	}
+
+	// Parámetros: los de la ruta son los registrados; los documentados que no existen son diferencias
+	pathParams := routeParams(rt.path)
+	var params []any
+	documented := map[string]bool{}
+	form := map[string]any{}
+	var formRequired []string
+	for _, p := range a.params {
+		schema := b.typeSchema(p.typ, key)
+		if len(p.enum) > 0 {
+			schema["enum"] = p.enum
+		}
+		switch p.in {
+		case "path":
+			documented[p.name] = true
+			if !slices.Contains(pathParams, p.name) {
+				b.drift = append(b.drift, fmt.Sprintf("%s: el parámetro de ruta %q documentado no existe en la ruta", key, p.name))
+				continue
+			}
+			params = append(params, map[string]any{"name": p.name, "in": "path", "required": true, "description": p.description, "schema": schema})
+		case "query", "header":
+			params = append(params, map[string]any{"name": p.name, "in": p.in, "required": p.required, "description": p.description, "schema": schema})
+		case "body":
+			if rt.method == http.MethodGet || rt.method == http.MethodHead {
+				continue
+			}
+			body := map[string]any{
+				"required":    p.required,
+				"description": p.description,
+				"content":     map[string]any{"application/json": map[string]any{"schema": schema}},
+			}
+			op["requestBody"] = body
+		case "formData":
+			if p.typ == "file" {
+				schema = map[string]any{"type": "string", "format": "binary"}
+			}
+			schema["description"] = p.description
+			form[p.name] = schema
+			if p.required {
+				formRequired = append(formRequired, p.name)
+			}
+		default:
+			b.drift = append(b.drift, fmt.Sprintf("%s: el parámetro %q usa una ubicación desconocida (%s)", key, p.name, p.in))
+		}
+	}
+	for _, name := range pathParams {
+		if !documented[name] {
+			if len(a.params) > 0 {
+				b.drift = append(b.drift, fmt.Sprintf("%s: falta documentar el parámetro de ruta %q", key, name))
+			}
+			params = append([]any{map[string]any{"name": name, "in": "path", "required": true, "schema": map[string]any{"type": "string"}}}, params...)
+		}
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	if len(form) > 0 {
+		schema := map[string]any{"type": "object", "properties": form}
+		if len(formRequired) > 0 {
+			schema["required"] = formRequired
+		}
+		op["requestBody"] = map[string]any{"content": map[string]any{"multipart/form-data": map[string]any{"schema": schema}}}
+	}
+
+	// Respuestas: los errores documentados como texto en realidad salen de writeError con el formato ErrorResponse
+	produce := "application/json"
+	if len(a.produce) > 0 {
+		produce = mimeType(a.produce[0])
+	}
+	responses := map[string]any{}
+	for _, r := range a.responses {
+		resp := map[string]any{"description": r.description}
+		if resp["description"] == "" {
+			resp["description"] = http.StatusText(atoi(r.code))
+		}
+		code := atoi(r.code)
+		switch {
+		case code >= 400 && (r.typ == "" || r.typ == "string"):
+			resp["content"] = map[string]any{"application/json": map[string]any{"schema": b.ref("ErrorResponse")}}
+		case r.typ != "" && code != http.StatusNoContent:
+			schema := b.typeSchema(r.typ, key)
+			if r.kind == "array" {
+				schema = map[string]any{"type": "array", "items": schema}
+			}
+			resp["content"] = map[string]any{produce: map[string]any{"schema": schema}}
+		}
+		responses[r.code] = resp
+	}
+	if len(responses) == 0 {
+		description := comment
+		if description == "" {
+			description = "OK"
+		}
+		responses["200"] = map[string]any{"description": description}
+	}
+	if _, ok := responses["401"]; rt.secured && !ok {
+		responses["401"] = map[string]any{
+			"description": "Token ausente o inválido",
+			"content":     map[string]any{"application/json": map[string]any{"schema": b.ref("ErrorResponse")}},
+		}
+	}
+	responses["default"] = map[string]any{
+		"description": "Error",
+		"content":     map[string]any{"application/json": map[string]any{"schema": b.ref("ErrorResponse")}},
+	}
+	op["responses"] = responses
+	return op
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// mimeType traduce los alias de swag (json, plain...) a tipos MIME
+func mimeType(alias string) string {
+	switch alias {
+	case "json":
+		return "application/json"
+	case "plain":
+		return "text/plain"
+	case "html":
+		return "text/html"
+	case "mpfd":
+		return "multipart/form-data"
+	}
+	return alias
+}
+
+// typeSchema traduce el tipo de una anotación (int, string, Band, map[string]int...) a un esquema
+func (b *specBuilder) typeSchema(typ, route string) map[string]any {
+	switch {
+	case strings.HasPrefix(typ, "[]"):
+		return map[string]any{"type": "array", "items": b.typeSchema(typ[2:], route)}
+	case strings.HasPrefix(typ, "map[string]"):
+		value := strings.TrimPrefix(typ, "map[string]")
+		if value == "interface{}" || value == "any" {
+			return map[string]any{"type": "object"}
+		}
+		return map[string]any{"type": "object", "additionalProperties": b.typeSchema(value, route)}
+	}
+	switch typ {
+	case "int", "integer", "int64":
+		return map[string]any{"type": "integer"}
+	case "number", "float64":
+		return map[string]any{"type": "number"}
+	case "bool", "boolean":
+		return map[string]any{"type": "boolean"}
+	case "string":
+		return map[string]any{"type": "string"}
+	case "file":
+		return map[string]any{"type": "string", "format": "binary"}
+	case "object":
+		return map[string]any{"type": "object"}
+	}
+	if _, ok := b.src.types[typ]; !ok {
+		b.drift = append(b.drift, fmt.Sprintf("%s: el modelo %s no existe", route, typ))
+		return map[string]any{"type": "object"}
+	}
+	return b.ref(typ)
+}
+
+// ref devuelve la referencia a un tipo del paquete y lo agrega a components la primera vez
+func (b *specBuilder) ref(name string) map[string]any {
+	ref := map[string]any{"$ref": "#/components/schemas/" + name}
+	if _, ok := b.schemas[name]; ok {
+		return ref
+	}
+	ts, ok := b.src.types[name]
+	if !ok {
+		return map[string]any{"type": "object"}
+	}
+	b.schemas[name] = nil // evita recursión infinita en tipos que se referencian entre sí
+	schema := b.exprSchema(ts.Type)
+	if doc := b.src.typeDocs[name]; doc != "" {
+		schema["description"] = doc
+	}
+	b.schemas[name] = schema
+	return ref
+}
+
+// exprSchema traduce un tipo de Go (del AST) a un esquema con los nombres de sus etiquetas json
+func (b *specBuilder) exprSchema(expr ast.Expr) map[string]any {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return map[string]any{"type": "integer"}
+		case "float32", "float64":
+			return map[string]any{"type": "number"}
+		case "string":
+			return map[string]any{"type": "string"}
+		case "bool":
+			return map[string]any{"type": "boolean"}
+		case "any":
+			return map[string]any{}
+		}
+		return b.ref(t.Name)
+	case *ast.StarExpr:
+		return b.exprSchema(t.X)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": b.exprSchema(t.Elt)}
+	case *ast.MapType:
+		return map[string]any{"type": "object", "additionalProperties": b.exprSchema(t.Value)}
+	case *ast.SelectorExpr:
+		switch fmt.Sprintf("%s.%s", t.X, t.Sel.Name) {
+		case "time.Time":
+			return map[string]any{"type": "string", "format": "date-time"}
+		case "json.RawMessage":
+			return map[string]any{}
+		case "sql.NullString":
+			return map[string]any{"type": "string", "nullable": true}
+		case "sql.NullInt64":
+			return map[string]any{"type": "integer", "nullable": true}
+		}
+		return map[string]any{"type": "object"}
+	case *ast.StructType:
+		properties := map[string]any{}
+		b.structProperties(t, properties)
+		return map[string]any{"type": "object", "properties": properties}
+	}
+	return map[string]any{}
+}
+
+// structProperties agrega los campos exportados de un struct; los embebidos se aplanan como en encoding/json
+func (b *specBuilder) structProperties(st *ast.StructType, properties map[string]any) {
+	for _, field := range st.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			raw, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(raw).Get("json")
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			if ident, ok := field.Type.(*ast.Ident); ok && name == "" {
+				if ts, ok := b.src.types[ident.Name]; ok {
+					if embedded, ok := ts.Type.(*ast.StructType); ok {
+						b.structProperties(embedded, properties)
+						continue
+					}
+				}
+			}
+		}
+		for _, n := range field.Names {
+			if !n.IsExported() {
+				continue
+			}
+			key := name
+			if key == "" {
+				key = n.Name
+			}
+			schema := b.exprSchema(field.Type)
+			if comment := fieldComment(field); comment != "" {
+				if _, isRef := schema["$ref"]; isRef {
+					schema = map[string]any{"allOf": []any{schema}}
+				}
+				schema["description"] = comment
+			}
+			properties[key] = schema
+		}
+	}
+}
+
+func fieldComment(field *ast.Field) string {
+	for _, cg := range []*ast.CommentGroup{field.Comment, field.Doc} {
+		if cg != nil {
+			return strings.TrimSpace(cg.Text())
+		}
+	}
+	return ""
+}
+
+// OpenAPIHandler sirve la especificación de router en JSON. Se arma en el primer pedido, cuando
+// ya están registradas todas las rutas (incluida la propia).
+func OpenAPIHandler(router chi.Routes, mainSources fs.FS, auth func(http.Handler) http.Handler) http.HandlerFunc {
+	var once sync.Once
+	var body []byte
+	var buildErr error
+	return func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() {
+			var spec map[string]any
+			spec, _, buildErr = BuildOpenAPI(router, mainSources, auth)
+			if buildErr == nil {
+				body, buildErr = json.Marshal(spec)
+			}
+		})
+		if buildErr != nil {
+			writeError(w, r, http.StatusInternalServerError, "", buildErr)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}
+}
+
+const swaggerUIVersion = "5.17.14"
+
+const docsScript = `SwaggerUIBundle({url: "/openapi.json", dom_id: "#docs", persistAuthorization: true});`
+
+var docsPage = `<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<title>Brote Colectivo API</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+<div id="docs"></div>
+<script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js"></script>
+<script>` + docsScript + `</script>
+</body>
+</html>`
+
+// La política de seguridad general solo permite recursos propios: la documentación además
+// carga Swagger UI desde unpkg y permite su script de inicio por hash.
+var docsCSP = func() string {
+	sum := sha256.Sum256([]byte(docsScript))
+	return "default-src 'self'; script-src https://unpkg.com 'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) +
+		"'; style-src 'self' https://unpkg.com 'unsafe-inline'; img-src 'self' data: https://unpkg.com"
+}()
+
+// APIDocs sirve la documentación navegable (Swagger UI) de /openapi.json
+func APIDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Security-Policy", docsCSP)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(docsPage))
+}
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Límites de paginación comunes a todos los listados
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pageCursor identifica la última fila devuelta: el valor de cada columna de orden y el ID,
+// que desempata cuando hay valores repetidos. Sort evita usar un cursor con otro orden.
+type pageCursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	ID     int      `json:"i"`
+}
+
+func (c pageCursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodePageCursor(value string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var c pageCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// pageRequest son los parámetros de paginación de un pedido.
+// Con cursor se pagina por clave (estable aunque se agreguen filas); con offset o page, por posición.
+type pageRequest struct {
+	Limit      int
+	Offset     int
+	Cursor     *pageCursor
+	OffsetMode bool // el cliente pidió offset o page: los enlaces siguen con offset
+	WithTotal  bool
+}
+
+// parsePageRequest lee limit, offset, page, cursor y total. Los límites mayores al máximo se recortan.
+func parsePageRequest(r *http.Request, defaultLimit int) (*pageRequest, error) {
+	query := r.URL.Query()
+	p := &pageRequest{Limit: defaultLimit}
+
+	if limitParam := query.Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			return nil, fieldError("limit", "invalid", "Límite inválido")
+		}
+		p.Limit = min(limit, maxPageLimit)
+	}
+
+	if cursorParam := query.Get("cursor"); cursorParam != "" {
+		cursor, err := decodePageCursor(cursorParam)
+		if err != nil {
+			return nil, fieldError("cursor", "invalid", "Cursor inválido")
+		}
+		p.Cursor = cursor
+	} else if offsetParam := query.Get("offset"); offsetParam != "" {
+		offset, err := strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			return nil, fieldError("offset", "invalid", "Offset inválido")
+		}
+		p.Offset = offset
+		p.OffsetMode = true
+	} else if pageParam := query.Get("page"); pageParam != "" {
+		page, err := strconv.Atoi(pageParam)
+		if err != nil || page < 1 {
+			return nil, fieldError("page", "invalid", "Página inválida")
+		}
+		p.Offset = (page - 1) * p.Limit
+		p.OffsetMode = true
+	}
+
+	switch query.Get("total") {
+	case "true", "1":
+		p.WithTotal = true
+	}
+	return p, nil
+}
+
+// sortTerm es una de las columnas del orden de un listado
+type sortTerm struct {
+	name   string // nombre público, va en el cursor
+	column string
+	desc   bool
+}
+
+// listQuery arma el SELECT de un listado a partir de los filtros del pedido. El mismo builder
+// genera la consulta paginada y la de conteo, así /count y /table no repiten la lógica de filtros.
+// Las columnas y el FROM vienen siempre del código (ver listSpec); del pedido solo salen parámetros.
+type listQuery struct {
+	columns    string
+	columnArgs []interface{} // parámetros de las columnas (ej. el punto de near)
+	from       string        // tabla con sus joins
+	id         string        // columna de ID, desempata el orden
+	conditions []string
+	args       []interface{}
+	orders     []sortTerm
+	keyset     bool // admite cursor; no se puede cuando se ordena por una columna calculada
+}
+
+func newListQuery(columns, from, id string) *listQuery {
+	return &listQuery{
+		columns: columns,
+		from:    from,
+		id:      id,
+		orders:  []sortTerm{{name: "id", column: id, desc: true}},
+		keyset:  true,
+	}
+}
+
+// where agrega una condición con sus parámetros
+func (q *listQuery) where(condition string, args ...interface{}) *listQuery {
+	q.conditions = append(q.conditions, condition)
+	q.args = append(q.args, args...)
+	return q
+}
+
+// orderBy reemplaza el orden por una sola columna. El ID se agrega siempre como desempate.
+func (q *listQuery) orderBy(name, column string, desc bool) *listQuery {
+	q.orders = []sortTerm{{name: name, column: column, desc: desc}}
+	return q
+}
+
+// sortKey identifica el orden dentro del cursor, ej. "date_start:desc,title:asc"
+func (q *listQuery) sortKey() string {
+	parts := make([]string, len(q.orders))
+	for i, term := range q.orders {
+		parts[i] = term.name + ":asc"
+		if term.desc {
+			parts[i] = term.name + ":desc"
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// tiebreak es el orden completo: las columnas pedidas y el ID al final, en la dirección de la anterior
+func (q *listQuery) tiebreak() []sortTerm {
+	last := q.orders[len(q.orders)-1]
+	if last.column == q.id {
+		return q.orders
+	}
+	return append(append([]sortTerm{}, q.orders...), sortTerm{column: q.id, desc: last.desc})
+}
+
+func (q *listQuery) whereSQL(extra ...string) string {
+	conditions := append(append([]string{}, q.conditions...), extra...)
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// countSQL cuenta las filas que cumplen los filtros
+func (q *listQuery) countSQL() (string, []interface{}) {
+	return "SELECT COUNT(*) FROM " + q.from + q.whereSQL(), append([]interface{}{}, q.args...)
+}
+
+// keysetSQL es la condición "después del cursor" para un orden de varias columnas:
+// (c1 > v1) OR (c1 = v1 AND c2 > v2) OR ... con el operador de la dirección de cada una.
+func (q *listQuery) keysetSQL(cursor *pageCursor) (string, []interface{}) {
+	terms := q.tiebreak()
+	value := func(i int) interface{} {
+		if i < len(cursor.Values) {
+			return cursor.Values[i]
+		}
+		return cursor.ID
+	}
+
+	var alternatives []string
+	var args []interface{}
+	for i, term := range terms {
+		var parts []string
+		for j, previous := range terms[:i] {
+			parts = append(parts, previous.column+" = ?")
+			args = append(args, value(j))
+		}
+		op := ">"
+		if term.desc {
+			op = "<"
+		}
+		parts = append(parts, term.column+" "+op+" ?")
+		args = append(args, value(i))
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// pageSQL arma la consulta de una página. Las primeras columnas son la clave del cursor
+// (un valor por columna de orden, más el ID); se pide una fila de más para saber si hay página siguiente.
+func (q *listQuery) pageSQL(p *pageRequest) (string, []interface{}) {
+	var keys []string
+	if q.keyset {
+		for _, term := range q.orders {
+			keys = append(keys, "IFNULL(CAST("+term.column+" AS CHAR), '')")
+		}
+	}
+	keys = append(keys, q.id)
+
+	args := append([]interface{}{}, q.columnArgs...)
+	args = append(args, q.args...)
+
+	var keysetCondition []string
+	if p.Cursor != nil && q.keyset {
+		condition, keysetArgs := q.keysetSQL(p.Cursor)
+		keysetCondition = append(keysetCondition, condition)
+		args = append(args, keysetArgs...)
+	}
+
+	var orders []string
+	for _, term := range q.tiebreak() {
+		if term.desc {
+			orders = append(orders, term.column+" DESC")
+		} else {
+			orders = append(orders, term.column+" ASC")
+		}
+	}
+
+	query := "SELECT " + strings.Join(keys, ", ") + ", " + q.columns + " FROM " + q.from +
+		q.whereSQL(keysetCondition...) +
+		" ORDER BY " + strings.Join(orders, ", ") +
+		" LIMIT ?"
+	args = append(args, p.Limit+1)
+	if p.Cursor == nil && p.Offset > 0 {
+		query += " OFFSET ?"
+		args = append(args, p.Offset)
+	}
+	return query, args
+}
+
+// pageInfo es el resultado de una página: si hay más filas, la clave de la última y el total
+type pageInfo struct {
+	request *pageRequest
+	keyset  bool
+	hasMore bool
+	last    pageCursor
+	total   *int
+}
+
+// queryPage ejecuta la consulta paginada. row escanea cada fila con la función scan,
+// que recibe solo los destinos de las columnas del listado.
+func (h *AuthHandler) queryPage(q *listQuery, p *pageRequest, row func(scan func(dest ...interface{}) error) error) (*pageInfo, error) {
+	if p.Cursor != nil {
+		if !q.keyset {
+			return nil, fieldError("cursor", "invalid", "Este listado no admite cursor, usá offset")
+		}
+		if p.Cursor.Sort != q.sortKey() || len(p.Cursor.Values) != len(q.orders) {
+			return nil, fieldError("cursor", "invalid", "El cursor corresponde a otro orden")
+		}
+	}
+
+	query, args := q.pageSQL(p)
+	rows, err := h.DB.Select(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	info := &pageInfo{request: p, keyset: q.keyset}
+	read := 0
+	for rows.Next() {
+		read++
+		if read > p.Limit {
+			info.hasMore = true
+			break
+		}
+		key := pageCursor{Sort: q.sortKey()}
+		var keyDest []interface{}
+		if q.keyset {
+			key.Values = make([]string, len(q.orders))
+			for i := range key.Values {
+				keyDest = append(keyDest, &key.Values[i])
+			}
+		}
+		keyDest = append(keyDest, &key.ID)
+		err := row(func(dest ...interface{}) error {
+			return rows.Scan(append(keyDest, dest...)...)
+		})
+		if err != nil {
+			return nil, err
+		}
+		info.last = key
+	}
+	rows.Close()
+
+	if p.WithTotal {
+		total, err := h.countList(q)
+		if err != nil {
+			return nil, err
+		}
+		info.total = &total
+	}
+	return info, nil
+}
+
+// countList cuenta las filas del listado con los mismos filtros
+func (h *AuthHandler) countList(q *listQuery) (int, error) {
+	query, args := q.countSQL()
+	row, err := h.DB.SelectRow(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = row.Scan(&count)
+	return count, err
+}
+
+// pageURL devuelve la URL del pedido actual con otros parámetros de paginación
+func pageURL(r *http.Request, set map[string]string) string {
+	query := r.URL.Query()
+	for _, param := range []string{"cursor", "offset", "page"} {
+		query.Del(param)
+	}
+	for param, value := range set {
+		query.Set(param, value)
+	}
+	return r.URL.Path + "?" + query.Encode()
+}
+
+// writePageHeaders agrega los encabezados de paginación: Link con next (y prev con offset),
+// X-Next-Cursor y, si se pidió total=true, X-Total-Count.
+func writePageHeaders(w http.ResponseWriter, r *http.Request, info *pageInfo) {
+	p := info.request
+	var links []string
+
+	if info.hasMore {
+		if p.OffsetMode || !info.keyset {
+			next := pageURL(r, map[string]string{"offset": strconv.Itoa(p.Offset + p.Limit)})
+			links = append(links, `<`+next+`>; rel="next"`)
+		} else {
+			cursor := info.last.encode()
+			links = append(links, `<`+pageURL(r, map[string]string{"cursor": cursor})+`>; rel="next"`)
+			w.Header().Set("X-Next-Cursor", cursor)
+		}
+	}
+	if p.OffsetMode && p.Offset > 0 {
+		prev := pageURL(r, map[string]string{"offset": strconv.Itoa(max(p.Offset-p.Limit, 0))})
+		links = append(links, `<`+prev+`>; rel="prev"`)
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	if info.total != nil {
+		w.Header().Set("X-Total-Count", strconv.Itoa(*info.total))
+	}
+}
+
+// writeListError responde 400 si el problema está en los parámetros y 500 si falló la consulta
+func writeListError(w http.ResponseWriter, r *http.Request, err error) {
+	var fields validationErrors
+	if errors.As(err, &fields) {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+	writeError(w, r, http.StatusInternalServerError, "Error al consultar el listado", err)
+}
@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+)
+
+// inClause devuelve los placeholders y parámetros de un IN (...) con los IDs sin repetir
+func inClause(ids []int) (string, []interface{}) {
+	seen := make(map[int]bool, len(ids))
+	var args []interface{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			args = append(args, id)
+		}
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", "), args
+}
+
+// bandsByEvent devuelve las bandas de cada evento, en una sola consulta
+func (h *AuthHandler) bandsByEvent(eventIDs []int) (map[int][]Band, error) {
+	result := map[int][]Band{}
+	if len(eventIDs) == 0 {
+		return result, nil
+	}
+	placeholders, args := inClause(eventIDs)
+	rows, err := h.DB.Select(`
+		SELECT eb.id_event, b.id, b.name, b.slug
+		FROM events_bands eb
+		JOIN bands b ON b.id = eb.id_band
+		WHERE eb.id_event IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var eventID int
+		var b Band
+		if err := rows.Scan(&eventID, &b.ID, &b.Name, &b.Slug); err != nil {
+			return nil, err
+		}
+		result[eventID] = append(result[eventID], b)
+	}
+	return result, nil
+}
+
+// bandsByNews devuelve las bandas de cada noticia, en una sola consulta
+func (h *AuthHandler) bandsByNews(newsIDs []int) (map[int][]Band, error) {
+	result := map[int][]Band{}
+	if len(newsIDs) == 0 {
+		return result, nil
+	}
+	placeholders, args := inClause(newsIDs)
+	rows, err := h.DB.Select(`
+		SELECT nb.id_news, b.id, b.name, b.slug
+		FROM news_bands nb
+		JOIN bands b ON nb.id_band = b.id
+		WHERE nb.id_news IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var newsID int
+		var b Band
+		if err := rows.Scan(&newsID, &b.ID, &b.Name, &b.Slug); err != nil {
+			return nil, err
+		}
+		result[newsID] = append(result[newsID], b)
+	}
+	return result, nil
+}
+
+// bandsByVideo devuelve las bandas de cada video, en una sola consulta
+func (h *AuthHandler) bandsByVideo(videoIDs []int) (map[int][]*Band, error) {
+	result := map[int][]*Band{}
+	if len(videoIDs) == 0 {
+		return result, nil
+	}
+	placeholders, args := inClause(videoIDs)
+	rows, err := h.DB.Select(`
+		SELECT vb.id_video, b.id, b.name, b.slug
+		FROM videos_bands vb
+		JOIN bands b ON vb.id_band = b.id
+		WHERE vb.id_video IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var videoID int
+		b := &Band{}
+		if err := rows.Scan(&videoID, &b.ID, &b.Name, &b.Slug); err != nil {
+			return nil, err
+		}
+		result[videoID] = append(result[videoID], b)
+	}
+	return result, nil
+}
+
+// bandsByID devuelve las bandas completas, en una sola consulta
+func (h *AuthHandler) bandsByID(bandIDs []int) (map[int]*Band, error) {
+	result := map[int]*Band{}
+	if len(bandIDs) == 0 {
+		return result, nil
+	}
+	placeholders, args := inClause(bandIDs)
+	rows, err := h.DB.Select(`
+		SELECT id, name, IFNULL(bio, ''), slug, social
+		FROM bands
+		WHERE id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b Band
+		var socialRaw []byte
+		if err := rows.Scan(&b.ID, &b.Name, &b.Bio, &b.Slug, &socialRaw); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(socialRaw, &b.Social); err != nil {
+			b.Social = map[string]string{}
+		}
+		result[b.ID] = &b
+	}
+	return result, nil
+}
+
+// genresByBand devuelve los géneros de cada banda, según los géneros de sus canciones
+func (h *AuthHandler) genresByBand(bandIDs []int) (map[int][]Genre, error) {
+	result := map[int][]Genre{}
+	if len(bandIDs) == 0 {
+		return result, nil
+	}
+	placeholders, args := inClause(bandIDs)
+	rows, err := h.DB.Select(`
+		SELECT DISTINCT s.id_band, g.id, g.name
+		FROM songs s
+		JOIN genres g ON g.id = s.id_genre
+		WHERE s.id_band IN (`+placeholders+`)
+		ORDER BY g.name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bandID int
+		var g Genre
+		if err := rows.Scan(&bandID, &g.ID, &g.Name); err != nil {
+			return nil, err
+		}
+		result[bandID] = append(result[bandID], g)
+	}
+	return result, nil
+}
+
+// venuesByID devuelve los venues completos (con perfil y coordenadas), en una sola consulta
+func (h *AuthHandler) venuesByID(venueIDs []int) (map[int]*Venue, error) {
+	result := map[int]*Venue{}
+	if len(venueIDs) == 0 {
+		return result, nil
+	}
+	placeholders, args := inClause(venueIDs)
+	rows, err := h.DB.Select(`
+		SELECT v.id, v.name, v.address, v.description, v.slug, v.latlng, v.city, `+venueLatLngSQL+`, `+venueProfileColumns+`
+		FROM venues v
+		WHERE v.id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v Venue
+		var lat, lng sql.NullFloat64
+		var p venueProfileScan
+		dest := []interface{}{&v.ID, &v.Name, &v.Address, &v.Description, &v.Slug, &v.LatLng, &v.City, &lat, &lng}
+		if err := rows.Scan(append(dest, p.dest()...)...); err != nil {
+			return nil, err
+		}
+		applyVenueCoordinates(&v, lat, lng)
+		p.apply(&v.VenueProfile)
+		result[v.ID] = &v
+	}
+	return result, nil
+}
+
+// seriesByID devuelve los datos básicos de cada festival o ciclo, en una sola consulta
+func (h *AuthHandler) seriesByID(seriesIDs []int) (map[int]*Series, error) {
+	result := map[int]*Series{}
+	if len(seriesIDs) == 0 {
+		return result, nil
+	}
+	placeholders, args := inClause(seriesIDs)
+	rows, err := h.DB.Select(`
+		SELECT id, type, title, slug, description, image, date_start, date_end
+		FROM event_series
+		WHERE id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s Series
+		var description, image, dateStart, dateEnd sql.NullString
+		if err := rows.Scan(&s.ID, &s.Type, &s.Title, &s.Slug, &description, &image, &dateStart, &dateEnd); err != nil {
+			return nil, err
+		}
+		s.Description = description.String
+		s.Image = image.String
+		s.DateStart = dateStart.String
+		s.DateEnd = dateEnd.String
+		result[s.ID] = &s
+	}
+	return result, nil
+}
+
+// eventsBy devuelve los eventos agrupados por key (ej. eb.id_band), del más reciente al más viejo.
+// join agrega la tabla de la relación cuando key no es una columna de events.
+func (h *AuthHandler) eventsBy(key, join string, ids []int) (map[int][]Event, error) {
+	result := map[int][]Event{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	placeholders, args := inClause(ids)
+	rows, err := h.DB.Select(`
+		SELECT `+key+`, e.id, e.title, e.tags, e.content, e.slug, e.date_start, e.date_end, e.id_venue, IFNULL(e.id_series, 0),
+			`+eventTicketingColumns+`,
+			v.name
+		FROM events e
+		JOIN venues v ON e.id_venue = v.id
+		`+join+`
+		WHERE `+key+` IN (`+placeholders+`)
+		ORDER BY e.date_start DESC, e.id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var owner int
+		var e Event
+		var v Venue
+		var t ticketingScan
+		dest := []interface{}{&owner, &e.ID, &e.Title, &e.Tags, &e.Content, &e.Slug, &e.DateStart, &e.DateEnd, &e.VenueID, &e.SeriesID}
+		dest = append(dest, t.dest()...)
+		if err := rows.Scan(append(dest, &v.Name)...); err != nil {
+			return nil, err
+		}
+		t.apply(&e.EventTicketing)
+		v.ID = e.VenueID
+		e.Venue = &v
+		result[owner] = append(result[owner], e)
+	}
+	return result, nil
+}
+
+// eventsByBand devuelve los eventos de cada banda, en una sola consulta
+func (h *AuthHandler) eventsByBand(bandIDs []int) (map[int][]Event, error) {
+	return h.eventsBy("eb.id_band", "JOIN events_bands eb ON eb.id_event = e.id", bandIDs)
+}
+
+// eventsByVenue devuelve los eventos de cada venue, en una sola consulta
+func (h *AuthHandler) eventsByVenue(venueIDs []int) (map[int][]Event, error) {
+	return h.eventsBy("e.id_venue", "", venueIDs)
+}
+
+// newsByBand devuelve las noticias de cada banda, de la más nueva a la más vieja, en una sola consulta
+func (h *AuthHandler) newsByBand(bandIDs []int) (map[int][]News, error) {
+	result := map[int][]News{}
+	if len(bandIDs) == 0 {
+		return result, nil
+	}
+	placeholders, args := inClause(bandIDs)
+	rows, err := h.DB.Select(`
+		SELECT nb.id_band, n.id, n.slug, n.date, n.title, n.content
+		FROM news n
+		JOIN news_bands nb ON nb.id_news = n.id
+		WHERE nb.id_band IN (`+placeholders+`)
+		ORDER BY n.date DESC, n.id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bandID int
+		var n News
+		if err := rows.Scan(&bandID, &n.ID, &n.Slug, &n.Date, &n.Title, &n.Content); err != nil {
+			return nil, err
+		}
+		result[bandID] = append(result[bandID], n)
+	}
+	return result, nil
+}
+
+// videosByBand devuelve los videos de cada banda, en una sola consulta
+func (h *AuthHandler) videosByBand(bandIDs []int) (map[int][]Video, error) {
+	result := map[int][]Video{}
+	if len(bandIDs) == 0 {
+		return result, nil
+	}
+	placeholders, args := inClause(bandIDs)
+	rows, err := h.DB.Select(`
+		SELECT vb.id_band, v.id, v.title, v.slug, v.id_youtube
+		FROM videos v
+		JOIN videos_bands vb ON vb.id_video = v.id
+		WHERE vb.id_band IN (`+placeholders+`)
+		ORDER BY v.id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bandID int
+		var v Video
+		if err := rows.Scan(&bandID, &v.ID, &v.Title, &v.Slug, &v.YoutubeID); err != nil {
+			return nil, err
+		}
+		result[bandID] = append(result[bandID], v)
+	}
+	return result, nil
+}
+
+// songsByBand devuelve las canciones de cada banda con su género, en una sola consulta
+func (h *AuthHandler) songsByBand(bandIDs []int) (map[int][]Song, error) {
+	result := map[int][]Song{}
+	if len(bandIDs) == 0 {
+		return result, nil
+	}
+	placeholders, args := inClause(bandIDs)
+	rows, err := h.DB.Select(`
+		SELECT s.id, s.title, s.slug, s.id_band, IFNULL(s.id_genre, 0), g.name, IFNULL(l.id, 0)
+		FROM songs s
+		LEFT JOIN genres g ON s.id_genre = g.id
+		LEFT JOIN lyrics l ON s.id = l.id_song
+		WHERE s.id_band IN (`+placeholders+`)
+		ORDER BY s.title`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s Song
+		var genreName sql.NullString
+		if err := rows.Scan(&s.ID, &s.Title, &s.Slug, &s.BandID, &s.GenreID, &genreName, &s.LyricsID); err != nil {
+			return nil, err
+		}
+		if genreName.Valid {
+			s.Genre = &Genre{ID: s.GenreID, Name: genreName.String}
+		}
+		result[s.BandID] = append(result[s.BandID], s)
+	}
+	return result, nil
+}
+
+// Campos y relaciones de los listados de eventos
+var eventFieldSet = fieldSetSpec{
+	fields: []string{"id", "title", "tags", "content", "slug", "date_start", "date_end", "id_venue", "rol", "id_series",
+		"price_tiers", "is_free", "free_until_capacity", "ticket_url", "min_age", "door_time"},
+	relations: []string{"venue", "bands", "genres", "series"},
+	defaults:  []string{"bands"},
+}
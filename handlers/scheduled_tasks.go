@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"brotecolectivo/utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"gopkg.in/ini.v1"
+)
+
+const (
+	pendingMediaMaxAge      = 7 * 24 * time.Hour // archivos en pending/ sin colaboración que los use
+	defaultStaleSubmissions = 3                  // días que una colaboración puede esperar revisión antes del aviso
+)
+
+// registerScheduledTasks declara las tareas periódicas con su horario por defecto
+func (h *AuthHandler) registerScheduledTasks() {
+	s := h.scheduler
+	s.add(&scheduledTask{
+		Name:        "instagram_token",
+		Description: "Renueva el token de acceso de Instagram antes de que venza (dura 60 días)",
+		Schedule:    "0 4 * * 1",
+		run:         h.renewInstagramTokenTask,
+	})
+	s.add(&scheduledTask{
+		Name:        "pending_media_cleanup",
+		Description: "Borra las imágenes y audios pendientes de más de 7 días que ninguna colaboración usa",
+		Schedule:    "30 3 * * *",
+		run:         h.cleanupPendingMedia,
+	})
+	s.add(&scheduledTask{
+		Name:        "weekly_digest",
+		Description: "Resumen de los eventos de los próximos 7 días (webhook events.weekly_digest y WhatsApp al administrador)",
+		Schedule:    "0 10 * * 1",
+		run:         h.sendWeeklyDigest,
+	})
+	s.add(&scheduledTask{
+		Name:        "event_reminders",
+		Description: "Webhook event.reminder por cada evento de mañana",
+		Schedule:    "0 12 * * *",
+		run:         h.sendEventReminders,
+	})
+	s.add(&scheduledTask{
+		Name:        "stale_submissions",
+		Description: "Avisa al administrador por WhatsApp de las colaboraciones que esperan revisión hace varios días",
+		Schedule:    "0 9 * * *",
+		run:         h.nudgeStaleSubmissions,
+	})
+}
+
+func (h *AuthHandler) renewInstagramTokenTask() (string, error) {
+	cfg, err := ini.Load("data.conf")
+	if err != nil {
+		return "", fmt.Errorf("error al cargar configuración: %v", err)
+	}
+	if cfg.Section("instagram").Key("app_id").String() == "" {
+		return "Instagram no está configurado", nil
+	}
+	if err := h.renewInstagramToken(); err != nil {
+		return "", err
+	}
+	return "Token renovado", nil
+}
+
+// cleanupPendingMedia borra de pending/ en Spaces lo que quedó de colaboraciones que ya no existen
+// y los audios de canciones cuya conversión no va a reintentarse
+func (h *AuthHandler) cleanupPendingMedia() (string, error) {
+	// Slugs que todavía usa alguna colaboración (también rechazadas, que pueden reabrirse)
+	rows, err := h.DB.Select("SELECT id, type, data FROM submissions")
+	if err != nil {
+		return "", err
+	}
+	inUse := map[string]bool{}
+	for rows.Next() {
+		var s Submission
+		if rows.Scan(&s.ID, &s.Type, &s.Data) != nil {
+			continue
+		}
+		if _, _, slug, err := extractFieldsFromSubmission(s); err == nil && slug != "" {
+			inUse[slug] = true
+		}
+	}
+	rows.Close()
+
+	accessKey, secretKey, region, endpoint, bucket, err := utils.LoadSpacesConfig()
+	if err != nil {
+		return "", err
+	}
+	sess, _ := session.NewSession(&aws.Config{
+		Region:           aws.String(region),
+		Endpoint:         aws.String(endpoint),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretKey, ""),
+	})
+	svc := s3.New(sess)
+
+	cutoff := time.Now().Add(-pendingMediaMaxAge)
+	var stale []string
+	err = svc.ListObjectsV2Pages(&s3.ListObjectsV2Input{Bucket: aws.String(bucket), Prefix: aws.String("pending/")},
+		func(page *s3.ListObjectsV2Output, last bool) bool {
+			for _, obj := range page.Contents {
+				key := aws.StringValue(obj.Key)
+				slug := strings.TrimSuffix(strings.TrimPrefix(key, "pending/"), filepath.Ext(key))
+				if aws.TimeValue(obj.LastModified).Before(cutoff) && !inUse[slug] {
+					stale = append(stale, key)
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return "", fmt.Errorf("no se pudo listar pending/: %v", err)
+	}
+	deleted := 0
+	for _, key := range stale {
+		if _, err := svc.DeleteObject(&s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}); err != nil {
+			fmt.Printf("[Warning] No se pudo borrar %s: %v\n", key, err)
+			continue
+		}
+		deleted++
+	}
+
+	return fmt.Sprintf("%d archivos borrados de pending/, %d audios locales", deleted, h.cleanupSongAudioUploads(cutoff)), nil
+}
+
+// cleanupSongAudioUploads borra los audios de songAudioDir anteriores a cutoff que ningún
+// trabajo pendiente va a convertir
+func (h *AuthHandler) cleanupSongAudioUploads(cutoff time.Time) int {
+	rows, err := h.DB.Select("SELECT payload FROM jobs WHERE type = ? AND status IN ('pending', 'running')", jobConvertSongAudio.Name)
+	if err != nil {
+		return 0
+	}
+	waiting := map[string]bool{}
+	for rows.Next() {
+		var payload []byte
+		var p convertSongAudioPayload
+		if rows.Scan(&payload) == nil && json.Unmarshal(payload, &p) == nil {
+			waiting[filepath.Clean(p.InputPath)] = true
+		}
+	}
+	rows.Close()
+
+	entries, err := os.ReadDir(songAudioDir)
+	if err != nil {
+		return 0
+	}
+	deleted := 0
+	for _, entry := range entries {
+		path := filepath.Join(songAudioDir, entry.Name())
+		info, err := entry.Info()
+		if err != nil || info.IsDir() || info.ModTime().After(cutoff) || waiting[path] {
+			continue
+		}
+		if os.Remove(path) == nil {
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// upcomingEvents devuelve los eventos que empiezan en el rango, con su espacio. Una fila que no se
+// puede leer queda en el log y no frena el resumen ni los recordatorios.
+func (h *AuthHandler) upcomingEvents(where string) ([]webhookEvent, error) {
+	rows, err := h.DB.Select(webhookEventQuery + " WHERE " + where + " ORDER BY e.date_start")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []webhookEvent{}
+	for rows.Next() {
+		e, err := scanWebhookEvent(rows)
+		if err != nil {
+			fmt.Printf("[Warning] Se omitió un evento del resumen: %v\n", err)
+			continue
+		}
+		events = append(events, *e)
+	}
+	return events, rows.Err()
+}
+
+func (h *AuthHandler) sendWeeklyDigest() (string, error) {
+	events, err := h.upcomingEvents("e.date_start >= CURDATE() AND e.date_start < CURDATE() + INTERVAL 7 DAY")
+	if err != nil {
+		return "", err
+	}
+	from := time.Now()
+	h.emitWebhook("events.weekly_digest", map[string]interface{}{
+		"from":   from.Format("2006-01-02"),
+		"to":     from.AddDate(0, 0, 6).Format("2006-01-02"),
+		"events": events,
+	})
+
+	message := fmt.Sprintf("Esta semana en Brote Colectivo: %d eventos.", len(events))
+	for _, e := range events {
+		line := fmt.Sprintf("\n• %s - %s", shortDate(e.DateStart), e.Title)
+		if e.Venue.Name != "" {
+			line += " (" + e.Venue.Name + ")"
+		}
+		message += line
+	}
+	if err := h.notifyAdmin(message); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d eventos en el resumen", len(events)), nil
+}
+
+func (h *AuthHandler) sendEventReminders() (string, error) {
+	events, err := h.upcomingEvents("DATE(e.date_start) = CURDATE() + INTERVAL 1 DAY")
+	if err != nil {
+		return "", err
+	}
+	for _, e := range events {
+		h.emitWebhook("event.reminder", e)
+	}
+	return fmt.Sprintf("%d recordatorios", len(events)), nil
+}
+
+func (h *AuthHandler) nudgeStaleSubmissions() (string, error) {
+	days := defaultStaleSubmissions
+	if cfg, err := ini.Load("data.conf"); err == nil {
+		days = cfg.Section("scheduler").Key("stale_submission_days").MustInt(defaultStaleSubmissions)
+	}
+	rows, err := h.DB.Select(`
+		SELECT id, type, data, created_at FROM submissions
+		WHERE status = 'pending' AND created_at < NOW() - INTERVAL ? DAY
+		ORDER BY created_at`, days)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for rows.Next() {
+		var s Submission
+		if rows.Scan(&s.ID, &s.Type, &s.Data, &s.CreatedAt) != nil {
+			continue
+		}
+		name, _, _, _ := extractFieldsFromSubmission(s)
+		lines = append(lines, fmt.Sprintf("\n• #%d %s: %s (desde el %s)", s.ID, s.Type, name, shortDate(s.CreatedAt)))
+	}
+	rows.Close()
+	if len(lines) == 0 {
+		return "No hay colaboraciones demoradas", nil
+	}
+
+	count := len(lines)
+	message := fmt.Sprintf("Hay %d colaboraciones esperando revisión hace más de %d días:", count, days)
+	if count > 10 {
+		lines = append(lines[:10], fmt.Sprintf("\n… y %d más", count-10))
+	}
+	message += strings.Join(lines, "")
+	if err := h.notifyAdmin(message); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Aviso de %d colaboraciones demoradas", count), nil
+}
+
+// notifyAdmin manda un mensaje al WhatsApp del administrador, si está configurado
+func (h *AuthHandler) notifyAdmin(message string) error {
+	cfg, err := ini.Load("data.conf")
+	if err != nil {
+		return nil
+	}
+	adminPhone := cfg.Section("keys").Key("admin_phone").String()
+	if adminPhone == "" {
+		return nil
+	}
+	return h.sendWhatsAppMessage(adminPhone, message)
+}
+
+// shortDate pasa una fecha de MySQL (2025-03-14 21:00:00) a 14/03
+func shortDate(value string) string {
+	t, err := time.Parse("2006-01-02 15:04:05", value)
+	if err != nil {
+		return value
+	}
+	return t.Format("02/01")
+}
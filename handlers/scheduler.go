@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"brotecolectivo/database"
+	"brotecolectivo/models"
+
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/ini.v1"
+)
+
+const (
+	schedulerLockName = "brotecolectivo_scheduler" // GET_LOCK que decide qué instancia agenda las tareas
+	schedulerTick     = 30 * time.Second
+	schedulerTimeFmt  = "2006-01-02 15:04:05"
+)
+
+// scheduledTask es una tarea periódica. Schedule es la expresión cron por defecto; se puede
+// cambiar (u "off" para desactivarla) en la sección [scheduler] de data.conf. run devuelve un
+// resumen de lo que hizo, que queda como último resultado.
+type scheduledTask struct {
+	Name        string
+	Description string
+	Schedule    string
+	run         func() (string, error)
+
+	cron *cronSchedule // nil si está desactivada
+	expr string
+}
+
+// Scheduler ejecuta tareas periódicas. Con varias instancias de la API solo agenda la que tiene
+// el lock de MySQL (el líder): las tareas vencidas se encolan como trabajos, así corren una sola
+// vez aunque el líder cambie, y la tabla scheduled_tasks guarda la próxima ejecución y el último
+// resultado de cada una.
+type Scheduler struct {
+	db       *database.DatabaseStruct
+	instance string
+	tasks    []*scheduledTask
+
+	mu     sync.Mutex
+	conn   *sql.Conn // conexión que retiene el lock mientras esta instancia es líder
+	leader bool
+}
+
+func newScheduler(db *database.DatabaseStruct, instance string) *Scheduler {
+	return &Scheduler{db: db, instance: instance}
+}
+
+func (s *Scheduler) add(t *scheduledTask) {
+	s.tasks = append(s.tasks, t)
+}
+
+func (s *Scheduler) task(name string) *scheduledTask {
+	for _, t := range s.tasks {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// configure lee las expresiones de [scheduler] en data.conf. Una expresión inválida deja la
+// tarea con la de por defecto.
+func (s *Scheduler) configure() {
+	cfg, err := ini.Load("data.conf")
+	for _, t := range s.tasks {
+		t.expr = t.Schedule
+		if err == nil {
+			if custom := cfg.Section("scheduler").Key(t.Name).String(); custom != "" {
+				t.expr = custom
+			}
+		}
+		if t.expr == "off" {
+			t.cron = nil
+			continue
+		}
+		c, perr := parseCron(t.expr)
+		if perr == nil && c.Next(time.Now()).IsZero() {
+			perr = fmt.Errorf("nunca se cumple")
+		}
+		if perr != nil {
+			fmt.Printf("[Warning] Horario inválido para la tarea %s (%v); se usa %q\n", t.Name, perr, t.Schedule)
+			t.expr = t.Schedule
+			c, _ = parseCron(t.Schedule)
+		}
+		t.cron = c
+	}
+}
+
+// StartScheduler inicia el agendado de tareas periódicas. Se puede apagar en una instancia con
+// enabled = false en [scheduler].
+func (h *AuthHandler) StartScheduler() {
+	s := h.scheduler
+	s.configure()
+	if cfg, err := ini.Load("data.conf"); err == nil && !cfg.Section("scheduler").Key("enabled").MustBool(true) {
+		fmt.Println("[Info] Tareas programadas desactivadas en esta instancia")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(schedulerTick)
+		defer ticker.Stop()
+		for {
+			if s.elect() {
+				s.dispatch(h.jobs)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// elect intenta tomar el lock de líder o confirma que esta instancia lo sigue teniendo. El lock
+// es de la conexión: si la instancia se cae, MySQL lo libera y otra lo toma en el próximo intento.
+func (s *Scheduler) elect() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if s.conn != nil {
+		var holder sql.NullInt64
+		var self int64
+		err := s.conn.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?), CONNECTION_ID()", schedulerLockName).Scan(&holder, &self)
+		if err == nil && holder.Valid && holder.Int64 == self {
+			return true
+		}
+		if err != nil {
+			fmt.Printf("[Warning] Se perdió la conexión del líder de las tareas programadas: %v\n", err)
+		} else {
+			fmt.Println("[Warning] Esta instancia dejó de ser líder de las tareas programadas")
+		}
+		s.conn.Close()
+		s.conn = nil
+		s.leader = false
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return false
+	}
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", schedulerLockName).Scan(&acquired); err != nil || acquired.Int64 != 1 {
+		conn.Close()
+		return false
+	}
+	s.conn = conn
+	s.leader = true
+	fmt.Printf("[Info] %s es líder de las tareas programadas\n", s.instance)
+	s.syncSchedules()
+	return true
+}
+
+// syncSchedules registra las tareas y adelanta su próxima ejecución si el horario cambió a uno
+// más temprano. Una ejecución que quedó vencida mientras no había líder corre una vez al volver.
+func (s *Scheduler) syncSchedules() {
+	now := time.Now()
+	for _, t := range s.tasks {
+		if t.cron == nil {
+			continue
+		}
+		next := t.cron.Next(now).Format(schedulerTimeFmt)
+		_, err := s.db.Exec(`
+			INSERT INTO scheduled_tasks (name, next_run_at) VALUES (?, ?)
+			ON DUPLICATE KEY UPDATE next_run_at = IF(next_run_at IS NULL OR next_run_at > VALUES(next_run_at), VALUES(next_run_at), next_run_at)`,
+			t.Name, next)
+		if err != nil {
+			fmt.Printf("[Warning] No se pudo registrar la tarea %s: %v\n", t.Name, err)
+		}
+	}
+}
+
+// dispatch encola las tareas vencidas. La actualización condicional de next_run_at evita que se
+// encolen dos veces si hubo un cambio de líder en el medio.
+func (s *Scheduler) dispatch(q *JobQueue) {
+	now := time.Now()
+	rows, err := s.db.Select("SELECT name, next_run_at FROM scheduled_tasks WHERE next_run_at <= ?", now.Format(schedulerTimeFmt))
+	if err != nil {
+		fmt.Printf("[Warning] No se pudieron consultar las tareas programadas: %v\n", err)
+		return
+	}
+	due := map[string]string{}
+	for rows.Next() {
+		var name, nextRun string
+		if rows.Scan(&name, &nextRun) == nil {
+			due[name] = nextRun
+		}
+	}
+	rows.Close()
+
+	for _, t := range s.tasks {
+		nextRun, ok := due[t.Name]
+		if !ok || t.cron == nil {
+			continue
+		}
+		claimed, err := s.db.Update(false, "UPDATE scheduled_tasks SET next_run_at = ? WHERE name = ? AND next_run_at = ?",
+			t.cron.Next(now).Format(schedulerTimeFmt), t.Name, nextRun)
+		if err != nil || claimed != 1 {
+			continue
+		}
+		if _, err := EnqueueJob(q, jobScheduledTask, scheduledTaskPayload{Task: t.Name}, 0); err != nil {
+			fmt.Printf("[Warning] No se pudo encolar la tarea %s: %v\n", t.Name, err)
+		}
+	}
+}
+
+// runScheduledTask ejecuta una tarea (desde su trabajo) y guarda el resultado
+func (h *AuthHandler) runScheduledTask(p scheduledTaskPayload) error {
+	t := h.scheduler.task(p.Task)
+	if t == nil {
+		return fmt.Errorf("tarea programada desconocida: %s", p.Task)
+	}
+	started := time.Now()
+	_, err := h.DB.Exec(`
+		INSERT INTO scheduled_tasks (name, last_started_at, last_status, last_instance) VALUES (?, ?, 'running', ?)
+		ON DUPLICATE KEY UPDATE last_started_at = VALUES(last_started_at), last_status = 'running', last_instance = VALUES(last_instance)`,
+		t.Name, started.Format(schedulerTimeFmt), h.scheduler.instance)
+	if err != nil {
+		return err
+	}
+
+	message, runErr := t.run()
+	status := "ok"
+	if runErr != nil {
+		status = "error"
+		message = runErr.Error()
+	}
+	_, err = h.DB.Exec(`
+		UPDATE scheduled_tasks SET last_finished_at = ?, last_status = ?, last_message = ?, last_duration_ms = ?
+		WHERE name = ?`,
+		time.Now().Format(schedulerTimeFmt), status, message, time.Since(started).Milliseconds(), t.Name)
+	if err != nil {
+		fmt.Printf("[Warning] No se pudo guardar el resultado de la tarea %s: %v\n", t.Name, err)
+	}
+	return runErr
+}
+
+// ScheduledTaskStatus es una tarea programada con su horario y su última ejecución.
+//
+// @Schema
+type ScheduledTaskStatus struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Schedule       string `json:"schedule"` // expresión cron, u "off" si está desactivada
+	Enabled        bool   `json:"enabled"`
+	NextRunAt      string `json:"next_run_at,omitempty"`
+	LastStartedAt  string `json:"last_started_at,omitempty"`
+	LastFinishedAt string `json:"last_finished_at,omitempty"`
+	LastStatus     string `json:"last_status,omitempty"` // running, ok o error
+	LastMessage    string `json:"last_message,omitempty"`
+	LastDurationMs int64  `json:"last_duration_ms,omitempty"`
+	LastInstance   string `json:"last_instance,omitempty"`
+}
+
+// SchedulerStatus es el estado del agendado visto desde la instancia que responde.
+//
+// @Schema
+type SchedulerStatus struct {
+	Instance string                `json:"instance"`
+	Leader   bool                  `json:"leader"` // si esta instancia es la que agenda las tareas
+	Tasks    []ScheduledTaskStatus `json:"tasks"`
+}
+
+// GetScheduler devuelve las tareas programadas con su horario y el resultado de su última ejecución (solo administradores).
+//
+// @Summary Estado de las tareas programadas
+// @Tags tareas programadas
+// @Produce json
+// @Success 200 {object} SchedulerStatus "Tareas programadas"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /scheduler [get]
+func (h *AuthHandler) GetScheduler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	rows, err := h.DB.Select(`
+		SELECT name, IFNULL(next_run_at, ''), IFNULL(last_started_at, ''), IFNULL(last_finished_at, ''),
+		       IFNULL(last_status, ''), IFNULL(last_message, ''), IFNULL(last_duration_ms, 0), IFNULL(last_instance, '')
+		FROM scheduled_tasks`)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener las tareas programadas", err)
+		return
+	}
+	defer rows.Close()
+	stored := map[string]ScheduledTaskStatus{}
+	for rows.Next() {
+		var t ScheduledTaskStatus
+		if rows.Scan(&t.Name, &t.NextRunAt, &t.LastStartedAt, &t.LastFinishedAt, &t.LastStatus, &t.LastMessage,
+			&t.LastDurationMs, &t.LastInstance) == nil {
+			stored[t.Name] = t
+		}
+	}
+
+	s := h.scheduler
+	s.mu.Lock()
+	status := SchedulerStatus{Instance: s.instance, Leader: s.leader, Tasks: []ScheduledTaskStatus{}}
+	s.mu.Unlock()
+	for _, t := range s.tasks {
+		task := stored[t.Name]
+		task.Name = t.Name
+		task.Description = t.Description
+		task.Schedule = t.expr
+		task.Enabled = t.cron != nil
+		if !task.Enabled {
+			task.NextRunAt = ""
+		}
+		status.Tasks = append(status.Tasks, task)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// RunScheduledTask encola una tarea programada para que corra ya, sin cambiar su próxima ejecución.
+//
+// @Summary Ejecutar una tarea programada
+// @Tags tareas programadas
+// @Produce json
+// @Param name path string true "Nombre de la tarea"
+// @Success 202 {object} map[string]interface{} "Trabajo encolado (job_id)"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Tarea no encontrada"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /scheduler/{name}/run [post]
+func (h *AuthHandler) RunScheduledTask(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	name := chi.URLParam(r, "name")
+	if h.scheduler.task(name) == nil {
+		writeError(w, r, http.StatusNotFound, "Tarea no encontrada")
+		return
+	}
+	jobID, err := EnqueueJob(h.jobs, jobScheduledTask, scheduledTaskPayload{Task: name}, 0)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "No se pudo encolar la tarea", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"job_id": jobID})
+}
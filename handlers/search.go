@@ -0,0 +1,511 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"brotecolectivo/models"
+)
+
+// Resultados por tipo que se devuelven por defecto y como máximo
+const (
+	defaultSearchLimit = 5
+	maxSearchLimit     = 20
+)
+
+// Filas que se leen del índice antes de agrupar por tipo
+const searchCandidateLimit = 500
+
+// Largo aproximado del fragmento resaltado, en caracteres
+const searchSnippetLength = 180
+
+// Peso extra de una coincidencia en el título frente a una en el cuerpo
+const searchTitleWeight = 3
+
+// searchSource describe cómo se arma el documento indexado de cada tipo de contenido.
+// La consulta devuelve id, título, slug y cuerpo; idColumn se usa para indexar un solo registro.
+type searchSource struct {
+	query    string
+	idColumn string
+}
+
+// Fuentes del índice de búsqueda. Los eventos y videos incluyen los nombres de sus bandas
+// para que buscar un artista también encuentre sus fechas.
+var searchSources = map[string]searchSource{
+	"band": {
+		query:    `SELECT b.id, b.name, b.slug, IFNULL(b.bio, '') FROM bands b`,
+		idColumn: "b.id",
+	},
+	"event": {
+		query: `SELECT e.id, e.title, e.slug, CONCAT_WS(' ', IFNULL(e.content, ''), IFNULL(e.tags, ''),
+				IFNULL(v.name, ''), IFNULL(v.city, ''),
+				IFNULL((SELECT GROUP_CONCAT(b.name SEPARATOR ', ') FROM events_bands eb
+					JOIN bands b ON b.id = eb.id_band WHERE eb.id_event = e.id), ''))
+			FROM events e LEFT JOIN venues v ON v.id = e.id_venue`,
+		idColumn: "e.id",
+	},
+	"venue": {
+		query: `SELECT v.id, v.name, v.slug, CONCAT_WS(' ', IFNULL(v.description, ''),
+				IFNULL(v.address, ''), IFNULL(v.city, ''))
+			FROM venues v`,
+		idColumn: "v.id",
+	},
+	"news": {
+		query:    `SELECT n.id, n.title, n.slug, IFNULL(n.content, '') FROM news n`,
+		idColumn: "n.id",
+	},
+	"song": {
+		query: `SELECT s.id, s.title, s.slug, IFNULL(b.name, '')
+			FROM songs s LEFT JOIN bands b ON b.id = s.id_band`,
+		idColumn: "s.id",
+	},
+	"lyrics": {
+		query: `SELECT l.id, IFNULL(s.title, ''), IFNULL(s.slug, ''),
+				CONCAT_WS(' ', IFNULL(l.lyric, ''), IFNULL(l.author, ''))
+			FROM lyrics l LEFT JOIN songs s ON s.id = l.id_song`,
+		idColumn: "l.id",
+	},
+	"video": {
+		query: `SELECT vd.id, vd.title, vd.slug,
+				IFNULL((SELECT GROUP_CONCAT(b.name SEPARATOR ', ') FROM videos_bands vb
+					JOIN bands b ON b.id = vb.id_band WHERE vb.id_video = vd.id), '')
+			FROM videos vd`,
+		idColumn: "vd.id",
+	},
+}
+
+// Orden en que se reindexa el contenido
+var searchTypes = []string{"band", "event", "venue", "news", "song", "lyrics", "video"}
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// plainText quita etiquetas HTML y espacios repetidos del contenido antes de indexarlo
+func plainText(content string) string {
+	content = html.UnescapeString(htmlTagPattern.ReplaceAllString(content, " "))
+	return strings.Join(strings.Fields(content), " ")
+}
+
+// indexTerms arma la columna de términos para el índice FULLTEXT.
+// Se descartan los términos de menos de 3 letras porque InnoDB no los indexa.
+func indexTerms(text string) string {
+	var kept []string
+	for _, term := range searchTerms(text) {
+		if len(term) >= 3 {
+			kept = append(kept, term)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// SearchResult es un registro encontrado por GET /search.
+//
+// @Schema
+type SearchResult struct {
+	Type    string  `json:"type"`
+	ID      int     `json:"id"`
+	Title   string  `json:"title"`
+	Slug    string  `json:"slug"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"` // HTML escapado, con <mark> en las coincidencias
+}
+
+// SearchGroup agrupa los resultados de un mismo tipo de contenido.
+//
+// @Schema
+type SearchGroup struct {
+	Type    string         `json:"type"`
+	Total   int            `json:"total"`
+	Results []SearchResult `json:"results"`
+}
+
+// reindexSearch actualiza en el índice de búsqueda los registros indicados.
+// Los que ya no existen se quitan del índice. Los errores se registran pero no cortan la operación
+// que disparó la actualización: el índice se puede reconstruir con POST /search/reindex.
+func (h *AuthHandler) reindexSearch(entityType string, ids ...int) {
+	source, ok := searchSources[entityType]
+	if !ok {
+		return
+	}
+	// Bandas y venues también están en el autocompletado
+	if entityType == "band" || entityType == "venue" {
+		h.autocomplete.markStale()
+	}
+	for _, id := range ids {
+		if id <= 0 {
+			continue
+		}
+		rows, err := h.DB.Select(source.query+" WHERE "+source.idColumn+" = ?", id)
+		if err != nil {
+			fmt.Printf("[Warning] No se pudo leer %s #%d para el índice de búsqueda: %v\n", entityType, id, err)
+			continue
+		}
+		found := false
+		for rows.Next() {
+			var doc SearchResult
+			var body string
+			if err := rows.Scan(&doc.ID, &doc.Title, &doc.Slug, &body); err != nil {
+				continue
+			}
+			found = true
+			if err := h.writeSearchDocument(entityType, doc, body); err != nil {
+				fmt.Printf("[Warning] No se pudo indexar %s #%d: %v\n", entityType, id, err)
+			}
+		}
+		rows.Close()
+		if !found {
+			h.removeFromSearch(entityType, id)
+		}
+	}
+}
+
+// reindexSearchRef reindexa un registro a partir de su ID o su slug actual
+func (h *AuthHandler) reindexSearchRef(entityType, idOrSlug string) {
+	if ref := h.currentSlug(entityType, idOrSlug); ref.ID > 0 {
+		h.reindexSearch(entityType, ref.ID)
+	}
+}
+
+// reindexSearchQuery reindexa los registros cuyos IDs devuelve la consulta.
+// Se usa para los documentos que incluyen datos de otra entidad (nombre de banda, de venue, título de canción).
+func (h *AuthHandler) reindexSearchQuery(entityType, idQuery string, args ...interface{}) {
+	rows, err := h.DB.Select(idQuery, args...)
+	if err != nil {
+		fmt.Printf("[Warning] No se pudieron obtener los %s a reindexar: %v\n", entityType, err)
+		return
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if rows.Scan(&id) == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	h.reindexSearch(entityType, ids...)
+}
+
+// reindexBandSearch reindexa una banda y el contenido que muestra su nombre
+func (h *AuthHandler) reindexBandSearch(bandID int) {
+	h.reindexSearch("band", bandID)
+	h.reindexSearchQuery("event", "SELECT id_event FROM events_bands WHERE id_band = ?", bandID)
+	h.reindexSearchQuery("video", "SELECT id_video FROM videos_bands WHERE id_band = ?", bandID)
+	h.reindexSearchQuery("song", "SELECT id FROM songs WHERE id_band = ?", bandID)
+}
+
+// removeFromSearch quita un registro del índice de búsqueda
+func (h *AuthHandler) removeFromSearch(entityType string, id int) {
+	if entityType == "band" || entityType == "venue" {
+		h.autocomplete.markStale()
+	}
+	if _, err := h.DB.Delete(false, "DELETE FROM search_index WHERE entity_type = ? AND entity_id = ?", entityType, id); err != nil {
+		fmt.Printf("[Warning] No se pudo quitar %s #%d del índice de búsqueda: %v\n", entityType, id, err)
+	}
+}
+
+// writeSearchDocument guarda (o reemplaza) el documento de un registro en search_index
+func (h *AuthHandler) writeSearchDocument(entityType string, doc SearchResult, body string) error {
+	body = plainText(body)
+	_, err := h.DB.Exec(`
+		REPLACE INTO search_index (entity_type, entity_id, title, slug, body, title_terms, terms, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, NOW())`,
+		entityType, doc.ID, doc.Title, doc.Slug, body,
+		indexTerms(doc.Title), indexTerms(doc.Title+" "+body))
+	return err
+}
+
+// rebuildSearchIndex vuelve a indexar todo el contenido de un tipo y borra los documentos huérfanos
+func (h *AuthHandler) rebuildSearchIndex(entityType string) (int, error) {
+	source := searchSources[entityType]
+
+	// Todo lo que no se reescriba desde este momento es de registros que ya no existen
+	var startedAt string
+	row, err := h.DB.SelectRow("SELECT NOW()")
+	if err != nil {
+		return 0, err
+	}
+	if err := row.Scan(&startedAt); err != nil {
+		return 0, err
+	}
+
+	rows, err := h.DB.Select(source.query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var doc SearchResult
+		var body string
+		if err := rows.Scan(&doc.ID, &doc.Title, &doc.Slug, &body); err != nil {
+			return count, err
+		}
+		if err := h.writeSearchDocument(entityType, doc, body); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	// Documentos de registros que se borraron sin pasar por la API
+	_, err = h.DB.Delete(false, "DELETE FROM search_index WHERE entity_type = ? AND updated_at < ?", entityType, startedAt)
+	return count, err
+}
+
+// booleanSearchQuery arma la consulta FULLTEXT: todas las raíces obligatorias y como prefijo
+func booleanSearchQuery(terms []string) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		parts = append(parts, "+"+term+"*")
+	}
+	return strings.Join(parts, " ")
+}
+
+// searchSnippet devuelve un fragmento del cuerpo alrededor de la primera coincidencia,
+// con las palabras encontradas entre <mark>. El resto del texto va escapado.
+func searchSnippet(body string, terms []string, length int) string {
+	type word struct{ start, end int }
+	var words []word
+	start := -1
+	for i, r := range body {
+		isWordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWordRune && start < 0 {
+			start = i
+		} else if !isWordRune && start >= 0 {
+			words = append(words, word{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, word{start, len(body)})
+	}
+
+	matches := func(w word) bool {
+		stem := stemSpanish(foldForMatch(body[w.start:w.end]))
+		for _, term := range terms {
+			if strings.HasPrefix(stem, term) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Ubicar la ventana alrededor de la primera coincidencia
+	first := -1
+	for i, w := range words {
+		if matches(w) {
+			first = i
+			break
+		}
+	}
+	from, to := 0, len(body)
+	if first >= 0 && words[first].start > length/3 {
+		from = words[first].start - length/3
+	}
+	// Ajustar los bordes a inicio y fin de palabra
+	for _, w := range words {
+		if w.start >= from {
+			from = w.start
+			break
+		}
+	}
+	if from+length < len(body) {
+		to = from + length
+		for i := len(words) - 1; i >= 0; i-- {
+			if words[i].end <= to {
+				to = words[i].end
+				break
+			}
+		}
+		// Una palabra más larga que el fragmento se corta, en el borde de una runa
+		if to <= from {
+			to = from + length
+			for to > from && !utf8.RuneStart(body[to]) {
+				to--
+			}
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("… ")
+	}
+	pos := from
+	for _, w := range words {
+		if w.start < from || w.end > to {
+			continue
+		}
+		if !matches(w) {
+			continue
+		}
+		b.WriteString(html.EscapeString(body[pos:w.start]))
+		b.WriteString("<mark>" + html.EscapeString(body[w.start:w.end]) + "</mark>")
+		pos = w.end
+	}
+	b.WriteString(html.EscapeString(body[pos:to]))
+	if to < len(body) {
+		b.WriteString(" …")
+	}
+	return b.String()
+}
+
+// Search busca en bandas, eventos, espacios, noticias, canciones, letras y videos a la vez.
+//
+// @Summary Búsqueda general
+// @Description Búsqueda de texto completo sin distinguir tildes y con raíces en español ("recitales" encuentra "recital"). Los resultados se ordenan por relevancia y se agrupan por tipo, con un fragmento resaltado.
+// @Tags búsqueda
+// @Produce json
+// @Param q query string true "Texto a buscar"
+// @Param type query string false "Tipos a incluir, separados por coma (band, event, venue, news, song, lyrics, video)"
+// @Param limit query int false "Resultados por tipo (por defecto 5, máximo 20)"
+// @Success 200 {object} map[string]interface{} "Resultados agrupados por tipo"
+// @Failure 400 {string} string "Búsqueda inválida"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Router /search [get]
+func (h *AuthHandler) Search(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	var terms []string
+	for _, term := range searchTerms(q) {
+		if len(term) >= 3 {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		writeError(w, r, http.StatusBadRequest, "Parámetro 'q' requerido (al menos una palabra de 3 letras)")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxSearchLimit)
+	}
+
+	where := "MATCH(terms) AGAINST(? IN BOOLEAN MODE)"
+	match := booleanSearchQuery(terms)
+	args := []interface{}{match}
+	if typesParam := r.URL.Query().Get("type"); typesParam != "" {
+		var placeholders []string
+		for _, t := range strings.Split(typesParam, ",") {
+			t = strings.TrimSpace(t)
+			if _, ok := searchSources[t]; !ok {
+				writeError(w, r, http.StatusBadRequest, "Tipo de contenido inválido: "+t)
+				return
+			}
+			placeholders = append(placeholders, "?")
+			args = append(args, t)
+		}
+		where += " AND entity_type IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	// Totales por tipo
+	totals := map[string]int{}
+	countRows, err := h.DB.Select("SELECT entity_type, COUNT(*) FROM search_index WHERE "+where+" GROUP BY entity_type", args...)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al buscar")
+		return
+	}
+	for countRows.Next() {
+		var t string
+		var n int
+		if err := countRows.Scan(&t, &n); err == nil {
+			totals[t] = n
+		}
+	}
+	countRows.Close()
+
+	// Los mejores resultados de todos los tipos; se recortan por tipo al agrupar
+	scoreArgs := append([]interface{}{match, match}, args...)
+	rows, err := h.DB.Select(fmt.Sprintf(`
+		SELECT entity_type, entity_id, title, slug, body,
+			MATCH(title_terms) AGAINST(? IN BOOLEAN MODE) * %d + MATCH(terms) AGAINST(? IN BOOLEAN MODE) AS score
+		FROM search_index
+		WHERE %s
+		ORDER BY score DESC, updated_at DESC
+		LIMIT %d`, searchTitleWeight, where, searchCandidateLimit), scoreArgs...)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al buscar")
+		return
+	}
+	defer rows.Close()
+
+	groups := map[string]*SearchGroup{}
+	var order []string
+	for rows.Next() {
+		var res SearchResult
+		var body string
+		if err := rows.Scan(&res.Type, &res.ID, &res.Title, &res.Slug, &body, &res.Score); err != nil {
+			continue
+		}
+		group, ok := groups[res.Type]
+		if !ok {
+			group = &SearchGroup{Type: res.Type, Total: totals[res.Type], Results: []SearchResult{}}
+			groups[res.Type] = group
+			order = append(order, res.Type)
+		}
+		if len(group.Results) >= limit {
+			continue
+		}
+		res.Score = float64(int(res.Score*1000)) / 1000
+		res.Snippet = searchSnippet(body, terms, searchSnippetLength)
+		group.Results = append(group.Results, res)
+	}
+
+	// Los grupos quedan ordenados por su mejor resultado
+	result := make([]SearchGroup, 0, len(order))
+	for _, t := range order {
+		result = append(result, *groups[t])
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Results[0].Score > result[j].Results[0].Score })
+
+	total := 0
+	for _, n := range totals {
+		total += n
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"query":  q,
+		"terms":  terms,
+		"total":  total,
+		"groups": result,
+	})
+}
+
+// ReindexSearch reconstruye el índice de búsqueda completo.
+//
+// @Summary Reconstruir índice de búsqueda
+// @Description Vuelve a indexar todo el contenido. Solo hace falta después de cargar datos por fuera de la API.
+// @Tags búsqueda
+// @Produce json
+// @Success 200 {object} map[string]int "Documentos indexados por tipo"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 500 {string} string "Error al reconstruir el índice"
+// @Security BearerAuth
+// @Router /search/reindex [post]
+func (h *AuthHandler) ReindexSearch(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+
+	counts := map[string]int{}
+	for _, entityType := range searchTypes {
+		n, err := h.rebuildSearchIndex(entityType)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "Error al reindexar "+entityType, err)
+			return
+		}
+		counts[entityType] = n
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counts)
+}
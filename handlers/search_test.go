@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSearchSnippet(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		query   string
+		want    []string // fragmentos que tiene que incluir el resultado
+		notWant []string
+	}{
+		{
+			name:  "texto corto sin recortes",
+			body:  "Recital de rock en el centro cultural",
+			query: "recitales",
+			want:  []string{"<mark>Recital</mark> de rock"},
+		},
+		{
+			name:    "coincidencia lejos del principio",
+			body:    strings.Repeat("relleno ", 60) + "un festival de jazz " + strings.Repeat("cola ", 60),
+			query:   "festival",
+			want:    []string{"… ", "<mark>festival</mark>", " …"},
+			notWant: []string{"<mark>relleno</mark>"},
+		},
+		{
+			name:  "palabra más larga que el fragmento después de multibyte",
+			body:  "a " + strings.Repeat("🎵", 30) + " mundo" + strings.Repeat("x", 300) + " fin",
+			query: "mund",
+			want:  []string{"… "},
+		},
+		{
+			name:  "palabra larga multibyte",
+			body:  "inicio " + strings.Repeat("ñ", 400) + " final",
+			query: "inicio",
+			want:  []string{"<mark>inicio</mark>", " …"},
+		},
+		{
+			name:  "tildes y mayúsculas",
+			body:  "La CANCIÓN del año",
+			query: "cancion",
+			want:  []string{"<mark>CANCIÓN</mark>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := searchSnippet(tt.body, searchTerms(tt.query), searchSnippetLength)
+			if !utf8.ValidString(got) {
+				t.Fatalf("el fragmento no es UTF-8 válido: %q", got)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("searchSnippet() = %q, falta %q", got, w)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("searchSnippet() = %q, no debería tener %q", got, w)
+				}
+			}
+		})
+	}
+}
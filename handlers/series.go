@@ -0,0 +1,903 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"brotecolectivo/models"
+
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/ini.v1"
+)
+
+// Series representa un festival o ciclo que agrupa varios eventos,
+// posiblemente en distintos venues y fechas.
+//
+// @Schema
+type Series struct {
+	ID          int     `json:"id"`
+	Type        string  `json:"type"` // festival, cycle
+	Title       string  `json:"title"`
+	Slug        string  `json:"slug"`
+	Description string  `json:"description"`
+	Image       string  `json:"image"`
+	DateStart   string  `json:"date_start"`
+	DateEnd     string  `json:"date_end"`
+	Events      []Event `json:"events,omitempty"`
+	EventIDs    []int   `json:"event_ids,omitempty"`
+}
+
+// SeriesLineupEntry es una banda del lineup con las fechas en las que toca dentro de la serie.
+//
+// @Schema
+type SeriesLineupEntry struct {
+	Band   Band            `json:"band"`
+	Events []EventConflict `json:"events"`
+	Venues []string        `json:"venues"`
+}
+
+var validSeriesTypes = map[string]bool{"festival": true, "cycle": true}
+
+// Argentina no usa horario de verano, así que alcanza con un huso fijo
+var argentinaTZ = time.FixedZone("ART", -3*60*60)
+
+// resolveSeriesID obtiene el ID de una serie a partir de su ID o su slug
+func (h *AuthHandler) resolveSeriesID(idOrSlug string) (int, error) {
+	if id, err := strconv.Atoi(idOrSlug); err == nil {
+		return id, nil
+	}
+	row, err := h.DB.SelectRow("SELECT id FROM event_series WHERE slug = ?", idOrSlug)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// getSeriesByIDInternal obtiene los datos básicos de una serie
+func (h *AuthHandler) getSeriesByIDInternal(id int) (*Series, error) {
+	row, err := h.DB.SelectRow(`
+		SELECT id, type, title, slug, description, image, date_start, date_end
+		FROM event_series
+		WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Series
+	var description, image, dateStart, dateEnd sql.NullString
+	if err := row.Scan(&s.ID, &s.Type, &s.Title, &s.Slug, &description, &image, &dateStart, &dateEnd); err != nil {
+		return nil, err
+	}
+	s.Description = description.String
+	s.Image = image.String
+	s.DateStart = dateStart.String
+	s.DateEnd = dateEnd.String
+	return &s, nil
+}
+
+// getSeriesEvents devuelve los eventos de una serie con su venue y sus bandas, ordenados por fecha
+func (h *AuthHandler) getSeriesEvents(seriesID int) ([]Event, error) {
+	rows, err := h.DB.Select(`
+		SELECT
+			e.id, e.title, e.tags, e.content, e.slug, e.date_start, e.date_end,
+			`+eventTicketingColumns+`,
+			v.id, v.name, v.address, v.city, v.slug
+		FROM events e
+		JOIN venues v ON e.id_venue = v.id
+		WHERE e.id_series = ?
+		ORDER BY e.date_start ASC`, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var e Event
+		var v Venue
+		var t ticketingScan
+		dest := []interface{}{&e.ID, &e.Title, &e.Tags, &e.Content, &e.Slug, &e.DateStart, &e.DateEnd}
+		dest = append(dest, t.dest()...)
+		dest = append(dest, &v.ID, &v.Name, &v.Address, &v.City, &v.Slug)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		t.apply(&e.EventTicketing)
+		e.Venue = &v
+		e.VenueID = v.ID
+		e.SeriesID = seriesID
+		events = append(events, e)
+	}
+	rows.Close()
+
+	// Bandas asociadas, en una sola consulta para todos los eventos
+	if err := h.newLoader().eventRelations(events, &fieldSet{includes: map[string]bool{"bands": true}}); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// assignSeriesEvents vincula los eventos indicados a la serie
+func (h *AuthHandler) assignSeriesEvents(seriesID int, eventIDs []int) {
+	for _, eventID := range eventIDs {
+		_, err := h.DB.Update(false, "UPDATE events SET id_series = ? WHERE id = ?", seriesID, eventID)
+		if err != nil {
+			fmt.Printf("Error al vincular evento %d con la serie %d: %v\n", eventID, seriesID, err)
+		}
+	}
+}
+
+// refreshSeriesDates completa las fechas de la serie con el rango de sus eventos cuando no fueron cargadas
+func (h *AuthHandler) refreshSeriesDates(seriesID int) {
+	_, err := h.DB.Update(false, `
+		UPDATE event_series s
+		JOIN (
+			SELECT id_series, DATE(MIN(date_start)) AS first_day, DATE(MAX(COALESCE(date_end, date_start))) AS last_day
+			FROM events
+			WHERE id_series = ?
+			GROUP BY id_series
+		) e ON e.id_series = s.id
+		SET s.date_start = COALESCE(s.date_start, e.first_day),
+		    s.date_end = COALESCE(s.date_end, e.last_day)
+		WHERE s.id = ?`, seriesID, seriesID)
+	if err != nil {
+		fmt.Printf("Error al actualizar fechas de la serie %d: %v\n", seriesID, err)
+	}
+}
+
+// nullableString devuelve nil para cadenas vacías, así se guardan como NULL
+func nullableString(value string) interface{} {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return value
+}
+
+// GetSeries devuelve los festivales y ciclos, con opciones de filtrado y paginación.
+//
+// @Summary Listar series
+// @Description Obtiene festivales y ciclos de eventos
+// @Tags series
+// @Produce json
+// @Param offset query int false "Desplazamiento para paginación"
+// @Param limit query int false "Límite de registros"
+// @Param q query string false "Término de búsqueda"
+// @Param type query string false "Tipo de serie (festival, cycle)"
+// @Success 200 {array} Series "Lista de series"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Router /series [get]
+func (h *AuthHandler) GetSeries(w http.ResponseWriter, r *http.Request) {
+	offsetParam := r.URL.Query().Get("offset")
+	limitParam := r.URL.Query().Get("limit")
+	search := r.URL.Query().Get("q")
+	seriesType := r.URL.Query().Get("type")
+
+	offset := 0
+	limit := 10
+	var err error
+
+	if offsetParam != "" {
+		offset, err = strconv.Atoi(offsetParam)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Offset inválido")
+			return
+		}
+	}
+	if limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Límite inválido")
+			return
+		}
+	}
+
+	query := `
+		SELECT id, type, title, slug, description, image, date_start, date_end
+		FROM event_series
+		WHERE 1=1
+	`
+	var queryParams []interface{}
+
+	if search != "" {
+		pattern := "%" + search + "%"
+		query += " AND (title LIKE ? OR description LIKE ? OR slug LIKE ?)"
+		queryParams = append(queryParams, pattern, pattern, pattern)
+	}
+	if seriesType != "" {
+		query += " AND type = ?"
+		queryParams = append(queryParams, seriesType)
+	}
+
+	query += " ORDER BY date_start DESC LIMIT ? OFFSET ?"
+	queryParams = append(queryParams, limit, offset)
+
+	rows, err := h.DB.Select(query, queryParams...)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener series")
+		return
+	}
+	defer rows.Close()
+
+	series := []Series{}
+	for rows.Next() {
+		var s Series
+		var description, image, dateStart, dateEnd sql.NullString
+		if err := rows.Scan(&s.ID, &s.Type, &s.Title, &s.Slug, &description, &image, &dateStart, &dateEnd); err != nil {
+			writeError(w, r, http.StatusInternalServerError, "Error al leer series")
+			return
+		}
+		s.Description = description.String
+		s.Image = image.String
+		s.DateStart = dateStart.String
+		s.DateEnd = dateEnd.String
+		series = append(series, s)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(series)
+}
+
+// GetSeriesByID devuelve una serie por ID o slug, con todos sus eventos.
+//
+// @Summary Obtener serie
+// @Description Obtiene un festival o ciclo con sus eventos ordenados por fecha
+// @Tags series
+// @Produce json
+// @Param id path string true "ID o slug de la serie"
+// @Success 200 {object} Series "Detalles de la serie"
+// @Failure 404 {string} string "Serie no encontrada"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Router /series/{id} [get]
+func (h *AuthHandler) GetSeriesByID(w http.ResponseWriter, r *http.Request) {
+	seriesID, err := h.resolveSeriesID(chi.URLParam(r, "id"))
+	if err != nil {
+		if h.redirectOldSlug(w, r, "series", chi.URLParam(r, "id")) {
+			return
+		}
+		writeError(w, r, http.StatusNotFound, "Serie no encontrada")
+		return
+	}
+
+	series, err := h.getSeriesByIDInternal(seriesID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "Serie no encontrada")
+		return
+	}
+
+	series.Events, err = h.getSeriesEvents(seriesID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener los eventos de la serie")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(series)
+}
+
+// CreateSeries crea un festival o ciclo y le vincula eventos existentes.
+//
+// @Summary Crear serie
+// @Description Crea un festival o ciclo. Si no se indican fechas, se toman del rango de sus eventos
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param series body Series true "Datos de la serie (event_ids opcional)"
+// @Security BearerAuth
+// @Success 201 {object} Series "Serie creada"
+// @Failure 400 {string} string "Error en la solicitud"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Router /series [post]
+func (h *AuthHandler) CreateSeries(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+
+	var input Series
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar el cuerpo")
+		return
+	}
+	if input.Title == "" || input.Slug == "" {
+		writeError(w, r, http.StatusBadRequest, "El título y el slug son obligatorios")
+		return
+	}
+	if input.Type == "" {
+		input.Type = "festival"
+	}
+	if !validSeriesTypes[input.Type] {
+		writeError(w, r, http.StatusBadRequest, "Tipo de serie inválido (festival o cycle)")
+		return
+	}
+
+	// Si el slug está ocupado se prueba primero con el año de inicio
+	var yearHint string
+	if start, err := parseEventDate(input.DateStart); err == nil {
+		yearHint = strconv.Itoa(start.Year())
+	}
+	slug, err := h.assignSlug("series", input.Slug, input.Title, yearHint)
+	if err != nil {
+		writeSlugError(w, r, err)
+		return
+	}
+	input.Slug = slug
+	defer h.releaseSlug("series", slug)
+
+	seriesID, err := h.DB.Insert(false, `
+		INSERT INTO event_series (type, title, slug, description, image, date_start, date_end)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		input.Type, input.Title, input.Slug, input.Description, nullableString(input.Image),
+		nullableString(input.DateStart), nullableString(input.DateEnd))
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al crear la serie", err)
+		return
+	}
+
+	h.assignSeriesEvents(seriesID, input.EventIDs)
+	h.refreshSeriesDates(seriesID)
+
+	series, err := h.getSeriesByIDInternal(seriesID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener la serie creada")
+		return
+	}
+	series.EventIDs = input.EventIDs
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(series)
+}
+
+// UpdateSeries actualiza un festival o ciclo.
+// Si se envía event_ids, reemplaza el conjunto de eventos vinculados.
+//
+// @Summary Actualizar serie
+// @Description Actualiza los datos de una serie y, opcionalmente, sus eventos
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la serie"
+// @Param series body Series true "Datos actualizados"
+// @Security BearerAuth
+// @Success 200 {object} Series "Serie actualizada"
+// @Failure 400 {string} string "Error en la solicitud"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Serie no encontrada"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Router /series/{id} [put]
+func (h *AuthHandler) UpdateSeries(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+
+	seriesID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	var input struct {
+		Series
+		EventIDs *[]int `json:"event_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar el cuerpo")
+		return
+	}
+	if input.Title == "" || input.Slug == "" {
+		writeError(w, r, http.StatusBadRequest, "El título y el slug son obligatorios")
+		return
+	}
+	if input.Type == "" {
+		input.Type = "festival"
+	}
+	if !validSeriesTypes[input.Type] {
+		writeError(w, r, http.StatusBadRequest, "Tipo de serie inválido (festival o cycle)")
+		return
+	}
+
+	prevSlug := h.currentSlug("series", strconv.Itoa(seriesID))
+	var yearHint string
+	if start, err := parseEventDate(input.DateStart); err == nil {
+		yearHint = strconv.Itoa(start.Year())
+	}
+	slug, err := h.updateSlug("series", prevSlug, input.Slug, input.Title, yearHint)
+	if err != nil {
+		writeSlugError(w, r, err)
+		return
+	}
+	input.Slug = slug
+	defer h.releaseSlug("series", slug)
+
+	affected, err := h.DB.Update(false, `
+		UPDATE event_series
+		SET type = ?, title = ?, slug = ?, description = ?, image = ?, date_start = ?, date_end = ?
+		WHERE id = ?`,
+		input.Type, input.Title, input.Slug, input.Description, nullableString(input.Image),
+		nullableString(input.DateStart), nullableString(input.DateEnd), seriesID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al actualizar la serie", err)
+		return
+	}
+	if affected == 0 {
+		if _, err := h.getSeriesByIDInternal(seriesID); err != nil {
+			writeError(w, r, http.StatusNotFound, "Serie no encontrada")
+			return
+		}
+	}
+	h.recordSlugChange("series", prevSlug, input.Slug)
+
+	if input.EventIDs != nil {
+		_, _ = h.DB.Update(false, "UPDATE events SET id_series = NULL WHERE id_series = ?", seriesID)
+		h.assignSeriesEvents(seriesID, *input.EventIDs)
+	}
+	h.refreshSeriesDates(seriesID)
+
+	series, err := h.getSeriesByIDInternal(seriesID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "Serie no encontrada")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(series)
+}
+
+// DeleteSeries elimina una serie. Los eventos no se borran, solo se desvinculan.
+//
+// @Summary Eliminar serie
+// @Description Elimina un festival o ciclo y desvincula sus eventos
+// @Tags series
+// @Param id path int true "ID de la serie"
+// @Security BearerAuth
+// @Success 204 "Serie eliminada"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Serie no encontrada"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Router /series/{id} [delete]
+func (h *AuthHandler) DeleteSeries(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+
+	// La FK de events.id_series (ON DELETE SET NULL) desvincula los eventos
+	affected, err := h.DB.Delete(false, "DELETE FROM event_series WHERE id = ?", chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al eliminar la serie", err)
+		return
+	}
+	if affected == 0 {
+		writeError(w, r, http.StatusNotFound, "Serie no encontrada")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSeriesLineup devuelve el lineup combinado de la serie: todas las bandas
+// de todos sus eventos, con las fechas y venues en las que se presentan.
+//
+// @Summary Lineup de una serie
+// @Description Lista todas las bandas de los eventos de la serie, sin repetir
+// @Tags series
+// @Produce json
+// @Param id path string true "ID o slug de la serie"
+// @Success 200 {array} SeriesLineupEntry "Lineup combinado"
+// @Failure 404 {string} string "Serie no encontrada"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Router /series/{id}/lineup [get]
+func (h *AuthHandler) GetSeriesLineup(w http.ResponseWriter, r *http.Request) {
+	seriesID, err := h.resolveSeriesID(chi.URLParam(r, "id"))
+	if err != nil {
+		if h.redirectOldSlug(w, r, "series", chi.URLParam(r, "id")) {
+			return
+		}
+		writeError(w, r, http.StatusNotFound, "Serie no encontrada")
+		return
+	}
+
+	rows, err := h.DB.Select(`
+		SELECT b.id, b.name, b.slug, e.id, e.title, e.slug, e.date_start, e.date_end, v.name
+		FROM events e
+		JOIN events_bands eb ON eb.id_event = e.id
+		JOIN bands b ON b.id = eb.id_band
+		JOIN venues v ON v.id = e.id_venue
+		WHERE e.id_series = ?
+		ORDER BY b.name ASC, e.date_start ASC`, seriesID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener el lineup")
+		return
+	}
+	defer rows.Close()
+
+	lineup := []SeriesLineupEntry{}
+	index := map[int]int{}
+	for rows.Next() {
+		var b Band
+		var e EventConflict
+		var dateEnd sql.NullString
+		var venueName string
+		if err := rows.Scan(&b.ID, &b.Name, &b.Slug, &e.ID, &e.Title, &e.Slug, &e.DateStart, &dateEnd, &venueName); err != nil {
+			writeError(w, r, http.StatusInternalServerError, "Error al leer el lineup")
+			return
+		}
+		e.DateEnd = dateEnd.String
+
+		pos, ok := index[b.ID]
+		if !ok {
+			lineup = append(lineup, SeriesLineupEntry{Band: b, Events: []EventConflict{}, Venues: []string{}})
+			pos = len(lineup) - 1
+			index[b.ID] = pos
+		}
+		lineup[pos].Events = append(lineup[pos].Events, e)
+
+		// Venues sin repetir
+		found := false
+		for _, name := range lineup[pos].Venues {
+			if name == venueName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			lineup[pos].Venues = append(lineup[pos].Venues, venueName)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lineup)
+}
+
+// escapeICalText escapa un texto según RFC 5545
+func escapeICalText(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, ";", `\;`)
+	text = strings.ReplaceAll(text, ",", `\,`)
+	text = strings.ReplaceAll(text, "\r\n", `\n`)
+	text = strings.ReplaceAll(text, "\n", `\n`)
+	return text
+}
+
+// foldICalLine corta las líneas largas en bloques de 75 bytes, como pide el formato iCalendar
+func foldICalLine(line string) string {
+	if len(line) <= 75 {
+		return line + "\r\n"
+	}
+	var b strings.Builder
+	current := 0
+	for _, r := range line {
+		size := len(string(r))
+		if current+size > 75 {
+			b.WriteString("\r\n ")
+			current = 1
+		}
+		b.WriteRune(r)
+		current += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// icalTime convierte una fecha local de evento (hora de Argentina) a UTC en formato iCalendar
+func icalTime(t time.Time) string {
+	local := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, argentinaTZ)
+	return local.UTC().Format("20060102T150405Z")
+}
+
+// buildICal arma un calendario iCalendar con los eventos indicados
+func buildICal(calendarName string, events []Event) string {
+	var b strings.Builder
+	write := func(line string) { b.WriteString(foldICalLine(line)) }
+
+	write("BEGIN:VCALENDAR")
+	write("VERSION:2.0")
+	write("PRODID:-//Brote Colectivo//Agenda Cultural//ES")
+	write("CALSCALE:GREGORIAN")
+	write("METHOD:PUBLISH")
+	write("X-WR-CALNAME:" + escapeICalText(calendarName))
+	write("X-WR-TIMEZONE:America/Argentina/Buenos_Aires")
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for _, e := range events {
+		start, end, err := eventTimeRange(e.DateStart, e.DateEnd)
+		if err != nil {
+			continue
+		}
+
+		location := ""
+		if e.Venue != nil {
+			location = e.Venue.Name
+			if e.Venue.Address != "" {
+				location += ", " + e.Venue.Address
+			}
+			if e.Venue.City != "" {
+				location += ", " + e.Venue.City
+			}
+		}
+
+		var bandNames []string
+		for _, band := range e.Bands {
+			bandNames = append(bandNames, band.Name)
+		}
+		description := cleanHTML(e.Content)
+		if len(bandNames) > 0 {
+			description = "Lineup: " + strings.Join(bandNames, ", ") + "\n\n" + description
+		}
+		if summary := e.EventTicketing.PriceSummary(); summary != "" {
+			description += "\n\n" + summary
+		}
+
+		write("BEGIN:VEVENT")
+		write(fmt.Sprintf("UID:event-%d@brotecolectivo.com", e.ID))
+		write("DTSTAMP:" + stamp)
+		write("DTSTART:" + icalTime(start))
+		write("DTEND:" + icalTime(end))
+		write("SUMMARY:" + escapeICalText(e.Title))
+		if location != "" {
+			write("LOCATION:" + escapeICalText(location))
+		}
+		write("DESCRIPTION:" + escapeICalText(description))
+		write("URL:https://brotecolectivo.com/agenda-cultural/" + e.Slug)
+		write("END:VEVENT")
+	}
+
+	write("END:VCALENDAR")
+	return b.String()
+}
+
+// GetSeriesICal devuelve todos los eventos de la serie en formato iCalendar.
+//
+// @Summary Calendario de una serie
+// @Description Exporta los eventos de la serie como archivo .ics
+// @Tags series
+// @Produce text/calendar
+// @Param id path string true "ID o slug de la serie"
+// @Success 200 {string} string "Archivo iCalendar"
+// @Failure 404 {string} string "Serie no encontrada"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Router /series/{id}/ical [get]
+func (h *AuthHandler) GetSeriesICal(w http.ResponseWriter, r *http.Request) {
+	seriesID, err := h.resolveSeriesID(chi.URLParam(r, "id"))
+	if err != nil {
+		if h.redirectOldSlug(w, r, "series", chi.URLParam(r, "id")) {
+			return
+		}
+		writeError(w, r, http.StatusNotFound, "Serie no encontrada")
+		return
+	}
+
+	series, err := h.getSeriesByIDInternal(seriesID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "Serie no encontrada")
+		return
+	}
+
+	events, err := h.getSeriesEvents(seriesID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener los eventos de la serie")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.ics"`, series.Slug))
+	io.WriteString(w, buildICal(series.Title, events))
+}
+
+// UploadSeriesImage sube la imagen de una serie a DigitalOcean Spaces.
+//
+// @Summary Subir imagen de serie
+// @Description Sube la imagen de un festival o ciclo
+// @Tags series
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Archivo de imagen"
+// @Param slug formData string true "Slug de la serie"
+// @Security BearerAuth
+// @Success 201 {object} map[string]string "URL de la imagen"
+// @Failure 400 {string} string "Error en la solicitud"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Router /series/upload-image [post]
+func (h *AuthHandler) UploadSeriesImage(w http.ResponseWriter, r *http.Request) {
+	r.ParseMultipartForm(10 << 20)
+	file, handler, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "No se pudo leer el archivo")
+		return
+	}
+	defer file.Close()
+
+	slug := r.FormValue("slug")
+	if slug == "" {
+		writeError(w, r, http.StatusBadRequest, "Slug faltante")
+		return
+	}
+
+	tempFile, err := os.CreateTemp("", "upload-*.jpg")
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "No se pudo crear archivo temporal")
+		return
+	}
+	defer os.Remove(tempFile.Name())
+	io.Copy(tempFile, file)
+
+	objectPath := "series/" + slug + ".jpg"
+	if err := uploadToSpaces(tempFile.Name(), objectPath, handler.Header.Get("Content-Type")); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al subir imagen", err)
+		return
+	}
+
+	imageURL := objectPath
+	if cfg, err := ini.Load("data.conf"); err == nil {
+		if mediaURL := cfg.Section("spaces").Key("media_url").String(); mediaURL != "" {
+			imageURL = mediaURL + "/" + objectPath
+		}
+	}
+	_, _ = h.DB.Update(false, "UPDATE event_series SET image = ? WHERE slug = ?", imageURL, slug)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Imagen subida con éxito", "image": imageURL})
+}
+
+// seriesCaption arma el texto del posteo de una serie con fechas, venues y lineup
+func seriesCaption(series *Series, events []Event) string {
+	var b strings.Builder
+	b.WriteString("🎪 " + series.Title + "\n\n")
+
+	for _, e := range events {
+		date := e.DateStart
+		if start, err := parseEventDate(e.DateStart); err == nil {
+			date = fmt.Sprintf("%s %d de %s", getSpanishWeekday(start.Weekday()), start.Day(), getSpanishMonth(start.Month()))
+		}
+		venueName := "Lugar a confirmar"
+		if e.Venue != nil {
+			venueName = e.Venue.Name
+		}
+		b.WriteString(fmt.Sprintf("📅 %s · 📍 %s\n", date, venueName))
+
+		var names []string
+		for _, band := range e.Bands {
+			names = append(names, band.Name)
+		}
+		if len(names) > 0 {
+			b.WriteString("🎵 " + strings.Join(names, ", ") + "\n")
+		}
+	}
+
+	if description := cleanHTML(series.Description); description != "" {
+		b.WriteString("\n" + description + "\n")
+	}
+	b.WriteString("\n#BroteColectivo #AgendaCulturalBroteColectivo #AgendaCultural #Festival #Música")
+	return b.String()
+}
+
+// publishSeriesToInstagram publica la serie en el feed de Instagram con su imagen y lineup
+func (h *AuthHandler) publishSeriesToInstagram(seriesID int) error {
+	cfg, err := ini.Load("data.conf")
+	if err != nil {
+		return fmt.Errorf("error al cargar configuración: %v", err)
+	}
+
+	accessToken := cfg.Section("instagram").Key("access_token").String()
+	businessID := cfg.Section("instagram").Key("business_id").String()
+	mediaURL := cfg.Section("spaces").Key("media_url").String()
+
+	series, err := h.getSeriesByIDInternal(seriesID)
+	if err != nil {
+		return fmt.Errorf("error al obtener la serie: %v", err)
+	}
+	events, err := h.getSeriesEvents(seriesID)
+	if err != nil {
+		return fmt.Errorf("error al obtener los eventos de la serie: %v", err)
+	}
+
+	imageURL := series.Image
+	if imageURL == "" {
+		imageURL = fmt.Sprintf("%s/series/%s.jpg", mediaURL, series.Slug)
+	}
+	caption := seriesCaption(series, events)
+
+	// Crear media container
+	feedURL := fmt.Sprintf("https://graph.facebook.com/v21.0/%s/media?image_url=%s&caption=%s&access_token=%s",
+		businessID, url.QueryEscape(imageURL), url.QueryEscape(caption), accessToken)
+
+	feedRes, err := http.Post(feedURL, "application/json", nil)
+	if err != nil {
+		return fmt.Errorf("error al hacer POST del feed: %v", err)
+	}
+	defer feedRes.Body.Close()
+
+	var feedData struct {
+		ID    string `json:"id"`
+		Error struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+			Code    int    `json:"code"`
+		} `json:"error"`
+	}
+	body, _ := io.ReadAll(feedRes.Body)
+	if err := json.Unmarshal(body, &feedData); err != nil {
+		return fmt.Errorf("error al decodificar respuesta del feed: %v", err)
+	}
+	if feedData.ID == "" {
+		return fmt.Errorf("error en publicación: %s (Tipo: %s, Código: %d)", feedData.Error.Message, feedData.Error.Type, feedData.Error.Code)
+	}
+
+	// Publicar en el feed
+	publishURL := fmt.Sprintf("https://graph.facebook.com/v21.0/%s/media_publish?creation_id=%s&access_token=%s",
+		businessID, feedData.ID, accessToken)
+
+	publishRes, err := http.Post(publishURL, "application/json", nil)
+	if err != nil {
+		return fmt.Errorf("error al publicar en feed: %v", err)
+	}
+	defer publishRes.Body.Close()
+
+	if publishRes.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(publishRes.Body)
+		return fmt.Errorf("error al publicar en feed: %s", string(body))
+	}
+
+	return nil
+}
+
+// PublishSeriesToSocial publica una serie en redes sociales (solo administradores).
+//
+// @Summary Publicar serie en redes
+// @Description Publica el festival o ciclo en Instagram con su lineup completo
+// @Tags series
+// @Produce json
+// @Param id path int true "ID de la serie"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Resultado de la publicación"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Serie no encontrada"
+// @Failure 502 {string} string "Error al publicar"
+// @Router /series/{id}/publish-social [post]
+func (h *AuthHandler) PublishSeriesToSocial(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+
+	seriesID, err := h.resolveSeriesID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "Serie no encontrada")
+		return
+	}
+
+	if err := h.renewInstagramToken(); err != nil {
+		fmt.Printf("[Warning] No se pudo renovar el token de Instagram: %v\n", err)
+	}
+
+	err = h.publishSeriesToInstagram(seriesID)
+	if err != nil {
+		h.LogSocialActivity(seriesID, "series", false, err.Error())
+		writeError(w, r, http.StatusBadGateway, "Error al publicar la serie", err)
+		return
+	}
+	h.LogSocialActivity(seriesID, "series", true, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "id": seriesID})
+}
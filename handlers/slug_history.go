@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Tablas de las entidades que se acceden por slug. Los nombres son fijos: nunca vienen del cliente.
+var slugEntityTables = map[string]string{
+	"band":   "bands",
+	"event":  "events",
+	"news":   "news",
+	"venue":  "venues",
+	"series": "event_series",
+	"song":   "songs",
+	"video":  "videos",
+}
+
+// slugRef es el ID y el slug vigente de un registro antes de modificarlo
+type slugRef struct {
+	ID   int
+	Slug string
+}
+
+// currentSlug obtiene el ID y el slug actual de un registro a partir de su ID o su slug
+func (h *AuthHandler) currentSlug(entityType, idOrSlug string) slugRef {
+	var ref slugRef
+	table, ok := slugEntityTables[entityType]
+	if !ok {
+		return ref
+	}
+
+	column := "slug"
+	if isNumeric(idOrSlug) {
+		column = "id"
+	}
+	row, err := h.DB.SelectRow("SELECT id, slug FROM "+table+" WHERE "+column+" = ?", idOrSlug)
+	if err == nil {
+		row.Scan(&ref.ID, &ref.Slug)
+	}
+	return ref
+}
+
+// recordSlugChange guarda el slug anterior en slug_history si el registro cambió de slug.
+// Si el slug nuevo había sido usado antes, deja de redirigir porque vuelve a ser canónico.
+func (h *AuthHandler) recordSlugChange(entityType string, prev slugRef, newSlug string) {
+	newSlug = strings.TrimSpace(newSlug)
+	if prev.ID == 0 || prev.Slug == "" || newSlug == "" || prev.Slug == newSlug {
+		return
+	}
+
+	if _, err := h.DB.Delete(false, "DELETE FROM slug_history WHERE entity_type = ? AND old_slug = ?",
+		entityType, newSlug); err != nil {
+		fmt.Printf("[Warning] No se pudo limpiar slug_history para %s %q: %v\n", entityType, newSlug, err)
+	}
+
+	_, err := h.DB.Exec(`
+		INSERT INTO slug_history (entity_type, entity_id, old_slug, created_at)
+		VALUES (?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE entity_id = VALUES(entity_id), created_at = NOW()`,
+		entityType, prev.ID, prev.Slug)
+	if err != nil {
+		fmt.Printf("[Warning] No se pudo registrar el slug anterior de %s #%d: %v\n", entityType, prev.ID, err)
+	}
+}
+
+// canonicalSlug busca un slug viejo en slug_history y devuelve el slug vigente del registro
+func (h *AuthHandler) canonicalSlug(entityType, oldSlug string) (string, bool) {
+	table, ok := slugEntityTables[entityType]
+	if !ok || oldSlug == "" || isNumeric(oldSlug) {
+		return "", false
+	}
+
+	row, err := h.DB.SelectRow(`
+		SELECT t.slug FROM slug_history sh
+		JOIN `+table+` t ON t.id = sh.entity_id
+		WHERE sh.entity_type = ? AND sh.old_slug = ?`,
+		entityType, oldSlug)
+	if err != nil {
+		return "", false
+	}
+	var slug string
+	if err := row.Scan(&slug); err != nil || slug == "" || slug == oldSlug {
+		return "", false
+	}
+	return slug, true
+}
+
+// redirectOldSlug responde 301 hacia el slug vigente si oldSlug es un slug anterior de la entidad.
+// Devuelve false si no hay redirección, para que el handler siga con su 404 habitual.
+func (h *AuthHandler) redirectOldSlug(w http.ResponseWriter, r *http.Request, entityType, oldSlug string) bool {
+	slug, ok := h.canonicalSlug(entityType, oldSlug)
+	if !ok {
+		return false
+	}
+
+	// Reemplazar el segmento del slug viejo en la ruta pedida, conservando el resto (/lineup, /ical, etc.)
+	segments := strings.Split(r.URL.Path, "/")
+	for i, segment := range segments {
+		if segment == oldSlug {
+			segments[i] = slug
+			break
+		}
+	}
+	location := strings.Join(segments, "/")
+	if r.URL.RawQuery != "" {
+		location += "?" + r.URL.RawQuery
+	}
+
+	w.Header().Set("Location", location)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusMovedPermanently)
+	json.NewEncoder(w).Encode(map[string]string{
+		"entity_type": entityType,
+		"old_slug":    oldSlug,
+		"slug":        slug,
+		"location":    location,
+	})
+	return true
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Largo máximo de un slug generado (sin contar el sufijo)
+const maxSlugLength = 80
+
+// Cuánto dura la reserva de un slug mientras se crea el registro
+const slugReservationMinutes = 10
+
+// Cantidad de sufijos numéricos que se prueban antes de rendirse
+const maxSlugAttempts = 50
+
+// Letras que la descomposición Unicode no resuelve por sí sola
+var slugTransliterations = strings.NewReplacer(
+	"ñ", "n", "Ñ", "n",
+	"æ", "ae", "Æ", "ae",
+	"œ", "oe", "Œ", "oe",
+	"ß", "ss",
+	"ø", "o", "Ø", "o",
+	"ł", "l", "Ł", "l",
+	"&", " y ",
+	"@", " arroba ",
+)
+
+// slugify convierte un texto en slug: minúsculas, sin tildes, con guiones.
+// Ej: "Ñandú & Los Pájaros" -> "nandu-y-los-pajaros"
+func slugify(text string) string {
+	text = slugTransliterations.Replace(text)
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if folded, _, err := transform.String(t, text); err == nil {
+		text = folded
+	}
+
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+
+	// Cortar en el último guion para no dejar palabras por la mitad
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		if i := strings.LastIndex(slug, "-"); i > maxSlugLength/2 {
+			slug = slug[:i]
+		}
+		slug = strings.Trim(slug, "-")
+	}
+	return slug
+}
+
+// slugCandidates devuelve los slugs a probar en orden: base, base-hint, base-2, base-3...
+func slugCandidates(base, hint string) []string {
+	candidates := []string{base}
+	if hint = slugify(hint); hint != "" && !strings.HasSuffix(base, "-"+hint) {
+		candidates = append(candidates, base+"-"+hint)
+	}
+	for i := 2; len(candidates) < maxSlugAttempts; i++ {
+		candidates = append(candidates, base+"-"+strconv.Itoa(i))
+	}
+	return candidates
+}
+
+// slugTaken indica si un slug está en uso por la entidad, por una redirección o por una reserva vigente
+func (h *AuthHandler) slugTaken(entityType, slug string) (bool, error) {
+	table, ok := slugEntityTables[entityType]
+	if !ok {
+		return false, fmt.Errorf("tipo de entidad no soportado: %s", entityType)
+	}
+
+	row, err := h.DB.SelectRow(`
+		SELECT EXISTS(SELECT 1 FROM `+table+` WHERE slug = ?)
+		    OR EXISTS(SELECT 1 FROM slug_history WHERE entity_type = ? AND old_slug = ?)
+		    OR EXISTS(SELECT 1 FROM slug_reservations WHERE entity_type = ? AND slug = ? AND expires_at > NOW())`,
+		slug, entityType, slug, entityType, slug)
+	if err != nil {
+		return false, err
+	}
+	var taken bool
+	if err := row.Scan(&taken); err != nil {
+		return false, err
+	}
+	return taken, nil
+}
+
+// reserveSlug reserva el primer slug libre a partir de base. La clave primaria de
+// slug_reservations garantiza que dos pedidos simultáneos no se lleven el mismo slug.
+func (h *AuthHandler) reserveSlug(entityType, base, hint string) (string, error) {
+	if base == "" {
+		return "", fieldError("slug", "required", "falta el slug o un nombre para generarlo")
+	}
+
+	if _, err := h.DB.Delete(false, "DELETE FROM slug_reservations WHERE expires_at <= NOW()"); err != nil {
+		fmt.Printf("[Warning] No se pudieron limpiar reservas de slugs vencidas: %v\n", err)
+	}
+
+	for _, candidate := range slugCandidates(base, hint) {
+		taken, err := h.slugTaken(entityType, candidate)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			continue
+		}
+		_, err = h.DB.Exec(`
+			INSERT INTO slug_reservations (entity_type, slug, expires_at)
+			VALUES (?, ?, DATE_ADD(NOW(), INTERVAL ? MINUTE))`,
+			entityType, candidate, slugReservationMinutes)
+		if err == nil {
+			return candidate, nil
+		}
+		// Otro pedido lo reservó entre la verificación y el INSERT: probar el siguiente
+	}
+	return "", fmt.Errorf("no se encontró un slug libre para %q", base)
+}
+
+// releaseSlug libera la reserva una vez que el registro ya se guardó con ese slug
+func (h *AuthHandler) releaseSlug(entityType, slug string) {
+	_, _ = h.DB.Delete(false, "DELETE FROM slug_reservations WHERE entity_type = ? AND slug = ?", entityType, slug)
+}
+
+// assignSlug normaliza el slug pedido (o lo genera desde el nombre) y reserva uno libre.
+// hint es un sufijo legible que se prueba antes de los numéricos (ciudad, año, etc.).
+func (h *AuthHandler) assignSlug(entityType, requested, name, hint string) (string, error) {
+	base := slugify(requested)
+	if base == "" {
+		base = slugify(name)
+	}
+	return h.reserveSlug(entityType, base, hint)
+}
+
+// updateSlug es assignSlug para ediciones: si no se envía slug o no cambia se conserva el actual,
+// y un slug viejo del mismo registro se puede recuperar. Si otro registro lo usa, se le agrega sufijo.
+func (h *AuthHandler) updateSlug(entityType string, prev slugRef, requested, name, hint string) (string, error) {
+	base := slugify(requested)
+	if prev.ID == 0 {
+		return h.assignSlug(entityType, requested, name, hint)
+	}
+	if base == "" || base == prev.Slug {
+		return prev.Slug, nil
+	}
+
+	row, err := h.DB.SelectRow(`
+		SELECT EXISTS(SELECT 1 FROM slug_history WHERE entity_type = ? AND old_slug = ? AND entity_id = ?)`,
+		entityType, base, prev.ID)
+	if err != nil {
+		return "", err
+	}
+	var own bool
+	if err := row.Scan(&own); err != nil {
+		return "", err
+	}
+	if own {
+		return base, nil
+	}
+	return h.reserveSlug(entityType, base, hint)
+}
+
+// writeSlugError responde 400 si faltaban datos para el slug, o 500 si falló la base
+func writeSlugError(w http.ResponseWriter, r *http.Request, err error) {
+	var fields validationErrors
+	if errors.As(err, &fields) {
+		writeError(w, r, http.StatusBadRequest, "No se pudo generar el slug", err)
+		return
+	}
+	writeError(w, r, http.StatusInternalServerError, "Error al generar el slug", err)
+}
+
+// suggestSlugs devuelve hasta limit alternativas libres sin reservarlas
+func (h *AuthHandler) suggestSlugs(entityType, base, hint string, limit int) ([]string, error) {
+	suggestions := []string{}
+	for _, candidate := range slugCandidates(base, hint) {
+		if len(suggestions) >= limit {
+			break
+		}
+		taken, err := h.slugTaken(entityType, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if !taken {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+	return suggestions, nil
+}
+
+// CheckSlug verifica si un slug está disponible para un tipo de entidad y sugiere alternativas.
+//
+// @Summary Verificar slug
+// @Description Normaliza el slug, indica si está disponible y sugiere alternativas legibles. Los slugs anteriores (redirecciones) cuentan como ocupados.
+// @Tags slugs
+// @Produce json
+// @Param type query string true "Tipo de entidad (band, event, news, venue, series, song, video)"
+// @Param slug query string false "Slug deseado"
+// @Param name query string false "Nombre o título, si no se envía slug"
+// @Param hint query string false "Sufijo preferido para la primera alternativa (ciudad, año)"
+// @Success 200 {object} map[string]interface{} "Disponibilidad y sugerencias"
+// @Failure 400 {string} string "Parámetros inválidos"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Router /slugs/check [get]
+func (h *AuthHandler) CheckSlug(w http.ResponseWriter, r *http.Request) {
+	entityType := r.URL.Query().Get("type")
+	if _, ok := slugEntityTables[entityType]; !ok {
+		writeError(w, r, http.StatusBadRequest, "Tipo de entidad inválido")
+		return
+	}
+
+	requested := r.URL.Query().Get("slug")
+	slug := slugify(requested)
+	if slug == "" {
+		slug = slugify(r.URL.Query().Get("name"))
+	}
+	if slug == "" {
+		writeError(w, r, http.StatusBadRequest, "Falta el slug o el nombre")
+		return
+	}
+
+	taken, err := h.slugTaken(entityType, slug)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al consultar la base de datos")
+		return
+	}
+
+	suggestions := []string{}
+	if taken {
+		suggestions, err = h.suggestSlugs(entityType, slug, r.URL.Query().Get("hint"), 3)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "Error al consultar la base de datos")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"type":        entityType,
+		"requested":   requested,
+		"slug":        slug,
+		"available":   !taken,
+		"suggestions": suggestions,
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -46,30 +47,59 @@ func (h *AuthHandler) GetLyricsByID(w http.ResponseWriter, r *http.Request) {
 	var l Lyrics
 	err := row.Scan(&l.ID, &l.Lyric, &l.IDSong, &l.Author)
 	if err != nil {
-		http.Error(w, "Letra no encontrada", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, "Letra no encontrada")
 		return
 	}
 	json.NewEncoder(w).Encode(l)
 }
 
-func (h *AuthHandler) GetSongs(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.DB.Select(`
-		SELECT s.id, s.title, s.slug, s.id_band, s.id_genre,
-		       b.id, b.name, b.slug,
-		       g.id, g.name, l.id
-		FROM songs s
+// songListSpec declara los filtros y órdenes del listado de canciones
+var songListSpec = &listSpec{
+	from: `songs s
 		LEFT JOIN bands b ON s.id_band = b.id
 		LEFT JOIN genres g ON s.id_genre = g.id
-		LEFT JOIN lyrics l ON s.id = l.id_song
-	`)
+		LEFT JOIN lyrics l ON s.id = l.id_song`,
+	id:     column("s.id"),
+	search: columns("s.title", "s.slug", "b.name"),
+	filters: map[string]filterField{
+		"id":    numberFilter("s.id"),
+		"slug":  textFilter("s.slug"),
+		"band":  enumFilter("s.id_band"),
+		"genre": enumFilter("s.id_genre"),
+		"title": textFilter("s.title"),
+	},
+	sorts: map[string]string{"id": column("s.id"), "title": column("s.title")},
+}
+
+func (h *AuthHandler) GetSongs(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePageRequest(r, maxPageLimit)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+
+	songs, page, err := h.querySongs(r, p)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeListError(w, r, err)
 		return
 	}
-	defer rows.Close()
+
+	writePageHeaders(w, r, page)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(songs)
+}
+
+// querySongs lee una página de canciones con su banda y su género
+func (h *AuthHandler) querySongs(r *http.Request, p *pageRequest) ([]Song, *pageInfo, error) {
+	q, err := songListSpec.query(r, `s.id, s.title, s.slug, s.id_band, s.id_genre,
+		       b.id, b.name, b.slug,
+		       g.id, g.name, l.id`)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	var songs []Song
-	for rows.Next() {
+	page, err := h.queryPage(q, p, func(scan func(dest ...interface{}) error) error {
 		var s Song
 		var bID sql.NullInt64
 		var bName, bSlug sql.NullString
@@ -77,15 +107,13 @@ func (h *AuthHandler) GetSongs(w http.ResponseWriter, r *http.Request) {
 		var gName sql.NullString
 		var lID sql.NullInt64
 
-		err := rows.Scan(
+		err := scan(
 			&s.ID, &s.Title, &s.Slug, &s.BandID, &s.GenreID,
 			&bID, &bName, &bSlug,
 			&gID, &gName, &lID,
 		)
-
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+			return err
 		}
 		if bID.Valid {
 			s.Band = &Band{
@@ -107,9 +135,9 @@ func (h *AuthHandler) GetSongs(w http.ResponseWriter, r *http.Request) {
 		}
 
 		songs = append(songs, s)
-	}
-
-	json.NewEncoder(w).Encode(songs)
+		return nil
+	})
+	return songs, page, err
 }
 
 func (h *AuthHandler) GetSongByID(w http.ResponseWriter, r *http.Request) {
@@ -141,7 +169,10 @@ func (h *AuthHandler) GetSongByID(w http.ResponseWriter, r *http.Request) {
 		&g.ID, &g.Name,
 	)
 	if err != nil {
-		http.Error(w, "Cancion no encontrada", http.StatusNotFound)
+		if h.redirectOldSlug(w, r, "song", idOrSlug) {
+			return
+		}
+		writeError(w, r, http.StatusNotFound, "Cancion no encontrada")
 		return
 	}
 
@@ -153,16 +184,24 @@ func (h *AuthHandler) GetSongByID(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) CreateSong(w http.ResponseWriter, r *http.Request) {
 	var s Song
 	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+	slug, err := h.assignSlug("song", s.Slug, s.Title, "")
+	if err != nil {
+		writeSlugError(w, r, err)
 		return
 	}
+	s.Slug = slug
+	defer h.releaseSlug("song", slug)
 	lastID, err := h.DB.Insert(true, "INSERT INTO songs (title, slug, id_band, id_genre) VALUES (?, ?, ?, ?)",
 		s.Title, s.Slug, s.BandID, s.GenreID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	s.ID = int(lastID)
+	h.reindexSearch("song", s.ID)
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(s)
 }
@@ -171,9 +210,17 @@ func (h *AuthHandler) UpdateSong(w http.ResponseWriter, r *http.Request) {
 	idOrSlug := chi.URLParam(r, "id")
 	var s Song
 	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+	prevSlug := h.currentSlug("song", idOrSlug)
+	slug, err := h.updateSlug("song", prevSlug, s.Slug, s.Title, "")
+	if err != nil {
+		writeSlugError(w, r, err)
 		return
 	}
+	s.Slug = slug
+	defer h.releaseSlug("song", slug)
 
 	query := "UPDATE songs SET title = ?, slug = ?, id_band = ?, id_genre = ? WHERE "
 	args := []interface{}{s.Title, s.Slug, s.BandID, s.GenreID}
@@ -184,11 +231,14 @@ func (h *AuthHandler) UpdateSong(w http.ResponseWriter, r *http.Request) {
 	}
 	args = append(args, idOrSlug)
 
-	_, err := h.DB.Update(true, query, args...)
+	_, err = h.DB.Update(true, query, args...)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
+	h.recordSlugChange("song", prevSlug, s.Slug)
+	h.reindexSearch("song", prevSlug.ID)
+	h.reindexSearchQuery("lyrics", "SELECT id FROM lyrics WHERE id_song = ?", prevSlug.ID)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -200,49 +250,87 @@ func (h *AuthHandler) DeleteSong(w http.ResponseWriter, r *http.Request) {
 	} else {
 		query += "slug = ?"
 	}
+	song := h.currentSlug("song", idOrSlug)
 	_, err := h.DB.Delete(true, query, idOrSlug)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
+	h.removeFromSearch("song", song.ID)
+	h.reindexSearchQuery("lyrics", "SELECT id FROM lyrics WHERE id_song = ?", song.ID)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// songAudioDir guarda los audios subidos hasta que el trabajo song.convert_audio los convierte
+const songAudioDir = "audio-uploads"
+
+// UploadSongAudio recibe el audio de una canción y encola su conversión a mp3; responde con el
+// ID del trabajo sin esperar a ffmpeg.
 func (h *AuthHandler) UploadSongAudio(w http.ResponseWriter, r *http.Request) {
 	r.ParseMultipartForm(10 << 20)
-	file, handler, err := r.FormFile("audio")
+	file, _, err := r.FormFile("audio")
 	if err != nil {
-		http.Error(w, "Error al leer el archivo", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Error al leer el archivo")
 		return
 	}
 	defer file.Close()
 
 	slug := chi.URLParam(r, "id")
-	tempFile, err := os.CreateTemp("", "upload-*.input")
+	if err := os.MkdirAll(songAudioDir, 0755); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "No se pudo guardar el archivo", err)
+		return
+	}
+	inputFile, err := os.CreateTemp(songAudioDir, slug+"-*.input")
 	if err != nil {
-		http.Error(w, "No se pudo crear archivo temporal", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se pudo guardar el archivo", err)
 		return
 	}
-	defer os.Remove(tempFile.Name())
-	io.Copy(tempFile, file)
-
-	outputPath := fmt.Sprintf("converted-%s.mp3", slug)
-	cmd := exec.Command("ffmpeg", "-i", tempFile.Name(), "-codec:a", "libmp3lame", "-qscale:a", "2", outputPath)
-	err = cmd.Run()
+	_, err = io.Copy(inputFile, file)
+	inputFile.Close()
 	if err != nil {
-		http.Error(w, "Error al convertir a mp3", http.StatusInternalServerError)
+		os.Remove(inputFile.Name())
+		writeError(w, r, http.StatusInternalServerError, "No se pudo guardar el archivo", err)
 		return
 	}
-	defer os.Remove(outputPath)
 
-	err = uploadToSpaces(outputPath, fmt.Sprintf("songs/%s.mp3", slug), handler.Header.Get("Content-Type"))
+	jobID, err := EnqueueJob(h.jobs, jobConvertSongAudio, convertSongAudioPayload{
+		Slug:      slug,
+		InputPath: inputFile.Name(),
+	}, 0)
 	if err != nil {
-		http.Error(w, "Error al subir a Spaces: "+err.Error(), http.StatusInternalServerError)
+		os.Remove(inputFile.Name())
+		writeError(w, r, http.StatusInternalServerError, "No se pudo encolar la conversión", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Audio subido con éxito"})
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"message": "Audio recibido, se está convirtiendo", "job_id": jobID})
+}
+
+// convertSongAudio convierte a mp3 el audio subido y lo sube a Spaces. El original se conserva
+// hasta que la conversión sale bien, para poder reintentarla.
+func convertSongAudio(p convertSongAudioPayload) error {
+	outputPath := fmt.Sprintf("converted-%s.mp3", p.Slug)
+	cmd := exec.Command("ffmpeg", "-y", "-i", p.InputPath, "-codec:a", "libmp3lame", "-qscale:a", "2", outputPath)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error al convertir a mp3: %v: %s", err, lastLines(string(out), 5))
+	}
+	defer os.Remove(outputPath)
+
+	if err := uploadToSpaces(outputPath, fmt.Sprintf("songs/%s.mp3", p.Slug), "audio/mpeg"); err != nil {
+		return fmt.Errorf("error al subir a Spaces: %v", err)
+	}
+	os.Remove(p.InputPath)
+	return nil
+}
+
+// lastLines devuelve las últimas n líneas de la salida de un comando, para guardarlas como error
+func lastLines(out string, n int) string {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
 }
 
 func uploadToSpaces(filePath, key, contentType string) error {
@@ -0,0 +1,81 @@
+package handlers
+
+import "strings"
+
+// Palabras vacías del español que no aportan a la búsqueda
+var spanishStopwords = map[string]bool{
+	"a": true, "al": true, "algo": true, "ante": true, "con": true, "contra": true, "como": true,
+	"de": true, "del": true, "desde": true, "donde": true, "el": true, "ella": true, "ellos": true,
+	"en": true, "entre": true, "era": true, "es": true, "esa": true, "ese": true, "esta": true,
+	"este": true, "esto": true, "fue": true, "ha": true, "hay": true, "hasta": true, "la": true,
+	"las": true, "le": true, "les": true, "lo": true, "los": true, "mas": true, "me": true,
+	"mi": true, "muy": true, "nos": true, "o": true, "para": true, "pero": true, "por": true,
+	"que": true, "se": true, "sin": true, "sobre": true, "su": true, "sus": true, "tambien": true,
+	"te": true, "tu": true, "un": true, "una": true, "unas": true, "uno": true, "unos": true,
+	"y": true, "ya": true, "yo": true,
+}
+
+// Sufijos que se quitan, de más largo a más corto dentro de cada paso.
+// Es un stemmer liviano inspirado en Snowball: no busca la raíz lingüística exacta,
+// sino que "canciones" y "canción" o "tocaron" y "tocar" terminen en la misma forma.
+var (
+	// Derivaciones (sustantivos y adjetivos)
+	spanishDerivationalSuffixes = []string{
+		"amientos", "imientos", "aciones", "uciones", "amiento", "imiento", "idades",
+		"adoras", "adores", "ancias", "logias", "mente", "acion", "ucion", "adora",
+		"antes", "ancia", "logia", "istas", "ismos", "ables", "ibles", "idad",
+		"ador", "ante", "anza", "ista", "ismo", "able", "ible", "osos", "osas",
+		"ivos", "ivas", "oso", "osa", "ivo", "iva",
+	}
+	// Terminaciones verbales
+	spanishVerbSuffixes = []string{
+		"aremos", "eremos", "iremos", "abamos", "iamos", "ieron", "aron", "ando",
+		"iendo", "aban", "aria", "eria", "iria", "aste", "iste", "ados", "idos",
+		"adas", "idas", "aba", "ian", "ado", "ido", "ada", "ida", "ara", "iera",
+		"ar", "er", "ir", "an", "en",
+	}
+	// Plurales, género y vocal final
+	spanishResidualSuffixes = []string{"es", "os", "as", "a", "o", "e", "s"}
+)
+
+// Largo mínimo que tiene que quedar después de quitar un sufijo
+const minStemLength = 3
+
+// stripSuffix quita el primer sufijo de la lista que deje una raíz suficientemente larga
+func stripSuffix(word string, suffixes []string, minRemaining int) (string, bool) {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= minRemaining {
+			return word[:len(word)-len(suffix)], true
+		}
+	}
+	return word, false
+}
+
+// stemSpanish reduce una palabra ya normalizada (minúsculas, sin tildes) a su raíz aproximada.
+// Las palabras cortas y los números se devuelven sin cambios.
+func stemSpanish(word string) string {
+	if len(word) <= minStemLength+1 || strings.IndexFunc(word, func(r rune) bool { return r >= '0' && r <= '9' }) >= 0 {
+		return word
+	}
+
+	if stem, ok := stripSuffix(word, spanishDerivationalSuffixes, minStemLength+1); ok {
+		word = stem
+	} else if stem, ok := stripSuffix(word, spanishVerbSuffixes, minStemLength); ok {
+		word = stem
+	}
+	word, _ = stripSuffix(word, spanishResidualSuffixes, minStemLength)
+	return word
+}
+
+// searchTerms normaliza un texto y devuelve sus raíces, sin palabras vacías.
+// Se usa igual para indexar y para consultar, así las dos partes coinciden.
+func searchTerms(text string) []string {
+	var terms []string
+	for _, word := range strings.Fields(foldForMatch(text)) {
+		if spanishStopwords[word] {
+			continue
+		}
+		terms = append(terms, stemSpanish(word))
+	}
+	return terms
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"brotecolectivo/models"
+)
+
+// Cuánto dura un ticket sin usar: alcanza para abrir el EventSource apenas se pide
+const streamTicketTTL = 30 * time.Second
+
+var errStreamTicketInvalid = errors.New("ticket inválido, vencido o ya usado")
+
+func hashStreamTicket(ticket string) string {
+	sum := sha256.Sum256([]byte(ticket))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateStreamTicket entrega un ticket de un solo uso para abrir /submissions/stream. El token JWT
+// no viaja en la URL, donde quedaría en los logs de acceso y de los proxies.
+//
+// @Summary Ticket para el flujo de submissions
+// @Description Devuelve un ticket que vence a los 30 segundos y sirve para una sola conexión: GET /submissions/stream?ticket=
+// @Tags submissions
+// @Produce json
+// @Success 201 {object} map[string]interface{} "ticket y expires_in (segundos)"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /stream-tickets [post]
+func (h *AuthHandler) CreateStreamTicket(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al generar el ticket", err)
+		return
+	}
+	ticket := hex.EncodeToString(buf)
+
+	_, _ = h.DB.Delete(false, "DELETE FROM stream_tickets WHERE expires_at <= NOW()")
+	_, err := h.DB.Exec(`
+		INSERT INTO stream_tickets (ticket_hash, user_id, role, expires_at)
+		VALUES (?, ?, ?, DATE_ADD(NOW(), INTERVAL ? SECOND))`,
+		hashStreamTicket(ticket), claims.UserID, claims.Role, int(streamTicketTTL.Seconds()))
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al guardar el ticket", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ticket":     ticket,
+		"expires_in": int(streamTicketTTL.Seconds()),
+	})
+}
+
+// ConsumeStreamTicket valida un ticket y lo borra, así no se puede usar dos veces
+func (h *AuthHandler) ConsumeStreamTicket(ticket string) (*models.Claims, error) {
+	hash := hashStreamTicket(ticket)
+	row, err := h.DB.SelectRow("SELECT user_id, role FROM stream_tickets WHERE ticket_hash = ? AND expires_at > NOW()", hash)
+	if err != nil {
+		return nil, err
+	}
+	claims := &models.Claims{}
+	if err := row.Scan(&claims.UserID, &claims.Role); err != nil {
+		return nil, errStreamTicketInvalid
+	}
+
+	// Si dos conexiones usan el mismo ticket a la vez, solo una llega a borrarlo
+	affected, err := h.DB.Delete(false, "DELETE FROM stream_tickets WHERE ticket_hash = ?", hash)
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, errStreamTicketInvalid
+	}
+	return claims, nil
+}
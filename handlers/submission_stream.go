@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"brotecolectivo/models"
+)
+
+const (
+	streamHistory     = 500              // eventos que se guardan para retomar con Last-Event-ID
+	streamBuffer      = 64               // eventos en espera por conexión antes de cortarla
+	streamHeartbeat   = 25 * time.Second // menos que el timeout de lectura habitual de los proxies
+	streamRetryMillis = 5000
+)
+
+// SubmissionStreamEvent es lo que recibe el panel de moderación por /submissions/stream. El tipo
+// de evento (created, approved, rejected, commented) viaja en el campo event de SSE.
+//
+// @Schema
+type SubmissionStreamEvent struct {
+	ID         int    `json:"id"` // ID de la submission
+	Type       string `json:"type"`
+	Status     string `json:"status"`
+	UserID     int    `json:"user_id"`
+	Name       string `json:"name,omitempty"` // nombre o título del contenido propuesto
+	Comment    string `json:"comment,omitempty"`
+	ReviewerID int    `json:"reviewer_id,omitempty"`
+	At         string `json:"at"`
+}
+
+type streamEvent struct {
+	id    int64
+	event string
+	data  []byte
+}
+
+// submissionBroker reparte los cambios de submissions a las conexiones abiertas del panel. Vive
+// en memoria: con varias instancias de la API cada una avisa de los cambios que procesó.
+type submissionBroker struct {
+	mu          sync.Mutex
+	lastID      int64
+	history     []streamEvent
+	subscribers map[chan streamEvent]struct{}
+}
+
+func newSubmissionBroker() *submissionBroker {
+	// Los IDs arrancan en la hora de inicio (ms): un Last-Event-ID de antes de un reinicio queda
+	// por debajo del historial y el cliente recibe reset en lugar de eventos que no son los suyos.
+	return &submissionBroker{lastID: time.Now().UnixMilli(), subscribers: map[chan streamEvent]struct{}{}}
+}
+
+func (b *submissionBroker) publish(event string, data SubmissionStreamEvent) {
+	data.At = time.Now().UTC().Format(time.RFC3339)
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	ev := streamEvent{id: b.lastID, event: event, data: payload}
+	b.history = append(b.history, ev)
+	if len(b.history) > streamHistory {
+		b.history = b.history[len(b.history)-streamHistory:]
+	}
+	for ch := range b.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			// Conexión demasiado lenta: se corta y el navegador reconecta retomando desde su último ID
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe registra una conexión y devuelve los eventos posteriores a lastEventID. reset indica
+// que ese ID ya no está en el historial y el panel tiene que recargar la lista completa.
+func (b *submissionBroker) subscribe(lastEventID int64) (ch chan streamEvent, missed []streamEvent, reset bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch = make(chan streamEvent, streamBuffer)
+	b.subscribers[ch] = struct{}{}
+
+	if lastEventID > 0 && lastEventID < b.lastID {
+		oldest := b.lastID - int64(len(b.history)) + 1
+		if lastEventID+1 < oldest {
+			return ch, nil, true
+		}
+		for _, ev := range b.history {
+			if ev.id > lastEventID {
+				missed = append(missed, ev)
+			}
+		}
+	} else if lastEventID > b.lastID {
+		return ch, nil, true
+	}
+	return ch, missed, false
+}
+
+func (b *submissionBroker) unsubscribe(ch chan streamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// publishSubmission avisa al panel de un cambio en una submission con sus datos actuales.
+// approved y rejected solo se envían si la submission quedó efectivamente en ese estado.
+func (h *AuthHandler) publishSubmission(event string, submissionID string) {
+	row, err := h.DB.SelectRow(`
+		SELECT id, type, data, status, user_id, IFNULL(comment, ''), IFNULL(reviewed_by, 0)
+		FROM submissions WHERE id = ?`, submissionID)
+	if err != nil {
+		return
+	}
+	var s Submission
+	var reviewerID int
+	if err := row.Scan(&s.ID, &s.Type, &s.Data, &s.Status, &s.UserID, &s.Comment, &reviewerID); err != nil {
+		return
+	}
+	if (event == "approved" || event == "rejected") && s.Status != event {
+		return
+	}
+	data := submissionStreamData(s)
+	data.ReviewerID = reviewerID
+	h.submissionStream.publish(event, data)
+}
+
+func submissionStreamData(s Submission) SubmissionStreamEvent {
+	name, _, _, _ := extractFieldsFromSubmission(s)
+	return SubmissionStreamEvent{ID: s.ID, Type: s.Type, Status: s.Status, UserID: s.UserID, Name: name, Comment: s.Comment}
+}
+
+func writeStreamEvent(w http.ResponseWriter, ev streamEvent) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.id, ev.event, ev.data)
+}
+
+// SubmissionStream envía en tiempo real los cambios de submissions al panel de moderación (solo administradores).
+//
+// @Summary Flujo de cambios de submissions (SSE)
+// @Description Server-sent events con los eventos created, approved, rejected y commented. Envía un comentario de heartbeat cada 25 segundos. Al reconectar, el navegador manda Last-Event-ID y se reenvían los eventos perdidos; si ese ID es demasiado viejo (o de antes de un reinicio) llega un evento reset y hay que recargar la lista. Como EventSource no permite encabezados, se puede abrir con un ticket de un solo uso de POST /stream-tickets
+// @Tags submissions
+// @Produce text/event-stream
+// @Param ticket query string false "Ticket de un solo uso (POST /stream-tickets), para clientes que no pueden enviar Authorization"
+// @Param last_event_id query string false "Último ID recibido, si no se envía el encabezado Last-Event-ID"
+// @Success 200 {object} SubmissionStreamEvent "Flujo de eventos; cada data es un SubmissionStreamEvent"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 500 {string} string "El servidor no admite streaming"
+// @Security BearerAuth
+// @Router /submissions/stream [get]
+func (h *AuthHandler) SubmissionStream(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, http.StatusInternalServerError, "El servidor no admite streaming")
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	lastID, _ := strconv.ParseInt(lastEventID, 10, 64)
+	events, missed, reset := h.submissionStream.subscribe(lastID)
+	defer h.submissionStream.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // que Nginx no junte los eventos
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis)
+	if reset {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, ev := range missed {
+		writeStreamEvent(w, ev)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			writeStreamEvent(w, ev)
+		}
+		flusher.Flush()
+	}
+}
@@ -13,7 +13,6 @@ import (
 	"image"
 	"image/jpeg"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"regexp"
@@ -50,7 +49,7 @@ func generateShortWhatsAppDescription(subType, rawDescription string, id int) st
 		label = "VIDEO"
 	case "band":
 		label = "BANDA"
-	case "artist_link":
+	case "artist_link", "venue_link":
 		label = "VINCULACIÓN"
 	}
 
@@ -103,7 +102,7 @@ func (h *AuthHandler) DirectApprove(w http.ResponseWriter, r *http.Request) {
 	cfg, err := ini.Load("data.conf")
 	if err != nil {
 		fmt.Println("DirectApprove - Error al cargar config:", err)
-		http.Error(w, "Config error", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Config error")
 		return
 	}
 
@@ -122,7 +121,7 @@ func (h *AuthHandler) DirectApprove(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		fmt.Println("DirectApprove - Error al convertir ID a entero:", err)
-		http.Error(w, "ID inválido", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "ID inválido")
 		return
 	}
 	expectedToken := generateApprovalToken(id, secret)
@@ -130,7 +129,7 @@ func (h *AuthHandler) DirectApprove(w http.ResponseWriter, r *http.Request) {
 
 	if token != expectedToken {
 		fmt.Println("DirectApprove - Token inválido. Recibido:", token, "Esperado:", expectedToken)
-		http.Error(w, "Token inválido", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, "Token inválido")
 		return
 	}
 
@@ -139,14 +138,14 @@ func (h *AuthHandler) DirectApprove(w http.ResponseWriter, r *http.Request) {
 	// Get submission info before approval to determine type and details
 	row, err := h.DB.SelectRow("SELECT type, data FROM submissions WHERE id = ? AND status = 'pending'", idStr)
 	if err != nil {
-		http.Error(w, "No se pudo consultar la submission", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se pudo consultar la submission")
 		return
 	}
 
 	var subType string
 	var dataRaw []byte
 	if err := row.Scan(&subType, &dataRaw); err != nil {
-		http.Error(w, "Submission no encontrada o ya procesada", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, "Submission no encontrada o ya procesada")
 		return
 	}
 
@@ -160,10 +159,14 @@ func (h *AuthHandler) DirectApprove(w http.ResponseWriter, r *http.Request) {
 	// Prepare response writer to capture JSON response
 	responseCapture := utils.NewResponseCapture(w)
 
-	// Forzamos reviewer_id = 1 (o alguno por default)
+	// Forzamos reviewer_id = 1 (o alguno por default). El enlace no tiene sesión, así que no puede
+	// forzar la aprobación de un evento que se superpone con otro.
 	payload := struct {
 		ReviewerID int `json:"reviewer_id"`
 	}{ReviewerID: 1}
+	query := r.URL.Query()
+	query.Del("force")
+	r.URL.RawQuery = query.Encode()
 
 	body, _ := json.Marshal(payload)
 	r.Body = io.NopCloser(bytes.NewReader(body))
@@ -176,10 +179,13 @@ func (h *AuthHandler) DirectApprove(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// La aprobación crea o modifica contenido público: se descarta lo cacheado
+	h.cache.invalidate()
+
 	// Parse the JSON response to get the ID
 	var response map[string]interface{}
 	if err := json.Unmarshal(responseCapture.Body.Bytes(), &response); err != nil {
-		http.Error(w, "Error al procesar la respuesta", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al procesar la respuesta")
 		return
 	}
 
@@ -217,7 +223,7 @@ func (h *AuthHandler) DirectApprove(w http.ResponseWriter, r *http.Request) {
 			contentID = int(id)
 			viewURL = fmt.Sprintf("https://brotecolectivo.com/artist/%s", slug)
 		}
-	case "artist_link":
+	case "artist_link", "venue_link":
 		viewURL = "https://brotecolectivo.com"
 	default:
 		viewURL = "https://brotecolectivo.com"
@@ -454,15 +460,16 @@ func generateApprovalToken(submissionID int, secret string) string {
 }
 
 type Submission struct {
-	ID        int             `json:"id"`
-	UserID    int             `json:"user_id"`
-	Type      string          `json:"type"` // ejemplo: "banda", "cancion", "event", "news", "video", "artist_link"
-	Data      json.RawMessage `json:"data"`
-	Status    string          `json:"status"` // pending, aprobado, rechazado
-	Reviewer  *models.User    `json:"reviewer,omitempty"`
-	Comment   string          `json:"comment,omitempty"`
-	CreatedAt string          `json:"created_at"`
-	UpdatedAt string          `json:"updated_at"`
+	ID        int                 `json:"id"`
+	UserID    int                 `json:"user_id"`
+	Type      string              `json:"type"` // ejemplo: "banda", "cancion", "event", "news", "video", "artist_link"
+	Data      json.RawMessage     `json:"data"`
+	Status    string              `json:"status"` // pending, aprobado, rechazado
+	Reviewer  *models.User        `json:"reviewer,omitempty"`
+	Comment   string              `json:"comment,omitempty"`
+	CreatedAt string              `json:"created_at"`
+	UpdatedAt string              `json:"updated_at"`
+	Warnings  []SubmissionWarning `json:"warnings,omitempty"`
 }
 
 func extractFieldsFromSubmission(sub Submission) (name, description, slug string, err error) {
@@ -519,6 +526,11 @@ func extractFieldsFromSubmission(sub Submission) (name, description, slug string
 
 		return submissionData.Data.Name, "", submissionData.Data.Slug, err
 
+	case "venue_link":
+		var claim VenueClaim
+		err = json.Unmarshal(sub.Data, &claim)
+		return claim.Name, fmt.Sprintf("Rol: %s. Evidencia: %s", claim.Rol, claim.ProofURL), claim.Slug, err
+
 	default:
 		return "Desconocido", "Sin descripción", "unknown", nil
 	}
@@ -558,6 +570,8 @@ func sendSubmissionWhatsApp(phone string, sub Submission, name, description, slu
 		viewURL = fmt.Sprintf("https://brotecolectivo.com/artist/%s", slug)
 	case "artist_link":
 		viewURL = fmt.Sprintf("https://brotecolectivo.com/artist/%s", slug)
+	case "venue_link":
+		viewURL = "https://brotecolectivo.com"
 	default:
 		viewURL = "https://brotecolectivo.com"
 	}
@@ -703,30 +717,46 @@ func sendSubmissionWhatsApp(phone string, sub Submission, name, description, slu
 	return nil
 }
 
+// submissionListSpec declara los filtros y órdenes de la cola de moderación, por defecto de la más nueva
+// a la más vieja
+var submissionListSpec = &listSpec{
+	from: "submissions s LEFT JOIN users u ON s.user_id = u.id",
+	id:   column("s.id"),
+	filters: map[string]filterField{
+		"status":     enumFilter("s.status"),
+		"type":       enumFilter("s.type"),
+		"user_id":    enumFilter("s.user_id"),
+		"created_at": dateFilter("s.created_at"),
+	},
+	sorts:       map[string]string{"id": column("s.id"), "created_at": column("s.created_at"), "type": column("s.type")},
+	defaultSort: "created_at:desc",
+}
+
 func (h *AuthHandler) GetSubmissions(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.DB.Select(`
-		SELECT s.id, s.user_id, s.type, s.data, s.status, s.created_at, s.updated_at,
-		       u.id, u.username, u.email
-		FROM submissions s
-		LEFT JOIN users u ON s.user_id = u.id
-		ORDER BY s.created_at DESC`)
+	p, err := parsePageRequest(r, maxPageLimit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+
+	q, err := submissionListSpec.query(r, `s.id, s.user_id, s.type, s.data, s.status, s.created_at, s.updated_at,
+		       u.id, u.username, u.email`)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
-	defer rows.Close()
 
 	var subs []Submission
-	for rows.Next() {
+	page, err := h.queryPage(q, p, func(scan func(dest ...interface{}) error) error {
 		var s Submission
 		var dataRaw []byte
 		var reviewerID sql.NullInt64
 		var reviewerName, reviewerEmail sql.NullString
 
-		err := rows.Scan(&s.ID, &s.UserID, &s.Type, &dataRaw, &s.Status, &s.CreatedAt, &s.UpdatedAt,
+		err := scan(&s.ID, &s.UserID, &s.Type, &dataRaw, &s.Status, &s.CreatedAt, &s.UpdatedAt,
 			&reviewerID, &reviewerName, &reviewerEmail)
 		if err != nil {
-			continue
+			return err
 		}
 		s.Data = dataRaw
 		if reviewerID.Valid {
@@ -737,7 +767,20 @@ func (h *AuthHandler) GetSubmissions(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		subs = append(subs, s)
+		return nil
+	})
+	if err != nil {
+		writeListError(w, r, err)
+		return
+	}
+
+	// Advertencias para la cola de moderación (superposiciones, posibles duplicados, etc.)
+	finder := h.newDuplicateFinder()
+	for i := range subs {
+		subs[i].Warnings = h.submissionWarnings(subs[i], finder)
 	}
+	writePageHeaders(w, r, page)
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(subs)
 }
 
@@ -745,7 +788,7 @@ func (h *AuthHandler) GetSubmissionByID(w http.ResponseWriter, r *http.Request)
 	id := chi.URLParam(r, "id")
 	row, err := h.DB.SelectRow("SELECT id, user_id, type, data, status, comment, created_at, updated_at FROM submissions WHERE id = ?", id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	var s Submission
@@ -753,7 +796,7 @@ func (h *AuthHandler) GetSubmissionByID(w http.ResponseWriter, r *http.Request)
 	var comment sql.NullString
 	err = row.Scan(&s.ID, &s.UserID, &s.Type, &dataRaw, &s.Status, &comment, &s.CreatedAt, &s.UpdatedAt)
 	if err != nil {
-		http.Error(w, "No encontrado", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, "No encontrado")
 		return
 	}
 	if comment.Valid {
@@ -763,6 +806,7 @@ func (h *AuthHandler) GetSubmissionByID(w http.ResponseWriter, r *http.Request)
 	}
 
 	s.Data = dataRaw
+	s.Warnings = h.submissionWarnings(s, h.newDuplicateFinder())
 	json.NewEncoder(w).Encode(s)
 }
 
@@ -799,6 +843,15 @@ func moveImageInSpaces(oldKey, newKey string) error {
 	return err
 }
 
+// submissionStatus devuelve el estado actual de una submission ("" si no se pudo leer)
+func (h *AuthHandler) submissionStatus(id string) string {
+	var status string
+	if row, err := h.DB.SelectRow("SELECT status FROM submissions WHERE id = ?", id); err == nil {
+		_ = row.Scan(&status)
+	}
+	return status
+}
+
 func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request) {
 	// Diagnóstico de tabla artist_links
 	h.DB.CheckArtistLinksTable()
@@ -806,13 +859,14 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 	// Extraer ID de la submission
 	id := chi.URLParam(r, "id")
 	if id == "" {
-		http.Error(w, "ID de submission requerido", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "ID de submission requerido")
 		return
 	}
 
 	// Decodificar el cuerpo de la solicitud
 	var payload struct {
-		ReviewerID int `json:"reviewer_id"`
+		ReviewerID int  `json:"reviewer_id"`
+		Force      bool `json:"force"` // aprobar aunque haya superposición de horarios
 	}
 
 	err := json.NewDecoder(r.Body).Decode(&payload)
@@ -833,7 +887,7 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 	row, err := h.DB.SelectRow("SELECT type, data, status FROM submissions WHERE id = ?", id)
 	if err != nil {
 		fmt.Println("Error al obtener submission:", err)
-		http.Error(w, "Error al obtener submission: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener submission", err)
 		return
 	}
 
@@ -841,12 +895,22 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 	var dataRaw json.RawMessage
 	if err := row.Scan(&submissionType, &dataRaw, &status); err != nil {
 		fmt.Println("Error al leer datos de submission:", err)
-		http.Error(w, "Error al leer datos de submission: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al leer datos de submission", err)
 		return
 	}
 
 	fmt.Println("Tipo de submission:", submissionType)
 	fmt.Println("Estado actual:", status)
+
+	// Al terminar, si la submission pasó a aprobada se publica el evento (cada tipo la aprueba por su cuenta)
+	submissionID, _ := strconv.Atoi(id)
+	if status != "approved" {
+		defer func() {
+			if h.submissionStatus(id) == "approved" {
+				h.bus.Publish(SubmissionApproved{Actor: Actor{UserID: payload.ReviewerID}, SubmissionID: submissionID})
+			}
+		}()
+	}
 	fmt.Println("Datos raw:", string(dataRaw))
 
 	// Obtener datos completos de la submission para depuración
@@ -884,19 +948,29 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 	case "band":
 		var band Band
 		if err := json.Unmarshal(dataRaw, &band); err != nil {
-			http.Error(w, "Error al parsear datos", http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, "Error al parsear datos")
 			return
 		}
+		// La imagen pendiente quedó subida con el slug enviado, que puede cambiar si está ocupado
+		pendingSlug := band.Slug
+		band.Slug, err = h.assignSlug("band", band.Slug, band.Name, "")
+		if err != nil {
+			writeSlugError(w, r, err)
+			return
+		}
+		defer h.releaseSlug("band", band.Slug)
 		socialJSON, _ := json.Marshal(band.Social)
 		newID, err := h.DB.Insert(false, `
 			INSERT INTO bands (name, bio, slug, social)
 			VALUES (?, ?, ?, ?)`, band.Name, band.Bio, band.Slug, string(socialJSON))
 		if err != nil {
-			http.Error(w, "Error al crear la banda: "+err.Error(), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al crear la banda", err)
 			return
 		}
-		_ = moveImageInSpaces("pending/"+band.Slug+".jpg", "bands/"+band.Slug+".jpg")
+		_ = moveImageInSpaces("pending/"+pendingSlug+".jpg", "bands/"+band.Slug+".jpg")
 		_, _ = h.DB.Update(false, `UPDATE submissions SET status = 'approved', reviewed_by = ? WHERE id = ?`, payload.ReviewerID, id)
+		band.ID = newID
+		h.bus.Publish(BandCreated{Actor: Actor{UserID: payload.ReviewerID}, Band: band, Origin: OriginSubmission, SubmissionID: submissionID})
 
 		// Crear vinculación automática entre el usuario que envió la banda y la banda creada
 		if submissionUserID > 0 && newID > 0 {
@@ -918,20 +992,8 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 			"band_id": newID,
 		})
 
-		// Publicar en redes sociales
-		imagePath := "bands/" + band.Slug + ".jpg"
-		go func() {
-			err := h.PublishToSocial("band", band, imagePath)
-			idInt, _ := strconv.Atoi(id)
-			if err != nil {
-				h.LogSocialActivity(idInt, "band", false, err.Error())
-			} else {
-				h.LogSocialActivity(idInt, "band", true, "")
-			}
-		}()
-
 	case "eventvenue":
-		fmt.Printf("[Info] Procesando submission tipo eventvenue (ID: %d)\n", id)
+		fmt.Printf("[Info] Procesando submission tipo eventvenue (ID: %s)\n", id)
 
 		var combined struct {
 			Venue struct {
@@ -950,13 +1012,18 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 				DateStart string `json:"date_start"`
 				DateEnd   string `json:"date_end"`
 				BandIDs   []int  `json:"band_ids"`
+				EventTicketing
 			} `json:"event"`
 		}
 
 		if err := json.Unmarshal(dataRaw, &combined); err != nil {
 			fmt.Printf("[Error] No se pudo decodificar el evento+venue: %v\n", err)
 			fmt.Printf("[Error] Datos raw recibidos: %s\n", string(dataRaw))
-			http.Error(w, "Error al parsear datos", http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, "Error al parsear datos")
+			return
+		}
+		if err := combined.Event.EventTicketing.normalize(); err != nil {
+			writeError(w, r, http.StatusBadRequest, "Datos de entradas inválidos", err)
 			return
 		}
 
@@ -964,14 +1031,22 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 			combined.Venue.Name, combined.Event.Title)
 
 		// Primero insertar el venue
-		venueID, err := h.DB.Insert(false, `
-			INSERT INTO venues (name, address, description, slug, latlng, city)
-			VALUES (?, ?, ?, ?, ?, ?)`,
-			combined.Venue.Name, combined.Venue.Address, combined.Venue.Description,
-			combined.Venue.Slug, combined.Venue.LatLng, combined.Venue.City)
+		venue := Venue{
+			Name:        combined.Venue.Name,
+			Address:     combined.Venue.Address,
+			Description: combined.Venue.Description,
+			Slug:        combined.Venue.Slug,
+			LatLng:      combined.Venue.LatLng,
+			City:        combined.Venue.City,
+		}
+		if _, err := normalizeVenueLocation(&venue); err != nil {
+			writeError(w, r, http.StatusBadRequest, "Coordenadas inválidas", err)
+			return
+		}
+		venueID, err := h.insertVenue(&venue)
 		if err != nil {
 			fmt.Printf("[Error] No se pudo insertar el venue combinado: %v\n", err)
-			http.Error(w, "Error al crear venue", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al crear venue")
 			return
 		}
 
@@ -985,14 +1060,20 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 		}
 
 		// Luego insertar el evento usando el ID del venue
-		eventID, err := h.DB.Insert(false, `
-			INSERT INTO events (id_venue, title, tags, content, slug, date_start, date_end)
-			VALUES (?, ?, ?, ?, ?, ?, ?)`,
-			venueID, combined.Event.Title, combined.Event.Tags, combined.Event.Content,
-			combined.Event.Slug, combined.Event.DateStart, combined.Event.DateEnd)
+		event := Event{
+			VenueID:        venueID,
+			Title:          combined.Event.Title,
+			Tags:           combined.Event.Tags,
+			Content:        combined.Event.Content,
+			Slug:           combined.Event.Slug,
+			DateStart:      combined.Event.DateStart,
+			DateEnd:        combined.Event.DateEnd,
+			EventTicketing: combined.Event.EventTicketing,
+		}
+		eventID, err := h.insertEvent(&event)
 		if err != nil {
 			fmt.Printf("[Error] No se pudo insertar el evento combinado: %v\n", err)
-			http.Error(w, "Error al crear evento", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al crear evento")
 			return
 		}
 
@@ -1007,15 +1088,9 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 			}
 		}
 
-		_ = moveImageInSpaces("pending/"+combined.Event.Slug+".jpg", "events/"+combined.Event.Slug+".jpg")
+		_ = moveImageInSpaces("pending/"+combined.Event.Slug+".jpg", "events/"+event.Slug+".jpg")
 		_, _ = h.DB.Update(false, `UPDATE submissions SET status = 'approved', reviewed_by = ? WHERE id = ?`, payload.ReviewerID, id)
 
-		go func() {
-			if err := h.PublishEventToInstagramByID(eventID); err != nil {
-				log.Printf("[WARN] No se pudo publicar en Instagram el evento %d: %v", eventID, err)
-			}
-		}()
-
 		// Crear vinculación automática entre el usuario que envió el evento y el evento creado
 		if submissionUserID > 0 && eventID > 0 {
 			fmt.Println("Creando vinculación automática para evento: UserID=", submissionUserID, "EventID=", eventID)
@@ -1029,17 +1104,8 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 			}
 		}
 
-		// Publicar en redes sociales
-		imagePath := "events/" + combined.Event.Slug + ".jpg"
-		go func() {
-			err := h.PublishToSocial("eventvenue", combined, imagePath)
-			idInt, _ := strconv.Atoi(id)
-			if err != nil {
-				h.LogSocialActivity(idInt, "eventvenue", false, err.Error())
-			} else {
-				h.LogSocialActivity(idInt, "eventvenue", true, "")
-			}
-		}()
+		event.ID = eventID
+		h.bus.Publish(EventPublished{Actor: Actor{UserID: payload.ReviewerID}, Event: event, Origin: OriginSubmission, SubmissionID: submissionID, SubmissionType: "eventvenue"})
 
 		json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "event_id": eventID, "venue_id": venueID})
 
@@ -1048,7 +1114,7 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 		fmt.Println("dataRaw:", string(dataRaw))
 
 		if err := json.Unmarshal(dataRaw, &eventData); err != nil {
-			http.Error(w, "Error al parsear datos: "+err.Error(), http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, "Error al parsear datos", err)
 			return
 		}
 
@@ -1078,13 +1144,36 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 
 		fmt.Println("Usando venueID:", venueID)
 
-		eventID, err := h.DB.Insert(false, `
-		INSERT INTO events (id_venue, title, tags, content, slug, date_start, date_end)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`,
-			venueID, event.Title, event.Tags, event.Content, event.Slug, event.DateStart, event.DateEnd)
+		// Datos de entradas (precios, edad mínima, apertura de puertas)
+		if err := json.Unmarshal(dataRaw, &event.EventTicketing); err != nil {
+			fmt.Println("Advertencia: datos de entradas inválidos:", err)
+		}
+		if err := event.EventTicketing.normalize(); err != nil {
+			writeError(w, r, http.StatusBadRequest, "Datos de entradas inválidos", err)
+			return
+		}
+		event.VenueID = venueID
+
+		// Verificar superposición de horarios en el mismo venue. Solo un administrador puede forzar.
+		claims, _ := r.Context().Value("user").(*models.Claims)
+		force := (payload.Force || r.URL.Query().Get("force") == "true") && claims != nil && claims.Role == "admin"
+		if !force {
+			conflicts, err := h.findVenueConflicts(venueID, event.DateStart, event.DateEnd, 0)
+			if err != nil {
+				writeError(w, r, http.StatusInternalServerError, "Error al verificar conflictos de horario")
+				return
+			}
+			if len(conflicts) > 0 {
+				writeVenueConflict(w, r, conflicts)
+				return
+			}
+		}
+
+		pendingSlug := event.Slug
+		eventID, err := h.insertEvent(&event)
 
 		if err != nil {
-			http.Error(w, "Error al crear evento: "+err.Error(), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al crear evento", err)
 			return
 		}
 
@@ -1111,17 +1200,12 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 			}
 		}
 
-		_ = moveImageInSpaces("pending/"+event.Slug+".jpg", "events/"+event.Slug+".jpg")
+		_ = moveImageInSpaces("pending/"+pendingSlug+".jpg", "events/"+event.Slug+".jpg")
 		_, _ = h.DB.Update(false, `UPDATE submissions SET status = 'approved', reviewed_by = ? WHERE id = ?`, payload.ReviewerID, id)
 
 		// print submissionUserID and eventID
 		fmt.Println("submissionUserID:", submissionUserID)
 		fmt.Println("eventID:", eventID)
-		go func() {
-			if err := h.PublishEventToInstagramByID(eventID); err != nil {
-				log.Printf("[WARN] No se pudo publicar en Instagram el evento %d: %v", eventID, err)
-			}
-		}()
 
 		// Crear vinculación automática entre el usuario que envió el evento y el evento creado
 		if submissionUserID > 0 && eventID > 0 {
@@ -1136,33 +1220,33 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 			}
 		}
 
-		// Publicar en redes sociales
-		imagePath := "events/" + event.Slug + ".jpg"
-		go func() {
-			err := h.PublishToSocial("event", event, imagePath)
-			idInt, _ := strconv.Atoi(id)
-			if err != nil {
-				h.LogSocialActivity(idInt, "event", false, err.Error())
-			} else {
-				h.LogSocialActivity(idInt, "event", true, "")
-			}
-		}()
+		event.ID = eventID
+		h.bus.Publish(EventPublished{Actor: Actor{UserID: payload.ReviewerID}, Event: event, Origin: OriginSubmission, SubmissionID: submissionID, SubmissionType: "event"})
 
 		json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "event_id": eventID})
 
 	case "song":
 		var song Song
 		if err := json.Unmarshal(dataRaw, &song); err != nil {
-			http.Error(w, "Error al parsear datos", http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, "Error al parsear datos")
 			return
 		}
+		// El audio pendiente quedó subido con el slug enviado, que puede cambiar si está ocupado
+		pendingSlug := song.Slug
+		song.Slug, err = h.assignSlug("song", song.Slug, song.Title, "")
+		if err != nil {
+			writeSlugError(w, r, err)
+			return
+		}
+		defer h.releaseSlug("song", song.Slug)
 		songID, err := h.DB.Insert(false, `INSERT INTO songs (title, slug, id_band, id_genre) VALUES (?, ?, ?, ?)`,
 			song.Title, song.Slug, song.BandID, song.GenreID)
 		if err != nil {
-			http.Error(w, "Error al crear canción", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al crear canción")
 			return
 		}
-		_ = moveImageInSpaces("pending/"+song.Slug+".mp3", "songs/"+song.Slug+".mp3")
+		h.reindexSearch("song", songID)
+		_ = moveImageInSpaces("pending/"+pendingSlug+".mp3", "songs/"+song.Slug+".mp3")
 		_, _ = h.DB.Update(false, `UPDATE submissions SET status = 'approved', reviewed_by = ? WHERE id = ?`, payload.ReviewerID, id)
 
 		json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "song_id": songID})
@@ -1170,50 +1254,54 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 	case "news":
 		var news News
 		if err := json.Unmarshal(dataRaw, &news); err != nil {
-			http.Error(w, "Error al parsear datos", http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, "Error al parsear datos")
 			return
 		}
+		pendingSlug := news.Slug
+		news.Slug, err = h.assignSlug("news", news.Slug, news.Title, "")
+		if err != nil {
+			writeSlugError(w, r, err)
+			return
+		}
+		defer h.releaseSlug("news", news.Slug)
 		newsID, err := h.DB.Insert(false, `INSERT INTO news (slug, title, content) VALUES (?, ?, ?)`,
 			news.Slug, news.Title, news.Content)
 		if err != nil {
-			http.Error(w, "Error al crear noticia", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al crear noticia")
 			return
 		}
 		for _, bandID := range news.BandIDs {
 			_, _ = h.DB.Insert(false, `INSERT INTO news_bands (id_news, id_band) VALUES (?, ?)`, newsID, bandID)
 		}
-		_ = moveImageInSpaces("pending/"+news.Slug+".jpg", "news/"+news.Slug+".jpg")
+		_ = moveImageInSpaces("pending/"+pendingSlug+".jpg", "news/"+news.Slug+".jpg")
 		_, _ = h.DB.Update(false, `UPDATE submissions SET status = 'approved', reviewed_by = ? WHERE id = ?`, payload.ReviewerID, id)
-
-		// Publicar en redes sociales
-		imagePath := "news/" + news.Slug + ".jpg"
-		go func() {
-			err := h.PublishToSocial("news", news, imagePath)
-			idInt, _ := strconv.Atoi(id)
-			if err != nil {
-				h.LogSocialActivity(idInt, "news", false, err.Error())
-			} else {
-				h.LogSocialActivity(idInt, "news", true, "")
-			}
-		}()
+		news.ID = newsID
+		h.bus.Publish(NewsPublished{Actor: Actor{UserID: payload.ReviewerID}, News: news, Origin: OriginSubmission, SubmissionID: submissionID})
 
 		json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "news_id": newsID})
 
 	case "video":
 		var video Video
 		if err := json.Unmarshal(dataRaw, &video); err != nil {
-			http.Error(w, "Error al parsear datos", http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, "Error al parsear datos")
 			return
 		}
+		video.Slug, err = h.assignSlug("video", video.Slug, video.Title, "")
+		if err != nil {
+			writeSlugError(w, r, err)
+			return
+		}
+		defer h.releaseSlug("video", video.Slug)
 		videoID, err := h.DB.Insert(false, `INSERT INTO videos (title, slug, id_youtube) VALUES (?, ?, ?)`,
 			video.Title, video.Slug, video.YoutubeID)
 		if err != nil {
-			http.Error(w, "Error al crear video", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al crear video")
 			return
 		}
 		for _, band := range video.Bands {
 			_, _ = h.DB.Insert(false, `INSERT INTO videos_bands (id_video, id_band) VALUES (?, ?)`, videoID, band.ID)
 		}
+		h.reindexSearch("video", videoID)
 		_, _ = h.DB.Update(false, `UPDATE submissions SET status = 'approved', reviewed_by = ? WHERE id = ?`, payload.ReviewerID, id)
 
 		json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "video_id": videoID})
@@ -1307,7 +1395,7 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 		if link.UserID <= 0 || link.ArtistID <= 0 {
 			errMsg := fmt.Sprintf("IDs inválidos: UserID=%d, ArtistID=%d", link.UserID, link.ArtistID)
 			fmt.Println(errMsg)
-			http.Error(w, errMsg, http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, errMsg)
 			return
 		}
 
@@ -1329,7 +1417,7 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 
 		if err != nil {
 			fmt.Println("Error al crear/actualizar vínculo de artista:", err)
-			http.Error(w, "Error al crear vínculo de artista: "+err.Error(), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al crear vínculo de artista", err)
 			return
 		}
 
@@ -1342,136 +1430,42 @@ func (h *AuthHandler) ApproveSubmission(w http.ResponseWriter, r *http.Request)
 			fmt.Println("Submission actualizada correctamente, filas afectadas:", updateResult)
 		}
 
-		// Enviar notificación por WhatsApp si hay un número guardado
-		comment, _ := h.DB.SelectRow("SELECT comment FROM submissions WHERE id = ?", id)
-		var commentStr string
-		if err := comment.Scan(&commentStr); err == nil {
-			// Extraer número de WhatsApp
-			whatsappParts := strings.Split(commentStr, "WhatsApp:")
-			if len(whatsappParts) > 1 {
-				userPhone := strings.TrimSpace(whatsappParts[1])
-				if userPhone != "" {
-					// Cargar configuración
-					cfg, err := ini.Load("data.conf")
-					if err == nil {
-						// Preparar datos para el mensaje
-						fmt.Println("Preparando mensaje de WhatsApp para:", userPhone)
-						fmt.Println("Datos de la vinculación - Rol:", link.Rol, "Nombre:", link.Name)
-
-						// Asegurar que los valores no estén vacíos
-						rol := link.Rol
-						if rol == "" {
-							rol = "colaborador"
-						}
-
-						artistName := link.Name
-						if artistName == "" {
-							artistName = "el artista"
-						}
-
-						// Verificar formato del número de teléfono
-						if !strings.HasPrefix(userPhone, "+") && !strings.HasPrefix(userPhone, "549") {
-							// Agregar prefijo de Argentina si no lo tiene
-							userPhone = "549" + userPhone
-						}
-
-						// Eliminar cualquier espacio o carácter no numérico (excepto el +)
-						userPhone = strings.Map(func(r rune) rune {
-							if r == '+' || (r >= '0' && r <= '9') {
-								return r
-							}
-							return -1
-						}, userPhone)
-
-						fmt.Println("Número de teléfono formateado:", userPhone)
-
-						// Construir el mensaje según la plantilla vinculacion_aprobada
-						message := map[string]interface{}{
-							"messaging_product": "whatsapp",
-							"recipient_type":    "individual",
-							"to":                userPhone,
-							"type":              "template",
-							"template": map[string]interface{}{
-								"name": "vinculacion_aprobada",
-								"language": map[string]interface{}{
-									"code": "es",
-								},
-								"components": []map[string]interface{}{
-									{
-										"type": "body",
-										"parameters": []map[string]interface{}{
-											{"type": "text", "text": rol},
-											{"type": "text", "text": artistName},
-										},
-									},
-								},
-							},
-						}
-
-						// Enviar mensaje
-						whatsappToken := cfg.Section("keys").Key("whatsapp_token").String()
-						whatsappPhoneID := cfg.Section("keys").Key("whatsapp_number").String()
-
-						if whatsappToken != "" && whatsappPhoneID != "" {
-							go func() {
-								jsonData, _ := json.Marshal(message)
-								fmt.Println("Enviando WhatsApp a:", userPhone)
-								fmt.Println("Datos del mensaje:", string(jsonData))
+		// El WhatsApp con la plantilla vinculacion_aprobada sale desde la cola de trabajos
+		h.bus.Publish(ArtistLinkApproved{
+			Actor:        Actor{UserID: payload.ReviewerID},
+			SubmissionID: submissionID,
+			ArtistID:     link.ArtistID,
+			Rol:          link.Rol,
+			ArtistName:   link.Name,
+		})
 
-								req, _ := http.NewRequest("POST", fmt.Sprintf("https://graph.facebook.com/v17.0/%s/messages", whatsappPhoneID), bytes.NewBuffer(jsonData))
-								req.Header.Set("Content-Type", "application/json")
-								req.Header.Set("Authorization", "Bearer "+whatsappToken)
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok"})
 
-								client := &http.Client{
-									Timeout: 30 * time.Second,
-								}
-								resp, err := client.Do(req)
-								if err != nil {
-									fmt.Println("Error al enviar WhatsApp:", err)
-									return
-								}
+	case "venue_link":
+		claim, err := h.approveVenueClaim(submissionUserID, dataRaw)
+		if err != nil {
+			fmt.Println("Error al aprobar vinculación de venue:", err)
+			writeError(w, r, http.StatusInternalServerError, "Error al crear vínculo de venue", err)
+			return
+		}
 
-								// Leer y mostrar la respuesta
-								defer resp.Body.Close()
-								respBody, _ := io.ReadAll(resp.Body)
-								fmt.Println("Respuesta de WhatsApp API:", resp.Status)
-								fmt.Println(string(respBody))
-
-								// Si hay un error, intentar interpretar la respuesta
-								if resp.StatusCode >= 400 {
-									var errorResp map[string]interface{}
-									if err := json.Unmarshal(respBody, &errorResp); err == nil {
-										if errObj, ok := errorResp["error"].(map[string]interface{}); ok {
-											fmt.Println("Código de error:", errObj["code"])
-											fmt.Println("Mensaje de error:", errObj["message"])
-											if errData, ok := errObj["error_data"].(map[string]interface{}); ok {
-												fmt.Println("Detalles del error:", errData["details"])
-											}
-										}
-									}
-								}
-							}()
-						} else {
-							fmt.Println("No se pudo enviar WhatsApp: falta token o phone_id")
-							fmt.Println("Phone ID:", whatsappPhoneID)
-							fmt.Println("Token disponible:", whatsappToken != "")
-						}
-					} else {
-						fmt.Println("Error al cargar configuración para WhatsApp:", err)
-					}
-				} else {
-					fmt.Println("Número de WhatsApp vacío")
-				}
-			} else {
-				fmt.Println("Formato incorrecto para WhatsApp en el comentario")
-			}
-		} else {
-			fmt.Println("No se encontró número de WhatsApp en el comentario")
+		_, err = h.DB.Update(false, `UPDATE submissions SET status = 'approved', reviewed_by = ? WHERE id = ?`,
+			payload.ReviewerID, id)
+		if err != nil {
+			fmt.Println("Error al actualizar estado de submission:", err)
 		}
 
-		json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok"})
+		h.bus.Publish(VenueClaimReviewed{
+			Actor:          Actor{UserID: payload.ReviewerID},
+			SubmissionID:   submissionID,
+			VenueID:        claim.VenueID,
+			Approved:       true,
+			NotifyWhatsApp: true,
+		})
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "venue_id": claim.VenueID})
 	default:
-		http.Error(w, "Tipo de submission no soportado", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Tipo de submission no soportado")
 	}
 }
 
@@ -1483,7 +1477,7 @@ func (h *AuthHandler) UploadSubmissionImage(w http.ResponseWriter, r *http.Reque
 	err := r.ParseMultipartForm(10 << 20)
 	if err != nil {
 		fmt.Println("Error parsing multipart form:", err)
-		http.Error(w, "No se pudo procesar la imagen: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "No se pudo procesar la imagen", err)
 		return
 	}
 
@@ -1494,7 +1488,7 @@ func (h *AuthHandler) UploadSubmissionImage(w http.ResponseWriter, r *http.Reque
 	file, handler, err := r.FormFile("file")
 	if err != nil {
 		fmt.Println("Error getting form file:", err)
-		http.Error(w, "No se pudo leer el archivo: "+err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "No se pudo leer el archivo", err)
 		return
 	}
 	defer file.Close()
@@ -1503,7 +1497,7 @@ func (h *AuthHandler) UploadSubmissionImage(w http.ResponseWriter, r *http.Reque
 	fmt.Println("Received slug:", slug) // Debug: imprimir el slug recibido
 
 	if slug == "" {
-		http.Error(w, "Slug es requerido", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Slug es requerido")
 		return
 	}
 
@@ -1515,7 +1509,7 @@ func (h *AuthHandler) UploadSubmissionImage(w http.ResponseWriter, r *http.Reque
 	buf := new(bytes.Buffer)
 	_, err = io.Copy(buf, file)
 	if err != nil {
-		http.Error(w, "Error leyendo archivo", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error leyendo archivo")
 		return
 	}
 
@@ -1529,19 +1523,19 @@ func (h *AuthHandler) UploadSubmissionImage(w http.ResponseWriter, r *http.Reque
 		strings.Contains(contentType, "jpg"):
 		src, _, err = image.Decode(bytes.NewReader(buf.Bytes()))
 	default:
-		http.Error(w, "Formato no soportado (solo jpg, png, gif)", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Formato no soportado (solo jpg, png, gif)")
 		return
 	}
 
 	// guardar como /pending/slug.jpg en Spaces
 	tmpPath := fmt.Sprintf("tmp/%s.jpg", slug)
 	if err := os.MkdirAll("tmp", os.ModePerm); err != nil {
-		http.Error(w, "No se pudo crear carpeta temporal", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se pudo crear carpeta temporal")
 		return
 	}
 	out, err := os.Create(tmpPath)
 	if err != nil {
-		http.Error(w, "No se pudo crear archivo temporal", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se pudo crear archivo temporal")
 		return
 	}
 	defer out.Close()
@@ -1549,7 +1543,7 @@ func (h *AuthHandler) UploadSubmissionImage(w http.ResponseWriter, r *http.Reque
 
 	err = jpeg.Encode(out, src, &jpeg.Options{Quality: 85})
 	if err != nil {
-		http.Error(w, "No se pudo convertir a JPG", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "No se pudo convertir a JPG")
 		return
 	}
 
@@ -1569,7 +1563,7 @@ func (h *AuthHandler) UploadSubmissionImage(w http.ResponseWriter, r *http.Reque
 
 	err = uploadToSpaces(tmpPath, key, "image/jpeg")
 	if err != nil {
-		http.Error(w, "Error al subir a Spaces: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al subir a Spaces", err)
 		return
 	}
 
@@ -1583,7 +1577,7 @@ func (h *AuthHandler) UploadSubmissionImage(w http.ResponseWriter, r *http.Reque
 func (h *AuthHandler) CreateSubmission(w http.ResponseWriter, r *http.Request) {
 	var s Submission
 	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
-		http.Error(w, "Error al decodificar", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar")
 		return
 	}
 
@@ -1604,16 +1598,24 @@ func (h *AuthHandler) CreateSubmission(w http.ResponseWriter, r *http.Request) {
 		initialStatus = "approved"
 	}
 
+	// Posibles duplicados para que el frontend se los muestre a quien envió la colaboración.
+	// Se buscan antes de guardar: la aprobación automática de un admin crearía el propio registro.
+	duplicates := h.newDuplicateFinder().find(s.Type, s.Data)
+	if duplicates == nil {
+		duplicates = []DuplicateMatch{}
+	}
+
 	// Insertar la submission (sin reviewer_id que no existe en la tabla)
 	id, err := h.DB.Insert(false, `
 		INSERT INTO submissions (user_id, type, data, status, updated_at)
 		VALUES (?, ?, ?, ?, NOW())`,
 		s.UserID, s.Type, string(s.Data), initialStatus)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	s.ID = int(id)
+	s.Status = initialStatus
 
 	// Si es admin, actualizar el campo reviewed_by (que es el nombre correcto de la columna)
 	if isAdmin {
@@ -1628,75 +1630,51 @@ func (h *AuthHandler) CreateSubmission(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// asignar el RawMessage para poder usar extractFields
-	s.Data = json.RawMessage(s.Data)
-
-	// cargar configuración desde data.conf
-	cfg, err := ini.Load("data.conf")
-	if err == nil {
-		// extraer campos y mandar WhatsApp
-		name, description, slug, err := extractFieldsFromSubmission(s)
-
-		// print name, description, slug
-		fmt.Printf("[Debug] CreateSubmission - name=%s, description=%s, slug=%s\n", name, description, slug)
-
-		if err == nil {
-			// número del admin al que querés mandar el mensaje
-			adminPhone := cfg.Section("keys").Key("admin_phone").String()
-			if adminPhone != "" && !isAdmin { // No enviar WhatsApp si es un admin
-				sendSubmissionWhatsApp(adminPhone, s, name, description, slug, cfg)
-			}
-
-			// Si es una solicitud de vinculación y se proporcionó un número de WhatsApp, guardarlo
-			if s.Type == "artist_link" {
-				var linkData struct {
-					WhatsApp string `json:"whatsapp"`
-				}
-				if err := json.Unmarshal(s.Data, &linkData); err == nil && linkData.WhatsApp != "" {
-					// Guardar el número de WhatsApp en la base de datos para notificaciones futuras
-					_, _ = h.DB.Update(false, `
-						UPDATE submissions 
-						SET comment = ? 
-						WHERE id = ?`,
-						fmt.Sprintf("WhatsApp: %s", linkData.WhatsApp), s.ID)
-				}
-			}
+	// Si es una solicitud de vinculación y se proporcionó un número de WhatsApp, guardarlo
+	if s.Type == "artist_link" || s.Type == "venue_link" {
+		var linkData struct {
+			WhatsApp string `json:"whatsapp"`
+		}
+		if err := json.Unmarshal(s.Data, &linkData); err == nil && linkData.WhatsApp != "" {
+			// Guardar el número de WhatsApp en la base de datos para notificaciones futuras
+			_, _ = h.DB.Update(false, `
+				UPDATE submissions 
+				SET comment = ? 
+				WHERE id = ?`,
+				fmt.Sprintf("WhatsApp: %s", linkData.WhatsApp), s.ID)
 		}
 	}
 
-	// Si es administrador, procesar la submission automáticamente
-	if isAdmin {
-		go func(submissionID int, submissionType string, submissionData json.RawMessage) {
-			// Esperar un momento para asegurar que la imagen se haya procesado
-			time.Sleep(2 * time.Second)
-
-			fmt.Printf("[Info] Iniciando procesamiento automático para submission %d (admin) - tipo: %s\n",
-				submissionID, submissionType)
-
-			// Procesar la submission aprobada directamente
-			success := h.processApprovedSubmission(submissionID, s.UserID)
-
-			if success {
-				// Eliminar la submission después de procesarla
-				_, err = h.DB.Delete(false, "DELETE FROM submissions WHERE id = ?", submissionID)
-				if err != nil {
-					fmt.Printf("[Error] No se pudo eliminar la submission %d después de procesarla: %v\n", submissionID, err)
-				} else {
-					fmt.Printf("[Info] Submission %d procesada y eliminada correctamente\n", submissionID)
-				}
-			} else {
-				fmt.Printf("[Warning] No se eliminó la submission %d porque hubo errores en el procesamiento\n", submissionID)
-			}
-		}(s.ID, s.Type, s.Data)
-	}
+	// Aviso al administrador, panel de moderación y, si es admin, aprobación automática
+	h.bus.Publish(SubmissionCreated{Actor: Actor{UserID: s.UserID}, Submission: s, IsAdmin: isAdmin})
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status": "ok",
-		"id":     s.ID,
+		"status":              "ok",
+		"id":                  s.ID,
+		"possible_duplicates": duplicates,
 	})
 }
 
+// notifyAdminOfSubmission avisa por WhatsApp al administrador de una colaboración nueva. Sin
+// configuración de WhatsApp no hace nada.
+func (h *AuthHandler) notifyAdminOfSubmission(s Submission) error {
+	cfg, err := ini.Load("data.conf")
+	if err != nil {
+		return nil
+	}
+	name, description, slug, err := extractFieldsFromSubmission(s)
+	if err != nil {
+		return fmt.Errorf("no se pudo armar el aviso de la submission %d: %v", s.ID, err)
+	}
+	// número del admin al que se manda el mensaje
+	adminPhone := cfg.Section("keys").Key("admin_phone").String()
+	if adminPhone == "" {
+		return nil
+	}
+	return sendSubmissionWhatsApp(adminPhone, s, name, description, slug, cfg)
+}
+
 func (h *AuthHandler) UpdateSubmissionStatus(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	var payload struct {
@@ -1706,7 +1684,7 @@ func (h *AuthHandler) UpdateSubmissionStatus(w http.ResponseWriter, r *http.Requ
 		Data       json.RawMessage `json:"data"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "Error al decodificar", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar")
 		return
 	}
 
@@ -1717,7 +1695,7 @@ func (h *AuthHandler) UpdateSubmissionStatus(w http.ResponseWriter, r *http.Requ
 		"rejected": true,
 	}
 	if !validStatus[payload.Status] {
-		http.Error(w, "Estado no válido", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Estado no válido")
 		return
 	}
 
@@ -1733,7 +1711,7 @@ func (h *AuthHandler) UpdateSubmissionStatus(w http.ResponseWriter, r *http.Requ
 		// Obtener datos de la submission
 		row, err := h.DB.SelectRow("SELECT type, data, user_id FROM submissions WHERE id = ?", id)
 		if err != nil {
-			http.Error(w, "Error al obtener submission", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al obtener submission")
 			return
 		}
 
@@ -1741,7 +1719,7 @@ func (h *AuthHandler) UpdateSubmissionStatus(w http.ResponseWriter, r *http.Requ
 		var dataRaw json.RawMessage
 		var userID int
 		if err := row.Scan(&submissionType, &dataRaw, &userID); err != nil {
-			http.Error(w, "Error al leer datos de submission", http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "Error al leer datos de submission")
 			return
 		}
 
@@ -1781,7 +1759,7 @@ func (h *AuthHandler) UpdateSubmissionStatus(w http.ResponseWriter, r *http.Requ
 
 			// Verificar que los IDs sean válidos
 			if link.UserID <= 0 || link.ArtistID <= 0 {
-				http.Error(w, "IDs inválidos para vinculación de artista", http.StatusBadRequest)
+				writeError(w, r, http.StatusBadRequest, "IDs inválidos para vinculación de artista")
 				return
 			}
 
@@ -1800,67 +1778,202 @@ func (h *AuthHandler) UpdateSubmissionStatus(w http.ResponseWriter, r *http.Requ
 			}
 
 			if err != nil {
-				http.Error(w, "Error al crear vínculo de artista", http.StatusInternalServerError)
+				writeError(w, r, http.StatusInternalServerError, "Error al crear vínculo de artista")
 				return
 			}
 		}
 	}
 
+	// Las solicitudes de vinculación con venues actualizan venue_links al aprobar o rechazar
+	if payload.Status == "approved" || payload.Status == "rejected" {
+		var submissionType string
+		var dataRaw []byte
+		var userID int
+		row, err := h.DB.SelectRow("SELECT type, data, user_id FROM submissions WHERE id = ?", id)
+		if err == nil && row.Scan(&submissionType, &dataRaw, &userID) == nil && submissionType == "venue_link" {
+			var claim VenueClaim
+			if payload.Status == "approved" {
+				claim, err = h.approveVenueClaim(userID, dataRaw)
+			} else {
+				claim, err = h.rejectVenueClaim(userID, dataRaw)
+			}
+			if err != nil {
+				writeError(w, r, http.StatusInternalServerError, "Error al actualizar vínculo de venue", err)
+				return
+			}
+			// El WhatsApp al usuario se envía con el aviso general al autor (SubmissionApproved/Rejected)
+			submissionID, _ := strconv.Atoi(id)
+			h.bus.Publish(VenueClaimReviewed{
+				Actor:        Actor{UserID: payload.ReviewerID},
+				SubmissionID: submissionID,
+				VenueID:      claim.VenueID,
+				Approved:     payload.Status == "approved",
+				Comment:      payload.Comment.String,
+			})
+		}
+	}
+
+	// Estado anterior: los suscriptores se enteran de los cambios, no de cada vez que se guarda
+	var previousStatus string
+	var previousComment sql.NullString
+	if row, err := h.DB.SelectRow("SELECT status, comment FROM submissions WHERE id = ?", id); err == nil {
+		_ = row.Scan(&previousStatus, &previousComment)
+	}
+
 	// Actualizar la submission en la base de datos
 	_, err := h.DB.Update(false, `
 		UPDATE submissions SET status=?, comment=?, reviewed_by=?, data=? WHERE id=?`,
 		payload.Status, payload.Comment, payload.ReviewerID, payload.Data, id)
 	if err != nil {
 		fmt.Println("Error al actualizar submission:", err)
-		http.Error(w, "Error al actualizar submission: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al actualizar submission", err)
 		return
 	}
+	submissionID, _ := strconv.Atoi(id)
+	actor := Actor{UserID: payload.ReviewerID}
+	switch {
+	case payload.Status == "approved" && previousStatus != "approved":
+		h.bus.Publish(SubmissionApproved{Actor: actor, SubmissionID: submissionID, Comment: payload.Comment.String})
+	case payload.Status == "rejected" && previousStatus != "rejected":
+		h.bus.Publish(SubmissionRejected{Actor: actor, SubmissionID: submissionID, Comment: payload.Comment.String})
+	case payload.Comment.Valid && payload.Comment.String != previousComment.String:
+		h.bus.Publish(SubmissionCommented{Actor: actor, SubmissionID: submissionID, Comment: payload.Comment.String})
+	}
 
-	// Enviar notificación de WhatsApp si es necesario
-	if payload.Status == "approved" || payload.Status == "rejected" {
-		// Obtener datos de la submission
-		var (
-			userID       int
-			submissionID int
-			whatsapp     string
-			comment      sql.NullString // Cambiado a sql.NullString para manejar NULL
-		)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok"})
+}
 
-		// Obtener el número de WhatsApp del usuario
-		row, err := h.DB.SelectRow(`
-			SELECT s.id, s.user_id, u.whatsapp, s.comment
-			FROM submissions s
-			JOIN users u ON s.user_id = u.id
-			WHERE s.id = ?
-		`, id)
-
-		if err == nil && row != nil {
-			if err := row.Scan(&submissionID, &userID, &whatsapp, &comment); err == nil && whatsapp != "" {
-				// Preparar mensaje según el estado
-				var message string
-				if payload.Status == "approved" {
-					message = fmt.Sprintf("¡Buenas noticias! Tu solicitud en Brote Colectivo ha sido aprobada.")
-				} else {
-					message = fmt.Sprintf("Tu solicitud en Brote Colectivo ha sido rechazada.")
-				}
+// notifySubmissionAuthor avisa por WhatsApp a quien envió la colaboración que fue aprobada o
+// rechazada, con el comentario del moderador si lo hay
+func (h *AuthHandler) notifySubmissionAuthor(submissionID int, approved bool) error {
+	var whatsapp string
+	var comment sql.NullString
+	row, err := h.DB.SelectRow(`
+		SELECT u.whatsapp, s.comment
+		FROM submissions s
+		JOIN users u ON s.user_id = u.id
+		WHERE s.id = ?
+	`, submissionID)
+	if err != nil {
+		return err
+	}
+	if err := row.Scan(&whatsapp, &comment); err == sql.ErrNoRows {
+		return nil // la submission ya se procesó y eliminó
+	} else if err != nil {
+		return err
+	}
+	if whatsapp == "" {
+		return nil
+	}
 
-				// Agregar comentario si existe
-				if comment.Valid && comment.String != "" {
-					message += fmt.Sprintf("\n\nComentario: %s", comment.String)
-				}
+	message := "Tu solicitud en Brote Colectivo ha sido rechazada."
+	if approved {
+		message = "¡Buenas noticias! Tu solicitud en Brote Colectivo ha sido aprobada."
+	}
+	if comment.Valid && comment.String != "" {
+		message += fmt.Sprintf("\n\nComentario: %s", comment.String)
+	}
+	return h.sendWhatsAppMessage(whatsapp, message)
+}
 
-				// Enviar mensaje de WhatsApp
-				go h.sendWhatsAppMessage(whatsapp, message)
-			} else if err != nil {
-				fmt.Println("Error al escanear datos para WhatsApp:", err)
-			}
-		} else if err != nil {
-			fmt.Println("Error al obtener datos para WhatsApp:", err)
+// notifyArtistLinkApproved avisa con la plantilla vinculacion_aprobada al WhatsApp que quedó en el
+// comentario de una solicitud artist_link. Sin número o sin configuración de WhatsApp no hace nada.
+func (h *AuthHandler) notifyArtistLinkApproved(submissionID int, rol, artistName string) error {
+	var comment sql.NullString
+	row, err := h.DB.SelectRow("SELECT comment FROM submissions WHERE id = ?", submissionID)
+	if err != nil {
+		return err
+	}
+	if err := row.Scan(&comment); err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	whatsappParts := strings.Split(comment.String, "WhatsApp:")
+	if len(whatsappParts) < 2 {
+		return nil
+	}
+	userPhone := strings.TrimSpace(whatsappParts[1])
+	if userPhone == "" {
+		return nil
+	}
+
+	cfg, err := ini.Load("data.conf")
+	if err != nil {
+		return nil
+	}
+	whatsappToken := cfg.Section("keys").Key("whatsapp_token").String()
+	whatsappPhoneID := cfg.Section("keys").Key("whatsapp_number").String()
+	if whatsappToken == "" || whatsappPhoneID == "" {
+		return nil
+	}
+
+	// Asegurar que los valores no estén vacíos
+	if rol == "" {
+		rol = "colaborador"
+	}
+	if artistName == "" {
+		artistName = "el artista"
+	}
+
+	// Agregar prefijo de Argentina si no lo tiene y dejar solo dígitos (y el +)
+	if !strings.HasPrefix(userPhone, "+") && !strings.HasPrefix(userPhone, "549") {
+		userPhone = "549" + userPhone
+	}
+	userPhone = strings.Map(func(r rune) rune {
+		if r == '+' || (r >= '0' && r <= '9') {
+			return r
 		}
+		return -1
+	}, userPhone)
+
+	message := map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                userPhone,
+		"type":              "template",
+		"template": map[string]interface{}{
+			"name": "vinculacion_aprobada",
+			"language": map[string]interface{}{
+				"code": "es",
+			},
+			"components": []map[string]interface{}{
+				{
+					"type": "body",
+					"parameters": []map[string]interface{}{
+						{"type": "text", "text": rol},
+						{"type": "text", "text": artistName},
+					},
+				},
+			},
+		},
+	}
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return err
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok"})
+	req, err := http.NewRequest("POST", fmt.Sprintf("https://graph.facebook.com/v17.0/%s/messages", whatsappPhoneID), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+whatsappToken)
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error al enviar WhatsApp de vinculación: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("WhatsApp API respondió %s: %s", resp.Status, string(respBody))
+	}
+	return nil
 }
 
 // sendWhatsAppMessage envía un mensaje simple de WhatsApp al número especificado
@@ -1946,6 +2059,34 @@ func (h *AuthHandler) sendWhatsAppMessage(phone, message string) error {
 
 // processApprovedSubmission procesa una submission aprobada y crea el contenido correspondiente
 // Devuelve true si el procesamiento fue exitoso, false en caso contrario
+// Espera antes de aprobar automáticamente la colaboración de un administrador: el frontend sube
+// la imagen en un pedido aparte y hay que darle tiempo a llegar a pending/ antes de moverla
+const adminSubmissionDelay = 2 * time.Second
+
+// autoApproveSubmission procesa la colaboración de un administrador y, si sale bien, la elimina.
+// Corre como trabajo en segundo plano (submission.auto_approve).
+func (h *AuthHandler) autoApproveSubmission(submissionID, userID int) error {
+	row, err := h.DB.SelectRow("SELECT id FROM submissions WHERE id = ?", submissionID)
+	if err != nil {
+		return err
+	}
+	if err := row.Scan(&submissionID); err == sql.ErrNoRows {
+		return nil // ya la procesó o eliminó un moderador
+	} else if err != nil {
+		return err
+	}
+	fmt.Printf("[Info] Iniciando procesamiento automático para submission %d (admin)\n", submissionID)
+	if !h.processApprovedSubmission(submissionID, userID) {
+		return fmt.Errorf("hubo errores al procesar la submission %d; queda para revisión manual", submissionID)
+	}
+	// Eliminar la submission después de procesarla
+	if _, err := h.DB.Delete(false, "DELETE FROM submissions WHERE id = ?", submissionID); err != nil {
+		return fmt.Errorf("no se pudo eliminar la submission %d después de procesarla: %v", submissionID, err)
+	}
+	fmt.Printf("[Info] Submission %d procesada y eliminada correctamente\n", submissionID)
+	return nil
+}
+
 func (h *AuthHandler) processApprovedSubmission(submissionID, reviewerID int) bool {
 	fmt.Printf("[Info] Procesando automáticamente submission %d\n", submissionID)
 
@@ -1970,6 +2111,12 @@ func (h *AuthHandler) processApprovedSubmission(submissionID, reviewerID int) bo
 
 	switch submissionType {
 	case "event":
+		// Si se superpone con otro evento del venue, queda pendiente para que la revise un moderador
+		if conflicts := h.eventSubmissionConflicts(dataRaw); len(conflicts) > 0 {
+			fmt.Printf("[Warning] La submission %d se superpone con eventos %s\n", submissionID, conflictIDs(conflicts))
+			h.holdSubmissionForConflicts(submissionID, conflicts)
+			return false
+		}
 		success = h.processEventSubmission(dataRaw, userID)
 	case "venue":
 		success = h.processVenueSubmission(dataRaw, userID)
@@ -1977,6 +2124,8 @@ func (h *AuthHandler) processApprovedSubmission(submissionID, reviewerID int) bo
 		success = h.processEventVenueSubmission(dataRaw, userID)
 	case "news":
 		success = h.processNewsSubmission(dataRaw, userID)
+	case "venue_link":
+		success = h.processVenueClaimSubmission(dataRaw, userID)
 	default:
 		fmt.Printf("[Warning] Tipo de submission no soportado para procesamiento automático: %s\n", submissionType)
 		return false
@@ -1998,14 +2147,13 @@ func (h *AuthHandler) processNewsSubmission(dataRaw []byte, userID int) bool {
 		return false
 	}
 
-	// Generar slug desde el título
-	slug := generateSlug(newsData.Title)
-
-	// Verificar si el slug ya existe y modificarlo si es necesario
-	existingSlug, err := h.DB.SelectRow("SELECT slug FROM news WHERE slug = ?", slug)
-	if err == nil && existingSlug != nil {
-		slug = fmt.Sprintf("%s-%d", slug, time.Now().Unix())
+	// Generar slug desde el título; si está ocupado se prueba con el año y luego con sufijos numéricos
+	slug, err := h.assignSlug("news", "", newsData.Title, strconv.Itoa(time.Now().Year()))
+	if err != nil {
+		fmt.Printf("Error al generar slug de noticia: %v\n", err)
+		return false
 	}
+	defer h.releaseSlug("news", slug)
 
 	// Mover la imagen de la carpeta temporal a la definitiva si existe
 	finalImageURL := newsData.Image
@@ -2038,6 +2186,11 @@ func (h *AuthHandler) processNewsSubmission(dataRaw []byte, userID int) bool {
 		return false
 	}
 
+	h.bus.Publish(NewsPublished{
+		Actor:  Actor{UserID: userID},
+		News:   News{ID: int(newsID), Slug: slug, Title: newsData.Title, Content: newsData.Content},
+		Origin: OriginAdmin,
+	})
 	fmt.Printf("Noticia creada exitosamente con ID: %d\n", newsID)
 	return true
 }
@@ -2054,20 +2207,32 @@ func (h *AuthHandler) processEventSubmission(dataRaw []byte, userID int) bool {
 		DateEnd   string `json:"date_end"`
 		IDVenue   int    `json:"id_venue"`
 		BandIDs   []int  `json:"band_ids"`
+		EventTicketing
 	}
 
 	if err := json.Unmarshal(dataRaw, &event); err != nil {
 		fmt.Printf("Error al deserializar datos de evento: %v\n", err)
 		return false
 	}
+	if err := event.EventTicketing.normalize(); err != nil {
+		fmt.Printf("Datos de entradas inválidos: %v\n", err)
+		return false
+	}
 
 	fmt.Printf("[Info] Datos de evento decodificados: %s (slug: %s)\n", event.Title, event.Slug)
 
 	// Insertar el evento
-	eventID, err := h.DB.Insert(false, `
-		INSERT INTO events (id_venue, title, tags, content, slug, date_start, date_end)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		event.IDVenue, event.Title, event.Tags, event.Content, event.Slug, event.DateStart, event.DateEnd)
+	published := Event{
+		VenueID:        event.IDVenue,
+		Title:          event.Title,
+		Tags:           event.Tags,
+		Content:        event.Content,
+		Slug:           event.Slug,
+		DateStart:      event.DateStart,
+		DateEnd:        event.DateEnd,
+		EventTicketing: event.EventTicketing,
+	}
+	eventID, err := h.insertEvent(&published)
 	if err != nil {
 		fmt.Printf("Error al insertar evento: %v\n", err)
 		return false
@@ -2091,6 +2256,8 @@ func (h *AuthHandler) processEventSubmission(dataRaw []byte, userID int) bool {
 	if linkErr != nil {
 		fmt.Printf("Error al vincular evento %d con usuario %d: %v\n", eventID, userID, linkErr)
 	}
+	published.ID = eventID
+	h.bus.Publish(EventPublished{Actor: Actor{UserID: userID}, Event: published, Origin: OriginAdmin, SubmissionType: "event"})
 
 	fmt.Printf("Evento creado automáticamente con ID: %d\n", eventID)
 	return true
@@ -2115,11 +2282,15 @@ func (h *AuthHandler) processVenueSubmission(dataRaw []byte, userID int) bool {
 
 	fmt.Printf("[Info] Datos de venue decodificados: %s (slug: %s)\n", venue.Name, venue.Slug)
 
-	// Insertar el venue
-	venueID, err := h.DB.Insert(false, `
-		INSERT INTO venues (name, address, description, slug, latlng, city)
-		VALUES (?, ?, ?, ?, ?, ?)`,
-		venue.Name, venue.Address, venue.Description, venue.Slug, venue.LatLng, venue.City)
+	// Insertar el venue (valida las coordenadas)
+	venueID, err := h.insertVenue(&Venue{
+		Name:        venue.Name,
+		Address:     venue.Address,
+		Description: venue.Description,
+		Slug:        venue.Slug,
+		LatLng:      venue.LatLng,
+		City:        venue.City,
+	})
 	if err != nil {
 		fmt.Printf("Error al insertar venue: %v\n", err)
 		return false
@@ -2162,6 +2333,7 @@ func (h *AuthHandler) processEventVenueSubmission(dataRaw []byte, userID int) bo
 			DateStart string `json:"date_start"`
 			DateEnd   string `json:"date_end"`
 			BandIDs   []int  `json:"band_ids"`
+			EventTicketing
 		} `json:"event"`
 	}
 
@@ -2170,16 +2342,23 @@ func (h *AuthHandler) processEventVenueSubmission(dataRaw []byte, userID int) bo
 		fmt.Printf("[Error] Datos raw recibidos: %s\n", string(dataRaw))
 		return false
 	}
+	if err := combined.Event.EventTicketing.normalize(); err != nil {
+		fmt.Printf("[Error] Datos de entradas inválidos: %v\n", err)
+		return false
+	}
 
 	fmt.Printf("[Info] Datos decodificados correctamente: Venue=%s, Event=%s\n",
 		combined.Venue.Name, combined.Event.Title)
 
-	// Primero insertar el venue
-	venueID, err := h.DB.Insert(false, `
-		INSERT INTO venues (name, address, description, slug, latlng, city)
-		VALUES (?, ?, ?, ?, ?, ?)`,
-		combined.Venue.Name, combined.Venue.Address, combined.Venue.Description,
-		combined.Venue.Slug, combined.Venue.LatLng, combined.Venue.City)
+	// Primero insertar el venue (valida las coordenadas)
+	venueID, err := h.insertVenue(&Venue{
+		Name:        combined.Venue.Name,
+		Address:     combined.Venue.Address,
+		Description: combined.Venue.Description,
+		Slug:        combined.Venue.Slug,
+		LatLng:      combined.Venue.LatLng,
+		City:        combined.Venue.City,
+	})
 	if err != nil {
 		fmt.Printf("[Error] No se pudo insertar el venue combinado: %v\n", err)
 		return false
@@ -2197,11 +2376,17 @@ func (h *AuthHandler) processEventVenueSubmission(dataRaw []byte, userID int) bo
 	}
 
 	// Luego insertar el evento usando el ID del venue
-	eventID, err := h.DB.Insert(false, `
-		INSERT INTO events (id_venue, title, tags, content, slug, date_start, date_end)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		venueID, combined.Event.Title, combined.Event.Tags, combined.Event.Content,
-		combined.Event.Slug, combined.Event.DateStart, combined.Event.DateEnd)
+	event := Event{
+		VenueID:        venueID,
+		Title:          combined.Event.Title,
+		Tags:           combined.Event.Tags,
+		Content:        combined.Event.Content,
+		Slug:           combined.Event.Slug,
+		DateStart:      combined.Event.DateStart,
+		DateEnd:        combined.Event.DateEnd,
+		EventTicketing: combined.Event.EventTicketing,
+	}
+	eventID, err := h.insertEvent(&event)
 	if err != nil {
 		fmt.Printf("[Error] No se pudo insertar el evento combinado: %v\n", err)
 		return false
@@ -2210,14 +2395,7 @@ func (h *AuthHandler) processEventVenueSubmission(dataRaw []byte, userID int) bo
 	fmt.Printf("[Info] Evento creado con ID: %d\n", eventID)
 
 	// mover la imagen de la submission al bucket
-	_ = moveImageInSpaces("pending/"+combined.Event.Slug+".jpg", "events/"+combined.Event.Slug+".jpg")
-
-	// publicar en IG
-	go func() {
-		if err := h.PublishEventToInstagramByID(eventID); err != nil {
-			log.Printf("[WARN] No se pudo publicar en Instagram el evento %d: %v", eventID, err)
-		}
-	}()
+	_ = moveImageInSpaces("pending/"+combined.Event.Slug+".jpg", "events/"+event.Slug+".jpg")
 
 	// Insertar relaciones en events_bands
 	for _, bandID := range combined.Event.BandIDs {
@@ -2239,39 +2417,20 @@ func (h *AuthHandler) processEventVenueSubmission(dataRaw []byte, userID int) bo
 	}
 
 	// Mover la imagen si existe
-	err = moveImageInSpaces("pending/"+combined.Event.Slug+".jpg", "events/"+combined.Event.Slug+".jpg")
+	err = moveImageInSpaces("pending/"+combined.Event.Slug+".jpg", "events/"+event.Slug+".jpg")
 	if err != nil {
-		fmt.Printf("[Warning] No se pudo mover la imagen para el evento %s: %v\n", combined.Event.Slug, err)
+		fmt.Printf("[Warning] No se pudo mover la imagen para el evento %s: %v\n", event.Slug, err)
 	} else {
-		fmt.Printf("[Info] Imagen movida correctamente para el evento %s\n", combined.Event.Slug)
+		fmt.Printf("[Info] Imagen movida correctamente para el evento %s\n", event.Slug)
 	}
 
+	event.ID = eventID
+	h.bus.Publish(EventPublished{Actor: Actor{UserID: userID}, Event: event, Origin: OriginAdmin, SubmissionType: "eventvenue"})
+
 	fmt.Printf("[Success] Evento+Venue creados automáticamente con IDs: %d, %d\n", eventID, venueID)
 	return true
 }
 
-// generateSlug genera un slug a partir de un título
-func generateSlug(title string) string {
-	// Convertir a minúsculas
-	slug := strings.ToLower(title)
-
-	// Reemplazar espacios con guiones
-	slug = strings.ReplaceAll(slug, " ", "-")
-
-	// Remover caracteres especiales
-	reg := regexp.MustCompile("[^a-z0-9-]")
-	slug = reg.ReplaceAllString(slug, "")
-
-	// Remover guiones múltiples
-	reg = regexp.MustCompile("-+")
-	slug = reg.ReplaceAllString(slug, "-")
-
-	// Remover guiones al inicio y final
-	slug = strings.Trim(slug, "-")
-
-	return slug
-}
-
 // getBucketFromConfig obtiene el nombre del bucket desde la configuración
 func (h *AuthHandler) getBucketFromConfig() string {
 	cfg, err := ini.Load("config.ini")
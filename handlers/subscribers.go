@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// subscribe conecta los efectos secundarios con los eventos de dominio. Los handlers solo
+// publican; acá se decide qué pasa después de cada hecho.
+func (h *AuthHandler) subscribe() {
+	b := h.bus
+
+	// Índice de búsqueda: síncrono, para que el contenido se encuentre apenas responde la API
+	Subscribe(b, "búsqueda", func(e BandCreated) { h.reindexSearch("band", e.Band.ID) })
+	Subscribe(b, "búsqueda", func(e BandUpdated) { h.reindexBandSearch(e.Band.ID) })
+	Subscribe(b, "búsqueda", func(e EventPublished) { h.reindexSearch("event", e.Event.ID) })
+	Subscribe(b, "búsqueda", func(e EventCancelled) { h.removeFromSearch("event", e.EventID) })
+	Subscribe(b, "búsqueda", func(e NewsPublished) { h.reindexSearch("news", e.News.ID) })
+
+	// Panel de moderación (/submissions/stream)
+	Subscribe(b, "panel de moderación", func(e SubmissionCreated) {
+		h.submissionStream.publish("created", submissionStreamData(e.Submission))
+	})
+	Subscribe(b, "panel de moderación", func(e SubmissionApproved) {
+		h.publishSubmission("approved", strconv.Itoa(e.SubmissionID))
+	})
+	Subscribe(b, "panel de moderación", func(e SubmissionRejected) {
+		h.publishSubmission("rejected", strconv.Itoa(e.SubmissionID))
+	})
+	Subscribe(b, "panel de moderación", func(e SubmissionCommented) {
+		h.publishSubmission("commented", strconv.Itoa(e.SubmissionID))
+	})
+
+	// Webhooks salientes
+	SubscribeAsync(b, "webhooks", func(e BandCreated) { h.emitWebhook("band.created", e.Band) })
+	SubscribeAsync(b, "webhooks", func(e EventPublished) { h.emitEventCreated(e.Event.ID) })
+	SubscribeAsync(b, "webhooks", func(e EventCancelled) {
+		if e.Snapshot != nil {
+			h.emitWebhook("event.cancelled", e.Snapshot)
+		}
+	})
+	SubscribeAsync(b, "webhooks", func(e NewsPublished) { h.emitWebhook("news.published", e.News) })
+	SubscribeAsync(b, "webhooks", func(e SubmissionApproved) { h.emitSubmissionApproved(strconv.Itoa(e.SubmissionID)) })
+
+	// Lo que depende de servicios externos va a la cola de trabajos: se guarda antes de que
+	// responda la API y se reintenta si falla (ver job_types.go)
+
+	// WhatsApp: aviso al administrador de cada colaboración y al autor cuando se revisa la suya
+	Subscribe(b, "whatsapp", func(e SubmissionCreated) {
+		if !e.IsAdmin {
+			enqueue(h, jobNotifyAdmin, notifyAdminPayload{SubmissionID: e.Submission.ID}, 0)
+		}
+	})
+	Subscribe(b, "whatsapp", func(e SubmissionApproved) {
+		enqueue(h, jobNotifyAuthor, notifyAuthorPayload{SubmissionID: e.SubmissionID, Approved: true}, 0)
+	})
+	Subscribe(b, "whatsapp", func(e SubmissionRejected) {
+		enqueue(h, jobNotifyAuthor, notifyAuthorPayload{SubmissionID: e.SubmissionID, Approved: false}, 0)
+	})
+	Subscribe(b, "whatsapp", func(e ArtistLinkApproved) {
+		enqueue(h, jobNotifyArtistLink, notifyArtistLinkPayload{SubmissionID: e.SubmissionID, Rol: e.Rol, ArtistName: e.ArtistName}, 0)
+	})
+
+	// Vinculaciones con venues: WhatsApp y email a los administradores y al solicitante
+	Subscribe(b, "vinculación de venues", func(e VenueClaimCreated) {
+		enqueue(h, jobNotifyVenueClaim, notifyVenueClaimPayload{SubmissionID: e.SubmissionID}, 0)
+	})
+	Subscribe(b, "vinculación de venues", func(e VenueClaimReviewed) {
+		enqueue(h, jobNotifyVenueClaimResult, notifyVenueClaimResultPayload{
+			SubmissionID: e.SubmissionID,
+			Approved:     e.Approved,
+			Comment:      e.Comment,
+			WhatsApp:     e.NotifyWhatsApp,
+		}, 0)
+	})
+
+	// Las colaboraciones de administradores se aprueban solas
+	Subscribe(b, "aprobación automática", func(e SubmissionCreated) {
+		if e.IsAdmin {
+			enqueue(h, jobAutoApprove, autoApprovePayload{SubmissionID: e.Submission.ID, UserID: e.Submission.UserID}, adminSubmissionDelay)
+		}
+	})
+
+	// Redes sociales: el contenido que llega por colaboraciones se anuncia al aprobarse
+	Subscribe(b, "redes sociales", func(e BandCreated) {
+		if e.Origin == OriginSubmission {
+			h.enqueueAnnouncement(e.SubmissionID, "band", e.Band, "bands/"+e.Band.Slug+".jpg")
+		}
+	})
+	Subscribe(b, "redes sociales", func(e EventPublished) {
+		if e.Origin == OriginSubmission {
+			h.enqueueAnnouncement(e.SubmissionID, e.SubmissionType, e.Event, "events/"+e.Event.Slug+".jpg")
+		}
+	})
+	Subscribe(b, "redes sociales", func(e NewsPublished) {
+		if e.Origin == OriginSubmission {
+			h.enqueueAnnouncement(e.SubmissionID, "news", e.News, "news/"+e.News.Slug+".jpg")
+		}
+	})
+	Subscribe(b, "instagram", func(e EventPublished) {
+		if e.Origin != OriginAPI {
+			enqueue(h, jobInstagramEvent, instagramEventPayload{EventID: e.Event.ID}, 0)
+		}
+	})
+
+	// Auditoría: cada hecho con un usuario identificado queda en la tabla logs
+	b.SubscribeAll("auditoría", func(e DomainEvent) {
+		actor, ok := e.(interface{ actorID() int })
+		if !ok || actor.actorID() == 0 {
+			return
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		_, err = h.DB.Insert(false, "INSERT INTO logs (`type`, `old_value`, `new_value`, `user_id`) VALUES (?, ?, ?, ?)",
+			e.EventName(), "", string(data), actor.actorID())
+		if err != nil {
+			fmt.Printf("[Warning] No se pudo registrar %s en la auditoría: %v\n", e.EventName(), err)
+		}
+	})
+}
+
+// enqueueAnnouncement encola la publicación en redes del contenido de una colaboración
+func (h *AuthHandler) enqueueAnnouncement(submissionID int, submissionType string, data interface{}, imagePath string) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		fmt.Printf("[Warning] No se pudo encolar la publicación de la submission %d: %v\n", submissionID, err)
+		return
+	}
+	enqueue(h, jobSocialAnnounce, socialAnnouncePayload{
+		SubmissionID:   submissionID,
+		SubmissionType: submissionType,
+		Data:           raw,
+		ImagePath:      imagePath,
+	}, 0)
+}
+
+// announceSubmission publica en redes el contenido de una colaboración aprobada y registra el resultado
+func (h *AuthHandler) announceSubmission(submissionID int, submissionType string, data interface{}, imagePath string) error {
+	// PublishToSocial lee los eventos como Event, también los que llegaron como eventvenue
+	socialType := submissionType
+	if socialType == "eventvenue" {
+		socialType = "event"
+	}
+	if err := h.PublishToSocial(socialType, data, imagePath); err != nil {
+		h.LogSocialActivity(submissionID, submissionType, false, err.Error())
+		return err
+	}
+	h.LogSocialActivity(submissionID, submissionType, true, "")
+	return nil
+}
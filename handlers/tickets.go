@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PriceTier representa un valor de entrada (anticipada, general, puerta, etc.).
+//
+// @Schema
+type PriceTier struct {
+	Name     string  `json:"name"`
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// EventTicketing agrupa la información de entradas y acceso de un evento.
+// Se embebe en Event para que los campos viajen planos en el JSON.
+//
+// @Schema
+type EventTicketing struct {
+	PriceTiers        []PriceTier `json:"price_tiers"`
+	IsFree            bool        `json:"is_free"`
+	FreeUntilCapacity bool        `json:"free_until_capacity"`
+	TicketURL         string      `json:"ticket_url"`
+	MinAge            int         `json:"min_age"`
+	DoorTime          string      `json:"door_time"`
+}
+
+// Columnas de entradas para usar en los SELECT de eventos (alias e)
+const eventTicketingColumns = `e.price_tiers, e.is_free, e.free_until_capacity, e.ticket_url, e.min_age, e.door_time`
+
+// Moneda por defecto cuando un precio no la especifica
+const defaultCurrency = "ARS"
+
+var doorTimeRegex = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+// ticketingScan recibe las columnas de entradas, que pueden venir en NULL
+type ticketingScan struct {
+	priceTiers        sql.NullString
+	isFree            sql.NullBool
+	freeUntilCapacity sql.NullBool
+	ticketURL         sql.NullString
+	minAge            sql.NullInt64
+	doorTime          sql.NullString
+}
+
+// dest devuelve los punteros en el mismo orden que eventTicketingColumns
+func (s *ticketingScan) dest() []interface{} {
+	return []interface{}{&s.priceTiers, &s.isFree, &s.freeUntilCapacity, &s.ticketURL, &s.minAge, &s.doorTime}
+}
+
+// apply vuelca los valores escaneados sobre el evento
+func (s *ticketingScan) apply(t *EventTicketing) {
+	t.PriceTiers = []PriceTier{}
+	if s.priceTiers.Valid && s.priceTiers.String != "" {
+		if err := json.Unmarshal([]byte(s.priceTiers.String), &t.PriceTiers); err != nil {
+			fmt.Printf("[Warning] price_tiers inválido: %v\n", err)
+			t.PriceTiers = []PriceTier{}
+		}
+	}
+	t.IsFree = s.isFree.Valid && s.isFree.Bool
+	t.FreeUntilCapacity = s.freeUntilCapacity.Valid && s.freeUntilCapacity.Bool
+	t.TicketURL = s.ticketURL.String
+	t.MinAge = int(s.minAge.Int64)
+	t.DoorTime = ""
+	if s.doorTime.Valid && len(s.doorTime.String) >= 5 {
+		// MySQL devuelve TIME como HH:MM:SS
+		t.DoorTime = s.doorTime.String[:5]
+	}
+}
+
+// normalize valida los datos de entradas y completa valores por defecto
+func (t *EventTicketing) normalize() error {
+	for i := range t.PriceTiers {
+		tier := &t.PriceTiers[i]
+		tier.Name = strings.TrimSpace(tier.Name)
+		if tier.Name == "" {
+			tier.Name = "General"
+		}
+		if tier.Amount < 0 {
+			return fieldError("price_tiers", "range", fmt.Sprintf("el precio de %q no puede ser negativo", tier.Name))
+		}
+		tier.Currency = strings.ToUpper(strings.TrimSpace(tier.Currency))
+		if tier.Currency == "" {
+			tier.Currency = defaultCurrency
+		}
+		if len(tier.Currency) != 3 {
+			return fieldError("price_tiers", "invalid", fmt.Sprintf("moneda inválida para %q: %s", tier.Name, tier.Currency))
+		}
+	}
+
+	// Un evento marcado como gratuito no debería tener precios cargados
+	if t.IsFree && len(t.PriceTiers) > 0 {
+		return fieldError("is_free", "invalid", "un evento gratuito no puede tener precios de entrada")
+	}
+
+	t.TicketURL = strings.TrimSpace(t.TicketURL)
+	if t.TicketURL != "" {
+		u, err := url.Parse(t.TicketURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fieldError("ticket_url", "invalid", "ticket_url inválida")
+		}
+	}
+
+	if t.MinAge < 0 || t.MinAge > 99 {
+		return fieldError("min_age", "range", "min_age debe estar entre 0 y 99")
+	}
+
+	t.DoorTime = strings.TrimSpace(t.DoorTime)
+	if len(t.DoorTime) == 8 {
+		// Aceptar HH:MM:SS
+		t.DoorTime = t.DoorTime[:5]
+	}
+	if t.DoorTime != "" && !doorTimeRegex.MatchString(t.DoorTime) {
+		return fieldError("door_time", "invalid", "door_time debe tener formato HH:MM")
+	}
+
+	return nil
+}
+
+// sqlValues devuelve los valores en el orden de las columnas
+// price_tiers, is_free, free_until_capacity, ticket_url, min_age, door_time
+func (t EventTicketing) sqlValues() []interface{} {
+	var priceTiers interface{}
+	if len(t.PriceTiers) > 0 {
+		data, _ := json.Marshal(t.PriceTiers)
+		priceTiers = string(data)
+	}
+	var ticketURL interface{}
+	if t.TicketURL != "" {
+		ticketURL = t.TicketURL
+	}
+	var doorTime interface{}
+	if t.DoorTime != "" {
+		doorTime = t.DoorTime
+	}
+	return []interface{}{priceTiers, t.IsFree, t.FreeUntilCapacity, ticketURL, t.MinAge, doorTime}
+}
+
+// formatAmount muestra el monto sin decimales cuando es entero (ej: $ 5.000)
+func formatAmount(amount float64) string {
+	if amount == float64(int64(amount)) {
+		s := strconv.FormatInt(int64(amount), 10)
+		// separador de miles con punto, como se usa en Argentina
+		var out []string
+		for len(s) > 3 {
+			out = append([]string{s[len(s)-3:]}, out...)
+			s = s[:len(s)-3]
+		}
+		out = append([]string{s}, out...)
+		return strings.Join(out, ".")
+	}
+	return strings.Replace(strconv.FormatFloat(amount, 'f', 2, 64), ".", ",", 1)
+}
+
+// PriceSummary devuelve un resumen corto de precios, ej: "Anticipada $ 4.000 ARS · Puerta $ 5.000 ARS"
+func (t EventTicketing) PriceSummary() string {
+	if t.IsFree {
+		return "Entrada libre y gratuita"
+	}
+	if t.FreeUntilCapacity {
+		return "Entrada libre hasta completar capacidad"
+	}
+	if len(t.PriceTiers) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, tier := range t.PriceTiers {
+		if tier.Amount == 0 {
+			parts = append(parts, tier.Name+" gratis")
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s $ %s %s", tier.Name, formatAmount(tier.Amount), tier.Currency))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// captionLines arma las líneas de entradas para los posteos en redes
+func (t EventTicketing) captionLines() string {
+	var lines []string
+	if t.DoorTime != "" {
+		lines = append(lines, "🚪 Apertura de puertas: "+t.DoorTime+" hs")
+	}
+	if summary := t.PriceSummary(); summary != "" {
+		lines = append(lines, "🎟️ "+summary)
+	}
+	if t.MinAge > 0 {
+		lines = append(lines, fmt.Sprintf("🔞 Solo mayores de %d años", t.MinAge))
+	}
+	if t.TicketURL != "" {
+		lines = append(lines, "🔗 Entradas: "+t.TicketURL)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// storyLine arma una línea corta para la imagen de historia
+func (t EventTicketing) storyLine() string {
+	var parts []string
+	if t.IsFree || t.FreeUntilCapacity {
+		parts = append(parts, "Entrada libre")
+	} else if len(t.PriceTiers) > 0 {
+		// En la story mostramos solo el precio más bajo
+		min := t.PriceTiers[0]
+		for _, tier := range t.PriceTiers[1:] {
+			if tier.Amount < min.Amount {
+				min = tier
+			}
+		}
+		if len(t.PriceTiers) > 1 {
+			parts = append(parts, fmt.Sprintf("Desde $ %s", formatAmount(min.Amount)))
+		} else {
+			parts = append(parts, fmt.Sprintf("$ %s", formatAmount(min.Amount)))
+		}
+	}
+	if t.MinAge > 0 {
+		parts = append(parts, fmt.Sprintf("+%d", t.MinAge))
+	}
+	if t.DoorTime != "" {
+		parts = append(parts, "Puertas "+t.DoorTime+" hs")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// eventFreeFilter devuelve la condición SQL para el filtro ?free=true|false
+func eventFreeFilter(value string) string {
+	switch value {
+	case "true", "1":
+		return " AND (e.is_free = 1 OR e.free_until_capacity = 1)"
+	case "false", "0":
+		return " AND e.is_free = 0 AND e.free_until_capacity = 0"
+	}
+	return ""
+}
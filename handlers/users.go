@@ -7,8 +7,6 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	"strconv"
-	"strings"
 	"time"
 	"unicode/utf8"
 
@@ -49,19 +47,19 @@ func (h *AuthHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Datos inválidos", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Datos inválidos")
 		return
 	}
 
 	// Validación básica
 	if input.Username == "" || input.Email == "" || input.Password == "" {
-		http.Error(w, "Faltan campos requeridos", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Faltan campos requeridos")
 		return
 	}
 
 	// Verificar si el usuario ya existe
 	if utils.UsernameExists(input.Username, h.DB) {
-		http.Error(w, "El usuario ya existe", http.StatusConflict)
+		writeError(w, r, http.StatusConflict, "El usuario ya existe")
 		return
 	}
 
@@ -71,13 +69,13 @@ func (h *AuthHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	id, err := h.DB.Insert(true, `INSERT INTO users (username, email, realName, password_hash, salt, role, provider) VALUES (?, ?, ?, ?, ?, 'user', 'local')`,
 		input.Username, input.Email, input.Name, hashedPassword, salt)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 
 	token, err := utils.GenerateAccessToken(int(id), input.Email, input.Name, "user")
 	if err != nil {
-		http.Error(w, "Error generando token", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error generando token")
 		return
 	}
 
@@ -88,24 +86,65 @@ func (h *AuthHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AuthHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.DB.Select(`SELECT id, username, role, email, realName, created_at, updated_at, provider FROM users`)
+	h.writeUserPage(w, r, maxPageLimit)
+}
+
+// usersListSpec declara los filtros y órdenes del listado de usuarios.
+// Lo comparten el listado, la tabla del panel y el conteo.
+var usersListSpec = &listSpec{
+	from:   "users",
+	id:     column("id"),
+	search: columns("username", "email", "role", "realName"),
+	filters: map[string]filterField{
+		"id":         numberFilter("id"),
+		"username":   textFilter("username"),
+		"email":      textFilter("email"),
+		"role":       textFilter("role"),
+		"provider":   enumFilter("provider"),
+		"created_at": dateFilter("created_at"),
+	},
+	sorts: map[string]string{
+		"id":         column("id"),
+		"username":   column("username"),
+		"email":      column("email"),
+		"role":       column("role"),
+		"created_at": column("created_at"),
+	},
+}
+
+// writeUserPage responde una página de usuarios con los encabezados de paginación
+func (h *AuthHandler) writeUserPage(w http.ResponseWriter, r *http.Request, defaultLimit int) {
+	p, err := parsePageRequest(r, defaultLimit)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+
+	q, err := usersListSpec.query(r, "id, username, role, email, realName, created_at, updated_at, provider")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
-	defer rows.Close()
 
 	var users []PublicUser
-	for rows.Next() {
+	page, err := h.queryPage(q, p, func(scan func(dest ...interface{}) error) error {
 		var u PublicUser
 		var createdAt, updatedAt string
-		if err := rows.Scan(&u.ID, &u.Username, &u.Role, &u.Email, &u.Name, &createdAt, &updatedAt, &u.Provider); err != nil {
-			continue
+		if err := scan(&u.ID, &u.Username, &u.Role, &u.Email, &u.Name, &createdAt, &updatedAt, &u.Provider); err != nil {
+			return err
 		}
 		u.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
 		u.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
 		users = append(users, u)
+		return nil
+	})
+	if err != nil {
+		writeListError(w, r, err)
+		return
 	}
+
+	writePageHeaders(w, r, page)
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(users)
 }
 
@@ -113,13 +152,13 @@ func (h *AuthHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 	userID := chi.URLParam(r, "id")
 	row, err := h.DB.SelectRow(`SELECT id, username, role, email, realName, created_at, updated_at, provider FROM users WHERE id = ?`, userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	var u PublicUser
 	var createdAt, updatedAt string
 	if err := row.Scan(&u.ID, &u.Username, &u.Role, &u.Email, &u.Name, &createdAt, &updatedAt, &u.Provider); err != nil {
-		http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, "Usuario no encontrado")
 		return
 	}
 	u.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
@@ -132,7 +171,7 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		Password string `json:"password"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Datos inválidos", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Datos inválidos")
 		return
 	}
 
@@ -142,23 +181,23 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		FROM users
 		WHERE email = ? AND provider = 'local'`, input.Email)
 	if err != nil {
-		http.Error(w, "Usuario no encontrado", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, "Usuario no encontrado")
 		return
 	}
 	err = row.Scan(&user.ID, &user.Email, &user.Username, &user.Name, &user.PasswordHash, &user.Salt, &user.Role)
 	if err != nil {
-		http.Error(w, "Credenciales inválidas", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, "Credenciales inválidas")
 		return
 	}
 
 	if !utils.ComparePasswords(user.PasswordHash, input.Password, user.Salt) {
-		http.Error(w, "Contraseña incorrecta", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, "Contraseña incorrecta")
 		return
 	}
 
 	token, err := utils.GenerateAccessToken(user.ID, user.Email, user.Name, user.Role)
 	if err != nil {
-		http.Error(w, "Error generando token", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error generando token")
 		return
 	}
 
@@ -178,14 +217,14 @@ func (h *AuthHandler) CreateOrLoginWithProvider(w http.ResponseWriter, r *http.R
 		Token    string `json:"token"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Datos inválidos", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Datos inválidos")
 		return
 	}
 
 	user, err := models.FindUserByEmailAndProvider(h.DB, input.Email, input.Provider)
 	if err != nil && err != sql.ErrNoRows {
 		log.Println("ERROR al buscar usuario por email y provider:", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 
@@ -201,7 +240,7 @@ func (h *AuthHandler) CreateOrLoginWithProvider(w http.ResponseWriter, r *http.R
 		id, err := h.DB.Insert(true, `INSERT INTO users (username, email, realName, role, provider) VALUES (?, ?, ?, ?, ?)`,
 			user.Username, user.Email, user.Name, user.Role, user.Provider)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "", err)
 			return
 		}
 		user.ID = int(id)
@@ -209,7 +248,7 @@ func (h *AuthHandler) CreateOrLoginWithProvider(w http.ResponseWriter, r *http.R
 
 	token, err := utils.GenerateAccessToken(user.ID, user.Email, user.Name, user.Role)
 	if err != nil {
-		http.Error(w, "Error generando token", http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error generando token")
 		return
 	}
 	json.NewEncoder(w).Encode(map[string]string{
@@ -220,137 +259,10 @@ func (h *AuthHandler) CreateOrLoginWithProvider(w http.ResponseWriter, r *http.R
 	})
 }
 func (h *AuthHandler) GetUsersDatatable(w http.ResponseWriter, r *http.Request) {
-	offsetParam := r.URL.Query().Get("offset")
-	limitParam := r.URL.Query().Get("limit")
-	search := r.URL.Query().Get("q")
-	sortBy := r.URL.Query().Get("sort")
-	order := r.URL.Query().Get("order")
-
-	idFilter := r.URL.Query().Get("id")
-	usernameFilter := r.URL.Query().Get("username")
-	emailFilter := r.URL.Query().Get("email")
-	roleFilter := r.URL.Query().Get("role")
-
-	offset := 0
-	limit := 10
-
-	if offsetParam != "" {
-		offset, _ = strconv.Atoi(offsetParam)
-	}
-	if limitParam != "" {
-		limit, _ = strconv.Atoi(limitParam)
-	}
-
-	query := `
-		SELECT id, username, role, email, realName, created_at, updated_at, provider
-		FROM users
-		WHERE 1=1
-	`
-	var queryParams []interface{}
-
-	if search != "" {
-		pattern := "%" + search + "%"
-		query += ` AND (username LIKE ? OR email LIKE ? OR role LIKE ? OR realName LIKE ?)`
-		queryParams = append(queryParams, pattern, pattern, pattern, pattern)
-	}
-	if idFilter != "" {
-		query += " AND id LIKE ?"
-		queryParams = append(queryParams, "%"+idFilter+"%")
-	}
-	if usernameFilter != "" {
-		query += " AND username LIKE ?"
-		queryParams = append(queryParams, "%"+usernameFilter+"%")
-	}
-	if emailFilter != "" {
-		query += " AND email LIKE ?"
-		queryParams = append(queryParams, "%"+emailFilter+"%")
-	}
-	if roleFilter != "" {
-		query += " AND role LIKE ?"
-		queryParams = append(queryParams, "%"+roleFilter+"%")
-	}
-
-	if sortBy != "" {
-		validSorts := map[string]bool{"id": true, "username": true, "email": true, "role": true}
-		if validSorts[sortBy] {
-			if order != "desc" {
-				order = "asc"
-			}
-			query += " ORDER BY " + sortBy + " " + strings.ToUpper(order)
-		}
-	}
-
-	query += " LIMIT ? OFFSET ?"
-	queryParams = append(queryParams, limit, offset)
-
-	rows, err := h.DB.Select(query, queryParams...)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	var users []PublicUser
-	for rows.Next() {
-		var u PublicUser
-		var createdAt, updatedAt string
-		if err := rows.Scan(&u.ID, &u.Username, &u.Role, &u.Email, &u.Name, &createdAt, &updatedAt, &u.Provider); err != nil {
-			continue
-		}
-		u.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
-		u.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
-		users = append(users, u)
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(users)
+	h.writeUserPage(w, r, 10)
 }
 func (h *AuthHandler) GetUsersCount(w http.ResponseWriter, r *http.Request) {
-	search := r.URL.Query().Get("q")
-	idFilter := r.URL.Query().Get("id")
-	usernameFilter := r.URL.Query().Get("username")
-	emailFilter := r.URL.Query().Get("email")
-	roleFilter := r.URL.Query().Get("role")
-
-	query := `SELECT COUNT(*) FROM users WHERE 1=1`
-	var queryParams []interface{}
-
-	if search != "" {
-		pattern := "%" + search + "%"
-		query += ` AND (username LIKE ? OR email LIKE ? OR role LIKE ? OR realName LIKE ?)`
-		queryParams = append(queryParams, pattern, pattern, pattern, pattern)
-	}
-	if idFilter != "" {
-		query += " AND id LIKE ?"
-		queryParams = append(queryParams, "%"+idFilter+"%")
-	}
-	if usernameFilter != "" {
-		query += " AND username LIKE ?"
-		queryParams = append(queryParams, "%"+usernameFilter+"%")
-	}
-	if emailFilter != "" {
-		query += " AND email LIKE ?"
-		queryParams = append(queryParams, "%"+emailFilter+"%")
-	}
-	if roleFilter != "" {
-		query += " AND role LIKE ?"
-		queryParams = append(queryParams, "%"+roleFilter+"%")
-	}
-
-	row, err := h.DB.SelectRow(query, queryParams...)
-	if err != nil {
-		http.Error(w, "Error al contar usuarios", http.StatusInternalServerError)
-		return
-	}
-
-	var count int
-	if err := row.Scan(&count); err != nil {
-		http.Error(w, "Error al leer el conteo", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]int{"count": count})
+	h.writeCount(w, r, usersListSpec)
 }
 
 func (h *AuthHandler) CreateArtistLinkRequest(w http.ResponseWriter, r *http.Request) {
@@ -361,13 +273,13 @@ func (h *AuthHandler) CreateArtistLinkRequest(w http.ResponseWriter, r *http.Req
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Datos inválidos", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Datos inválidos")
 		return
 	}
 
 	// Validaciones mínimas
 	if input.UserID == 0 || input.ArtistID == 0 || input.Rol == "" {
-		http.Error(w, "Faltan campos requeridos", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Faltan campos requeridos")
 		return
 	}
 
@@ -378,7 +290,7 @@ func (h *AuthHandler) CreateArtistLinkRequest(w http.ResponseWriter, r *http.Req
 	`, input.UserID, input.ArtistID, input.Rol)
 
 	if err != nil {
-		http.Error(w, "Error al guardar la solicitud: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "Error al guardar la solicitud", err)
 		return
 	}
 
@@ -393,7 +305,7 @@ func (h *AuthHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	userID := chi.URLParam(r, "id")
 	_, err := h.DB.Delete(true, "DELETE FROM users WHERE id = ?", userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
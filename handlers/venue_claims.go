@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"brotecolectivo/models"
+	"brotecolectivo/utils"
+
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/ini.v1"
+)
+
+// VenueClaim es la solicitud de un usuario para vincularse a un venue existente.
+// Se guarda como data de una submission de tipo venue_link.
+//
+// @Schema
+type VenueClaim struct {
+	VenueID      int    `json:"venue_id"`
+	Name         string `json:"name"` // Nombre del venue (se completa desde la base)
+	Slug         string `json:"slug"` // Slug del venue (se completa desde la base)
+	Rol          string `json:"rol"`  // Rol del usuario en el venue: dueño, programación, prensa, etc.
+	ContactName  string `json:"contact_name"`
+	ContactEmail string `json:"contact_email"`
+	WhatsApp     string `json:"whatsapp"`
+	ProofURL     string `json:"proof_url"` // Enlace que respalde la vinculación (web, red social, nota)
+	Message      string `json:"message"`
+}
+
+// normalize valida la evidencia de la solicitud
+func (c *VenueClaim) normalize() error {
+	c.Rol = strings.TrimSpace(c.Rol)
+	if c.Rol == "" {
+		return fieldError("rol", "required", "el rol es obligatorio")
+	}
+	if len(c.Rol) > 50 {
+		return fieldError("rol", "range", "el rol no puede superar los 50 caracteres")
+	}
+
+	c.ContactName = strings.TrimSpace(c.ContactName)
+	c.ContactEmail = strings.TrimSpace(c.ContactEmail)
+	c.WhatsApp = strings.TrimSpace(c.WhatsApp)
+	if c.ContactEmail == "" && c.WhatsApp == "" {
+		return fieldError("contact_email", "required", "se necesita un email o WhatsApp de contacto")
+	}
+	if c.ContactEmail != "" {
+		addr, err := mail.ParseAddress(c.ContactEmail)
+		if err != nil || addr.Address != c.ContactEmail {
+			return fieldError("contact_email", "invalid", "contact_email inválido")
+		}
+	}
+
+	c.ProofURL = strings.TrimSpace(c.ProofURL)
+	if c.ProofURL == "" {
+		return fieldError("proof_url", "required", "proof_url es obligatorio")
+	}
+	u, err := url.Parse(c.ProofURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fieldError("proof_url", "invalid", "proof_url inválida")
+	}
+
+	c.Message = strings.TrimSpace(c.Message)
+	return nil
+}
+
+// CreateVenueClaim crea una solicitud de vinculación con un venue existente.
+//
+// @Summary Solicitar vinculación con un venue
+// @Description Crea una submission de tipo venue_link con la evidencia aportada. Un moderador la aprueba o rechaza desde la cola de submissions.
+// @Tags venues
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del venue"
+// @Param claim body VenueClaim true "Rol, contacto y evidencia"
+// @Success 201 {object} map[string]interface{} "Solicitud creada"
+// @Failure 400 {string} string "Datos inválidos"
+// @Failure 401 {string} string "Usuario no autenticado"
+// @Failure 404 {string} string "Venue no encontrado"
+// @Failure 409 {string} string "Ya existe una vinculación o una solicitud pendiente"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /venues/{id}/claim [post]
+func (h *AuthHandler) CreateVenueClaim(w http.ResponseWriter, r *http.Request) {
+	venueID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "Usuario no autenticado")
+		return
+	}
+	userID := int(claims.UserID)
+
+	var claim VenueClaim
+	if err := json.NewDecoder(r.Body).Decode(&claim); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar")
+		return
+	}
+	if err := claim.normalize(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+
+	// Los datos del venue se toman de la base, no del cliente
+	row, err := h.DB.SelectRow("SELECT id, name, slug FROM venues WHERE id = ?", venueID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener el venue")
+		return
+	}
+	if err := row.Scan(&claim.VenueID, &claim.Name, &claim.Slug); err != nil {
+		writeError(w, r, http.StatusNotFound, "Venue no encontrado")
+		return
+	}
+
+	var linked bool
+	row, err = h.DB.SelectRow(`
+		SELECT EXISTS(SELECT 1 FROM venue_links WHERE user_id = ? AND venue_id = ? AND status = 'approved')`,
+		userID, venueID)
+	if err == nil && row.Scan(&linked) == nil && linked {
+		writeError(w, r, http.StatusConflict, "Ya estás vinculado a este venue")
+		return
+	}
+
+	var pending bool
+	row, err = h.DB.SelectRow(`
+		SELECT EXISTS(SELECT 1 FROM submissions
+			WHERE user_id = ? AND type = 'venue_link' AND status = 'pending'
+			  AND JSON_EXTRACT(data, '$.venue_id') = ?)`,
+		userID, venueID)
+	if err == nil && row.Scan(&pending) == nil && pending {
+		writeError(w, r, http.StatusConflict, "Ya tenés una solicitud pendiente para este venue")
+		return
+	}
+
+	data, _ := json.Marshal(claim)
+	submissionID, err := h.DB.Insert(false, `
+		INSERT INTO submissions (user_id, type, data, status, updated_at)
+		VALUES (?, 'venue_link', ?, 'pending', NOW())`,
+		userID, string(data))
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al guardar la solicitud", err)
+		return
+	}
+
+	// Igual que en artist_link, el WhatsApp queda en el comentario para avisar el resultado
+	if claim.WhatsApp != "" {
+		_, _ = h.DB.Update(false, `UPDATE submissions SET comment = ? WHERE id = ?`,
+			fmt.Sprintf("WhatsApp: %s", claim.WhatsApp), submissionID)
+	}
+
+	if err := h.upsertVenueLink(userID, venueID, claim.Rol, "pending"); err != nil {
+		fmt.Printf("[Warning] No se pudo registrar el vínculo pendiente del venue %d: %v\n", venueID, err)
+	}
+
+	h.bus.Publish(VenueClaimCreated{Actor: Actor{UserID: userID}, SubmissionID: submissionID, VenueID: venueID})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "ok",
+		"id":      submissionID,
+		"message": "Solicitud enviada",
+	})
+}
+
+// parseVenueClaim lee la data de una submission venue_link
+func parseVenueClaim(dataRaw []byte) (VenueClaim, error) {
+	var claim VenueClaim
+	if err := json.Unmarshal(dataRaw, &claim); err != nil {
+		return claim, err
+	}
+	if claim.VenueID <= 0 {
+		return claim, fmt.Errorf("venue_id inválido")
+	}
+	return claim, nil
+}
+
+// upsertVenueLink crea o actualiza el vínculo entre usuario y venue con el estado indicado
+func (h *AuthHandler) upsertVenueLink(userID, venueID int, rol, status string) error {
+	var exists bool
+	row, err := h.DB.SelectRow("SELECT EXISTS(SELECT 1 FROM venue_links WHERE user_id = ? AND venue_id = ?)",
+		userID, venueID)
+	if err != nil {
+		return err
+	}
+	if err := row.Scan(&exists); err != nil {
+		return err
+	}
+
+	if exists {
+		// Un vínculo aprobado no se degrada por una nueva solicitud
+		_, err = h.DB.Update(false, `
+			UPDATE venue_links SET rol = ?, status = ?
+			WHERE user_id = ? AND venue_id = ? AND (status <> 'approved' OR ? = 'approved')`,
+			rol, status, userID, venueID, status)
+		return err
+	}
+
+	_, err = h.DB.Insert(false, `INSERT INTO venue_links (user_id, venue_id, rol, status) VALUES (?, ?, ?, ?)`,
+		userID, venueID, rol, status)
+	return err
+}
+
+// approveVenueClaim aprueba el vínculo solicitado en una submission venue_link
+func (h *AuthHandler) approveVenueClaim(userID int, dataRaw []byte) (VenueClaim, error) {
+	claim, err := parseVenueClaim(dataRaw)
+	if err != nil {
+		return claim, err
+	}
+	if userID <= 0 {
+		return claim, fmt.Errorf("user_id inválido")
+	}
+	return claim, h.upsertVenueLink(userID, claim.VenueID, claim.Rol, "approved")
+}
+
+// rejectVenueClaim marca como rechazado el vínculo pendiente de una submission venue_link
+func (h *AuthHandler) rejectVenueClaim(userID int, dataRaw []byte) (VenueClaim, error) {
+	claim, err := parseVenueClaim(dataRaw)
+	if err != nil {
+		return claim, err
+	}
+	_, err = h.DB.Update(false, `
+		UPDATE venue_links SET status = 'rejected'
+		WHERE user_id = ? AND venue_id = ? AND status = 'pending'`,
+		userID, claim.VenueID)
+	return claim, err
+}
+
+// processVenueClaimSubmission procesa una submission venue_link creada por un admin
+func (h *AuthHandler) processVenueClaimSubmission(dataRaw []byte, userID int) bool {
+	if _, err := h.approveVenueClaim(userID, dataRaw); err != nil {
+		fmt.Printf("[Error] No se pudo aprobar la vinculación con el venue: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// venueClaimSubmission lee una submission venue_link con la solicitud que guarda
+func (h *AuthHandler) venueClaimSubmission(submissionID int) (Submission, VenueClaim, error) {
+	sub := Submission{ID: submissionID, Type: "venue_link"}
+	row, err := h.DB.SelectRow("SELECT user_id, data FROM submissions WHERE id = ? AND type = 'venue_link'", submissionID)
+	if err != nil {
+		return sub, VenueClaim{}, err
+	}
+	if err := row.Scan(&sub.UserID, &sub.Data); err != nil {
+		return sub, VenueClaim{}, err
+	}
+	claim, err := parseVenueClaim(sub.Data)
+	return sub, claim, err
+}
+
+// notifyVenueClaimCreated avisa a los administradores por WhatsApp y email que hay una solicitud nueva
+func (h *AuthHandler) notifyVenueClaimCreated(sub Submission, claim VenueClaim) error {
+	cfg, err := ini.Load("data.conf")
+	if err != nil {
+		return fmt.Errorf("no se pudo cargar la configuración para notificar la solicitud: %w", err)
+	}
+
+	var errs []error
+	description := fmt.Sprintf("%s solicita vincularse como %s. Evidencia: %s", claim.ContactName, claim.Rol, claim.ProofURL)
+	if adminPhone := cfg.Section("keys").Key("admin_phone").String(); adminPhone != "" {
+		if err := sendSubmissionWhatsApp(adminPhone, sub, claim.Name, description, claim.Slug, cfg); err != nil {
+			errs = append(errs, fmt.Errorf("WhatsApp de solicitud de venue: %w", err))
+		}
+	}
+
+	if adminEmail := cfg.Section("keys").Key("admin_email").String(); adminEmail != "" {
+		body := fmt.Sprintf(`
+		<p>Nueva solicitud de vinculación con el venue <strong>%s</strong> (submission #%d).</p>
+		<ul>
+			<li>Rol: %s</li>
+			<li>Contacto: %s %s %s</li>
+			<li>Evidencia: <a href="%s">%s</a></li>
+		</ul>
+		<p>%s</p>`,
+			html.EscapeString(claim.Name), sub.ID,
+			html.EscapeString(claim.Rol),
+			html.EscapeString(claim.ContactName), html.EscapeString(claim.ContactEmail), html.EscapeString(claim.WhatsApp),
+			html.EscapeString(claim.ProofURL), html.EscapeString(claim.ProofURL),
+			html.EscapeString(claim.Message))
+		if err := utils.SendEmail(adminEmail, "Nueva solicitud de vinculación con venue", body); err != nil {
+			errs = append(errs, fmt.Errorf("email de solicitud de venue: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notifyVenueClaimResult avisa al solicitante si su vinculación fue aprobada o rechazada.
+// withWhatsApp permite omitir el WhatsApp cuando el flujo que llama ya lo envía.
+func (h *AuthHandler) notifyVenueClaimResult(userID int, claim VenueClaim, approved bool, comment string, withWhatsApp bool) error {
+	var message string
+	if approved {
+		message = fmt.Sprintf("¡Buenas noticias! Tu vinculación como %s de %s en Brote Colectivo fue aprobada. Ya podés editar el perfil del espacio.", claim.Rol, claim.Name)
+	} else {
+		message = fmt.Sprintf("Tu solicitud de vinculación con %s en Brote Colectivo fue rechazada.", claim.Name)
+	}
+	if comment != "" {
+		message += "\n\nComentario: " + comment
+	}
+
+	var errs []error
+	if withWhatsApp && claim.WhatsApp != "" {
+		if err := h.sendWhatsAppMessage(claim.WhatsApp, message); err != nil {
+			errs = append(errs, fmt.Errorf("WhatsApp de vinculación de venue: %w", err))
+		}
+	}
+
+	email := claim.ContactEmail
+	if email == "" {
+		row, err := h.DB.SelectRow("SELECT email FROM users WHERE id = ?", userID)
+		if err == nil {
+			row.Scan(&email)
+		}
+	}
+	if email != "" {
+		body := "<p>" + strings.ReplaceAll(html.EscapeString(message), "\n", "<br>") + "</p>"
+		if err := utils.SendEmail(email, "Vinculación con venue - Brote Colectivo", body); err != nil {
+			errs = append(errs, fmt.Errorf("email de vinculación de venue: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
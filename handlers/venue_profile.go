@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"brotecolectivo/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// VenueAccessibility describe las condiciones de accesibilidad del espacio.
+//
+// @Schema
+type VenueAccessibility struct {
+	StepFree           bool   `json:"step_free"`           // Ingreso sin escalones o con rampa
+	AccessibleRestroom bool   `json:"accessible_restroom"` // Baño adaptado
+	ReservedSeating    bool   `json:"reserved_seating"`    // Espacio reservado para sillas de ruedas
+	HearingLoop        bool   `json:"hearing_loop"`        // Aro magnético para audífonos
+	Notes              string `json:"notes"`
+}
+
+// VenueHours es una franja horaria de apertura para un día de la semana.
+//
+// @Schema
+type VenueHours struct {
+	Day   string `json:"day"`   // lunes, martes, ..., domingo
+	Open  string `json:"open"`  // HH:MM
+	Close string `json:"close"` // HH:MM (puede ser menor a open si cierra pasada la medianoche)
+}
+
+// VenueProfile agrupa la información técnica y de contacto de un venue.
+// Se embebe en Venue para que los campos viajen planos en el JSON.
+//
+// @Schema
+type VenueProfile struct {
+	Capacity      int                `json:"capacity"`
+	StageInfo     string             `json:"stage_info"`
+	Backline      []string           `json:"backline"`
+	Accessibility VenueAccessibility `json:"accessibility"`
+	OpeningHours  []VenueHours       `json:"opening_hours"`
+	ContactEmail  string             `json:"contact_email"`
+	ContactPhone  string             `json:"contact_phone"`
+	Social        map[string]string  `json:"social"`
+}
+
+// Columnas de perfil para usar en los SELECT de venues (alias v)
+const venueProfileColumns = `v.capacity, v.stage_info, v.backline, v.accessibility, v.opening_hours, v.contact_email, v.contact_phone, v.social`
+
+// Columnas de perfil en el orden de VenueProfile.sqlValues, para INSERT y UPDATE
+const venueProfileWriteColumns = `capacity, stage_info, backline, accessibility, is_accessible, opening_hours, contact_email, contact_phone, social`
+
+// Capacidad máxima razonable para validar errores de carga
+const maxVenueCapacity = 100000
+
+var venueWeekDays = map[string]bool{
+	"lunes": true, "martes": true, "miercoles": true, "jueves": true,
+	"viernes": true, "sabado": true, "domingo": true,
+}
+
+// venueProfileScan recibe las columnas de perfil, que pueden venir en NULL
+type venueProfileScan struct {
+	capacity      sql.NullInt64
+	stageInfo     sql.NullString
+	backline      sql.NullString
+	accessibility sql.NullString
+	openingHours  sql.NullString
+	contactEmail  sql.NullString
+	contactPhone  sql.NullString
+	social        sql.NullString
+}
+
+// dest devuelve los punteros en el mismo orden que venueProfileColumns
+func (s *venueProfileScan) dest() []interface{} {
+	return []interface{}{&s.capacity, &s.stageInfo, &s.backline, &s.accessibility,
+		&s.openingHours, &s.contactEmail, &s.contactPhone, &s.social}
+}
+
+// apply vuelca los valores escaneados sobre el perfil
+func (s *venueProfileScan) apply(p *VenueProfile) {
+	p.Capacity = int(s.capacity.Int64)
+	p.StageInfo = s.stageInfo.String
+	p.ContactEmail = s.contactEmail.String
+	p.ContactPhone = s.contactPhone.String
+
+	p.Backline = []string{}
+	p.Accessibility = VenueAccessibility{}
+	p.OpeningHours = []VenueHours{}
+	p.Social = map[string]string{}
+	unmarshalVenueJSON("backline", s.backline, &p.Backline)
+	unmarshalVenueJSON("accessibility", s.accessibility, &p.Accessibility)
+	unmarshalVenueJSON("opening_hours", s.openingHours, &p.OpeningHours)
+	unmarshalVenueJSON("social", s.social, &p.Social)
+}
+
+// unmarshalVenueJSON decodifica una columna JSON, dejando el valor por defecto si está vacía o es inválida
+func unmarshalVenueJSON(column string, value sql.NullString, target interface{}) {
+	if !value.Valid || value.String == "" {
+		return
+	}
+	if err := json.Unmarshal([]byte(value.String), target); err != nil {
+		fmt.Printf("[Warning] %s inválido en venue: %v\n", column, err)
+	}
+}
+
+// Accessible indica si el venue se considera accesible para el filtro ?accessible=true
+func (a VenueAccessibility) Accessible() bool {
+	return a.StepFree
+}
+
+// normalize valida el perfil y limpia los valores
+func (p *VenueProfile) normalize() error {
+	if p.Capacity < 0 || p.Capacity > maxVenueCapacity {
+		return fieldError("capacity", "range", fmt.Sprintf("capacity debe estar entre 0 y %d", maxVenueCapacity))
+	}
+
+	p.StageInfo = strings.TrimSpace(p.StageInfo)
+
+	backline := []string{}
+	for _, item := range p.Backline {
+		if item = strings.TrimSpace(item); item != "" {
+			backline = append(backline, item)
+		}
+	}
+	p.Backline = backline
+
+	p.Accessibility.Notes = strings.TrimSpace(p.Accessibility.Notes)
+
+	for i := range p.OpeningHours {
+		hours := &p.OpeningHours[i]
+		hours.Day = strings.ToLower(strings.TrimSpace(hours.Day))
+		hours.Day = strings.NewReplacer("é", "e", "á", "a").Replace(hours.Day)
+		if !venueWeekDays[hours.Day] {
+			return fieldError("opening_hours", "invalid", "día inválido en opening_hours: "+hours.Day)
+		}
+		if !doorTimeRegex.MatchString(hours.Open) || !doorTimeRegex.MatchString(hours.Close) {
+			return fieldError("opening_hours", "invalid", fmt.Sprintf("los horarios de %s deben tener formato HH:MM", hours.Day))
+		}
+	}
+
+	p.ContactEmail = strings.TrimSpace(p.ContactEmail)
+	if p.ContactEmail != "" {
+		addr, err := mail.ParseAddress(p.ContactEmail)
+		if err != nil || addr.Address != p.ContactEmail {
+			return fieldError("contact_email", "invalid", "contact_email inválido")
+		}
+	}
+
+	p.ContactPhone = strings.TrimSpace(p.ContactPhone)
+	if p.ContactPhone != "" {
+		digits := 0
+		for _, c := range p.ContactPhone {
+			switch {
+			case c >= '0' && c <= '9':
+				digits++
+			case strings.ContainsRune("+-() ", c):
+			default:
+				return fieldError("contact_phone", "invalid", "contact_phone inválido")
+			}
+		}
+		if digits < 6 || digits > 15 {
+			return fieldError("contact_phone", "invalid", "contact_phone inválido")
+		}
+	}
+
+	social := map[string]string{}
+	for platform, link := range p.Social {
+		platform = strings.ToLower(strings.TrimSpace(platform))
+		link = strings.TrimSpace(link)
+		if platform == "" || link == "" {
+			continue
+		}
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("enlace inválido para %s", platform)
+		}
+		social[platform] = link
+	}
+	p.Social = social
+
+	return nil
+}
+
+// sqlValues devuelve los valores en el orden de venueProfileWriteColumns
+func (p VenueProfile) sqlValues() []interface{} {
+	var capacity interface{}
+	if p.Capacity > 0 {
+		capacity = p.Capacity
+	}
+	backline, _ := json.Marshal(p.Backline)
+	accessibility, _ := json.Marshal(p.Accessibility)
+	openingHours, _ := json.Marshal(p.OpeningHours)
+	social, _ := json.Marshal(p.Social)
+	return []interface{}{
+		capacity, nullableString(p.StageInfo), string(backline), string(accessibility),
+		p.Accessibility.Accessible(), string(openingHours),
+		nullableString(p.ContactEmail), nullableString(p.ContactPhone), string(social),
+	}
+}
+
+// venueProfileFilters arma las condiciones SQL para ?accessible=true y ?min_capacity=N
+func venueProfileFilters(r *http.Request) (string, []interface{}, error) {
+	var clause string
+	var params []interface{}
+
+	switch r.URL.Query().Get("accessible") {
+	case "true", "1":
+		clause += " AND v.is_accessible = 1"
+	case "false", "0":
+		clause += " AND (v.is_accessible = 0 OR v.is_accessible IS NULL)"
+	}
+
+	if minCapacity := r.URL.Query().Get("min_capacity"); minCapacity != "" {
+		value, err := strconv.Atoi(minCapacity)
+		if err != nil || value < 0 {
+			return "", nil, fieldError("min_capacity", "invalid", "min_capacity inválido")
+		}
+		clause += " AND v.capacity >= ?"
+		params = append(params, value)
+	}
+
+	return clause, params, nil
+}
+
+// canEditVenue indica si el usuario puede editar el venue: admins o usuarios con un vínculo aprobado
+func (h *AuthHandler) canEditVenue(claims *models.Claims, venueID int) (bool, error) {
+	if claims.Role == "admin" {
+		return true, nil
+	}
+	row, err := h.DB.SelectRow(`
+		SELECT EXISTS(SELECT 1 FROM venue_links WHERE user_id = ? AND venue_id = ? AND status = 'approved')`,
+		claims.UserID, venueID)
+	if err != nil {
+		return false, err
+	}
+	var linked bool
+	if err := row.Scan(&linked); err != nil {
+		return false, err
+	}
+	return linked, nil
+}
+
+// UpdateVenueProfile actualiza capacidad, escenario, accesibilidad, horarios y contacto de un venue.
+//
+// @Summary Actualizar perfil de venue
+// @Description Actualiza los datos técnicos y de contacto del venue. Requiere ser admin o tener un vínculo aprobado con el venue.
+// @Tags venues
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del venue"
+// @Param profile body VenueProfile true "Perfil del venue"
+// @Success 200 {object} VenueProfile "Perfil actualizado"
+// @Failure 400 {string} string "Datos inválidos"
+// @Failure 401 {string} string "Usuario no autenticado"
+// @Failure 403 {string} string "Sin permiso para editar el venue"
+// @Failure 404 {string} string "Venue no encontrado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /venues/{id}/profile [put]
+func (h *AuthHandler) UpdateVenueProfile(w http.ResponseWriter, r *http.Request) {
+	venueID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "Usuario no autenticado")
+		return
+	}
+
+	var profile VenueProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar")
+		return
+	}
+	if err := profile.normalize(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+
+	allowed, err := h.canEditVenue(claims, venueID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al verificar permisos")
+		return
+	}
+	if !allowed {
+		writeError(w, r, http.StatusForbidden, "No tienes permiso para editar este venue")
+		return
+	}
+
+	params := append(profile.sqlValues(), venueID)
+	affected, err := h.DB.Update(false, `
+		UPDATE venues SET capacity=?, stage_info=?, backline=?, accessibility=?, is_accessible=?,
+			opening_hours=?, contact_email=?, contact_phone=?, social=?
+		WHERE id = ?`, params...)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "", err)
+		return
+	}
+	if affected == 0 {
+		// Puede ser que no exista o que no haya cambios
+		row, err := h.DB.SelectRow("SELECT EXISTS(SELECT 1 FROM venues WHERE id = ?)", venueID)
+		var exists bool
+		if err == nil && row.Scan(&exists) == nil && !exists {
+			writeError(w, r, http.StatusNotFound, "Venue no encontrado")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
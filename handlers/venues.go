@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"brotecolectivo/models"
 
@@ -20,29 +21,154 @@ type Venue struct {
 	Slug        string `json:"slug"`
 	LatLng      string `json:"latlng"`
 	City        string `json:"city"`
+
+	// Coordenadas validadas (se derivan de latlng)
+	Lat      *float64 `json:"lat,omitempty"`
+	Lng      *float64 `json:"lng,omitempty"`
+	Distance *float64 `json:"distance_km,omitempty"`
+
+	VenueProfile
 }
 
+// insertVenue valida las coordenadas y el perfil e inserta el venue, devolviendo el ID creado.
+// v.Slug queda con el slug definitivo (si estaba ocupado se prueba primero con la ciudad).
+func (h *AuthHandler) insertVenue(v *Venue) (int, error) {
+	location, err := normalizeVenueLocation(v)
+	if err != nil {
+		return 0, err
+	}
+	if err := v.VenueProfile.normalize(); err != nil {
+		return 0, err
+	}
+	slug, err := h.assignSlug("venue", v.Slug, v.Name, v.City)
+	if err != nil {
+		return 0, err
+	}
+	v.Slug = slug
+	defer h.releaseSlug("venue", slug)
+	params := []interface{}{v.Name, v.Address, v.Description, v.Slug, v.LatLng, location, v.City}
+	params = append(params, v.VenueProfile.sqlValues()...)
+	id, err := h.DB.Insert(false, `
+		INSERT INTO venues (name, address, description, slug, latlng, location, city, `+venueProfileWriteColumns+`)
+		VALUES (?, ?, ?, ?, ?, `+geoPointSQL+`, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		params...)
+	if err != nil {
+		return 0, err
+	}
+	h.reindexSearch("venue", id)
+	return id, nil
+}
+
+// venueListSpec declara los filtros y órdenes del listado de venues, por defecto alfabético
+var venueListSpec = &listSpec{
+	from:   "venues v",
+	id:     column("v.id"),
+	search: columns("v.name", "v.address", "v.city"),
+	filters: map[string]filterField{
+		"id":   numberFilter("v.id"),
+		"slug": textFilter("v.slug"),
+		"name": textFilter("v.name"),
+		"city": textFilter("v.city"),
+	},
+	sorts:       map[string]string{"id": column("v.id"), "name": column("v.name")},
+	defaultSort: "name",
+	apply: func(r *http.Request, q *listQuery) error {
+		profileFilter, profileParams, err := venueProfileFilters(r)
+		if err != nil {
+			return err
+		}
+		if profileFilter != "" {
+			q.where(strings.TrimPrefix(profileFilter, " AND "), profileParams...)
+		}
+		return nil
+	},
+}
+
+// GetVenues devuelve todos los venues. Con ?near=lat,lng (y opcionalmente &radius=km)
+// devuelve solo los venues con coordenadas, ordenados por distancia.
+//
+// @Summary Listar venues
+// @Description Obtiene los venues, opcionalmente filtrados por accesibilidad, capacidad y cercanía
+// @Tags venues
+// @Produce json
+// @Param near query string false "Centro de búsqueda (lat,lng)"
+// @Param radius query number false "Radio en kilómetros"
+// @Param accessible query bool false "Solo venues con ingreso accesible"
+// @Param min_capacity query int false "Capacidad mínima"
+// @Param limit query int false "Cantidad de venues (por defecto y máximo 100)"
+// @Param offset query int false "Desplazamiento para paginación"
+// @Param cursor query string false "Cursor de la página siguiente (X-Next-Cursor, no disponible con near)"
+// @Param total query bool false "Incluir el total en X-Total-Count"
+// @Success 200 {array} Venue "Lista de venues"
+// @Failure 400 {string} string "Parámetros inválidos"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Router /venues [get]
 func (h *AuthHandler) GetVenues(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.DB.Select(`SELECT id, name, address, description, slug, latlng, city FROM venues ORDER BY name ASC`)
+	p, err := parsePageRequest(r, maxPageLimit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
-	defer rows.Close()
 
-	var venues []Venue
-	for rows.Next() {
-		var v Venue
-		if err := rows.Scan(&v.ID, &v.Name, &v.Address, &v.Description, &v.Slug, &v.LatLng, &v.City); err != nil {
-			continue
-		}
-		venues = append(venues, v)
+	venues, page, err := h.queryVenues(r, p)
+	if err != nil {
+		writeListError(w, r, err)
+		return
 	}
 
+	writePageHeaders(w, r, page)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(venues)
 }
 
+// queryVenues lee una página de venues con su perfil. Con near ordena por cercanía.
+func (h *AuthHandler) queryVenues(r *http.Request, p *pageRequest) ([]Venue, *pageInfo, error) {
+	near, radius, err := nearParams(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q, err := venueListSpec.query(r, `v.id, v.name, v.address, v.description, v.slug, v.latlng, v.city, `+venueLatLngSQL+`, `+venueProfileColumns)
+	if err != nil {
+		return nil, nil, err
+	}
+	if near != nil {
+		q.columns += ", " + venueDistanceSQL + " AS distance"
+		q.columnArgs = append(q.columnArgs, near.WKT())
+		q.where("v.location IS NOT NULL")
+		if radius > 0 {
+			q.where(venueDistanceSQL+" <= ?", near.WKT(), radius)
+		}
+		// La distancia es calculada, así que este orden se pagina con offset
+		q.orderBy("distance", "distance", false)
+		q.keyset = false
+	}
+
+	var venues []Venue
+	page, err := h.queryPage(q, p, func(scan func(dest ...interface{}) error) error {
+		var v Venue
+		var lat, lng sql.NullFloat64
+		var pr venueProfileScan
+		dest := []interface{}{&v.ID, &v.Name, &v.Address, &v.Description, &v.Slug, &v.LatLng, &v.City, &lat, &lng}
+		dest = append(dest, pr.dest()...)
+		var distance float64
+		if near != nil {
+			dest = append(dest, &distance)
+		}
+		if err := scan(dest...); err != nil {
+			return err
+		}
+		applyVenueCoordinates(&v, lat, lng)
+		pr.apply(&v.VenueProfile)
+		if near != nil {
+			v.Distance = &distance
+		}
+		venues = append(venues, v)
+		return nil
+	})
+	return venues, page, err
+}
+
 func (h *AuthHandler) GetVenueByIDOrSlug(w http.ResponseWriter, r *http.Request) {
 	param := chi.URLParam(r, "id") // puede ser numérico o string
 	var v Venue
@@ -52,25 +178,36 @@ func (h *AuthHandler) GetVenueByIDOrSlug(w http.ResponseWriter, r *http.Request)
 	if _, errConv := strconv.Atoi(param); errConv == nil {
 		// Es un número → buscar por ID
 		row, err = h.DB.SelectRow(`
-			SELECT id, name, address, description, slug, latlng, city
-			FROM venues WHERE id = ?`, param)
+			SELECT v.id, v.name, v.address, v.description, v.slug, v.latlng, v.city, `+venueLatLngSQL+`, `+venueProfileColumns+`, v.updated_at
+			FROM venues v WHERE v.id = ?`, param)
 	} else {
 		// No es número → buscar por slug
 		row, err = h.DB.SelectRow(`
-			SELECT id, name, address, description, slug, latlng, city
-			FROM venues WHERE slug = ?`, param)
+			SELECT v.id, v.name, v.address, v.description, v.slug, v.latlng, v.city, `+venueLatLngSQL+`, `+venueProfileColumns+`, v.updated_at
+			FROM venues v WHERE v.slug = ?`, param)
 	}
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	err = row.Scan(&v.ID, &v.Name, &v.Address, &v.Description, &v.Slug, &v.LatLng, &v.City)
+	var lat, lng sql.NullFloat64
+	var p venueProfileScan
+	var updatedAt string
+	dest := []interface{}{&v.ID, &v.Name, &v.Address, &v.Description, &v.Slug, &v.LatLng, &v.City, &lat, &lng}
+	err = row.Scan(append(append(dest, p.dest()...), &updatedAt)...)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		if h.redirectOldSlug(w, r, "venue", param) {
+			return
+		}
+		writeError(w, r, http.StatusNotFound, "", err)
 		return
 	}
+	applyVenueCoordinates(&v, lat, lng)
+	p.apply(&v.VenueProfile)
+	h.countView("venues", v.ID)
+	setLastModified(w, updatedAt)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(v)
@@ -79,24 +216,30 @@ func (h *AuthHandler) GetVenueByIDOrSlug(w http.ResponseWriter, r *http.Request)
 func (h *AuthHandler) CreateVenue(w http.ResponseWriter, r *http.Request) {
 	var v Venue
 	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
-		http.Error(w, "Error al decodificar", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar")
 		return
 	}
 
 	// Obtener el ID del usuario autenticado
 	claims, ok := r.Context().Value("user").(*models.Claims)
 	if !ok {
-		http.Error(w, "Usuario no autenticado", http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, "Usuario no autenticado")
 		return
 	}
 	userID := claims.UserID
 
-	id, err := h.DB.Insert(false, `
-		INSERT INTO venues (name, address, description, slug, latlng, city)
-		VALUES (?, ?, ?, ?, ?, ?)`,
-		v.Name, v.Address, v.Description, v.Slug, v.LatLng, v.City)
+	if _, err := normalizeVenueLocation(&v); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Coordenadas inválidas", err)
+		return
+	}
+	if err := v.VenueProfile.normalize(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+
+	id, err := h.insertVenue(&v)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	v.ID = int(id)
@@ -118,18 +261,36 @@ func (h *AuthHandler) UpdateVenue(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	var v Venue
 	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
-		http.Error(w, "Error al decodificar", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar")
 		return
 	}
 
-	_, err := h.DB.Update(false, `
-		UPDATE venues SET name=?, address=?, description=?, slug=?, latlng=?, city=?
+	location, err := normalizeVenueLocation(&v)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "Coordenadas inválidas", err)
+		return
+	}
+
+	prevSlug := h.currentSlug("venue", id)
+	slug, err := h.updateSlug("venue", prevSlug, v.Slug, v.Name, v.City)
+	if err != nil {
+		writeSlugError(w, r, err)
+		return
+	}
+	v.Slug = slug
+	defer h.releaseSlug("venue", slug)
+	_, err = h.DB.Update(false, `
+		UPDATE venues SET name=?, address=?, description=?, slug=?, latlng=?, location=`+geoPointSQL+`, city=?
 		WHERE id = ?`,
-		v.Name, v.Address, v.Description, v.Slug, v.LatLng, v.City, id)
+		v.Name, v.Address, v.Description, v.Slug, v.LatLng, location, v.City, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
+	h.recordSlugChange("venue", prevSlug, v.Slug)
+	// Los eventos muestran el nombre y la ciudad del venue
+	h.reindexSearch("venue", prevSlug.ID)
+	h.reindexSearchQuery("event", "SELECT id FROM events WHERE id_venue = ?", prevSlug.ID)
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -138,9 +299,11 @@ func (h *AuthHandler) DeleteVenue(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	_, err := h.DB.Delete(false, "DELETE FROM venues WHERE id = ?", id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
+	venueID, _ := strconv.Atoi(id)
+	h.removeFromSearch("venue", venueID)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -166,37 +329,33 @@ func (h *AuthHandler) GetUserVenues(w http.ResponseWriter, r *http.Request) {
 	// Verificar que el usuario autenticado tenga permiso para ver estos venues
 	claims, ok := r.Context().Value("user").(*models.Claims)
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Usuario no autenticado"})
+		writeError(w, r, http.StatusUnauthorized, "Usuario no autenticado")
 		return
 	}
 
 	// Convertir userID de string a uint para comparar con claims.UserID
 	userIDUint, err := strconv.ParseUint(userID, 10, 32)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "ID de usuario inválido"})
+		writeError(w, r, http.StatusBadRequest, "ID de usuario inválido")
 		return
 	}
 
 	// Solo permitir acceso si es el mismo usuario o es admin
 	if claims.UserID != uint(userIDUint) && claims.Role != "admin" {
-		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(map[string]string{"error": "No tienes permiso para ver estos venues"})
+		writeError(w, r, http.StatusForbidden, "No tienes permiso para ver estos venues")
 		return
 	}
 
 	// Consultar los venues vinculados al usuario
 	rows, err := h.DB.Select(`
-		SELECT v.id, v.name, v.address, v.description, v.slug, v.latlng, v.city, vl.rol
+		SELECT v.id, v.name, v.address, v.description, v.slug, v.latlng, v.city, `+venueProfileColumns+`, vl.rol
 		FROM venues v
 		JOIN venue_links vl ON v.id = vl.venue_id
 		WHERE vl.user_id = ? AND vl.status = 'approved'
 		ORDER BY v.name ASC`, userID)
 
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Error al consultar los venues: " + err.Error()})
+		writeError(w, r, http.StatusInternalServerError, "Error al consultar los venues", err)
 		return
 	}
 	defer rows.Close()
@@ -206,11 +365,15 @@ func (h *AuthHandler) GetUserVenues(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var venue Venue
 		var rol string
+		var p venueProfileScan
 
-		if err := rows.Scan(&venue.ID, &venue.Name, &venue.Address, &venue.Description,
-			&venue.Slug, &venue.LatLng, &venue.City, &rol); err != nil {
+		dest := []interface{}{&venue.ID, &venue.Name, &venue.Address, &venue.Description,
+			&venue.Slug, &venue.LatLng, &venue.City}
+		dest = append(dest, p.dest()...)
+		if err := rows.Scan(append(dest, &rol)...); err != nil {
 			continue // Saltamos este registro si hay error
 		}
+		p.apply(&venue.VenueProfile)
 
 		venues = append(venues, venue)
 	}
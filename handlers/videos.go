@@ -16,59 +16,66 @@ type Video struct {
 	Bands     []*Band `json:"bands,omitempty"`
 }
 
+// videoListSpec declara los filtros y órdenes del listado de videos
+var videoListSpec = &listSpec{
+	from:   "videos v",
+	id:     column("v.id"),
+	search: columns("v.title", "v.slug"),
+	filters: map[string]filterField{
+		"id":    numberFilter("v.id"),
+		"slug":  textFilter("v.slug"),
+		"title": textFilter("v.title"),
+	},
+	sorts: map[string]string{"id": column("v.id"), "title": column("v.title")},
+	apply: func(r *http.Request, q *listQuery) error {
+		if band := r.URL.Query().Get("band"); band != "" {
+			q.where("EXISTS(SELECT 1 FROM videos_bands vb WHERE vb.id_video = v.id AND vb.id_band = ?)", band)
+		}
+		return nil
+	},
+}
+
 func (h *AuthHandler) GetVideos(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.DB.Select(`
-		SELECT v.id, v.title, v.slug, v.id_youtube, b.id, b.name, b.slug
-		FROM videos v
-		LEFT JOIN videos_bands vb ON v.id = vb.id_video
-		LEFT JOIN bands b ON vb.id_band = b.id
-	`)
+	p, err := parsePageRequest(r, maxPageLimit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
-	defer rows.Close()
-
-	videosMap := map[int]*Video{}
-	var videos []Video
 
-	for rows.Next() {
-		var id int
-		var title, slug, youtubeID string
-		var bID sql.NullInt64
-		var bName, bSlug sql.NullString
+	videos, page, err := h.queryVideos(r, p)
+	if err != nil {
+		writeListError(w, r, err)
+		return
+	}
 
-		err := rows.Scan(&id, &title, &slug, &youtubeID, &bID, &bName, &bSlug)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
+	// Bandas de los videos de la página, en una sola consulta
+	if err := h.newLoader().videoBandsFor(videos); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "", err)
+		return
+	}
 
-		video, exists := videosMap[id]
-		if !exists {
-			video = &Video{
-				ID:        id,
-				Title:     title,
-				Slug:      slug,
-				YoutubeID: youtubeID,
-				Bands:     []*Band{},
-			}
-			videosMap[id] = video
-		}
+	writePageHeaders(w, r, page)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(videos)
+}
 
-		if bID.Valid {
-			video.Bands = append(video.Bands, &Band{
-				ID:   int(bID.Int64),
-				Name: bName.String,
-				Slug: bSlug.String,
-			})
-		}
-	}
-	for _, v := range videosMap {
-		videos = append(videos, *v)
+// queryVideos lee una página de videos, sin las bandas
+func (h *AuthHandler) queryVideos(r *http.Request, p *pageRequest) ([]Video, *pageInfo, error) {
+	q, err := videoListSpec.query(r, "v.id, v.title, v.slug, v.id_youtube")
+	if err != nil {
+		return nil, nil, err
 	}
 
-	json.NewEncoder(w).Encode(videos)
+	var videos []Video
+	page, err := h.queryPage(q, p, func(scan func(dest ...interface{}) error) error {
+		video := Video{Bands: []*Band{}}
+		if err := scan(&video.ID, &video.Title, &video.Slug, &video.YoutubeID); err != nil {
+			return err
+		}
+		videos = append(videos, video)
+		return nil
+	})
+	return videos, page, err
 }
 
 func (h *AuthHandler) GetVideoByID(w http.ResponseWriter, r *http.Request) {
@@ -92,7 +99,7 @@ func (h *AuthHandler) GetVideoByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	defer rows.Close()
@@ -107,7 +114,7 @@ func (h *AuthHandler) GetVideoByID(w http.ResponseWriter, r *http.Request) {
 
 		err := rows.Scan(&id, &title, &slug, &youtubeID, &bID, &bName, &bSlug)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "", err)
 			return
 		}
 
@@ -131,7 +138,10 @@ func (h *AuthHandler) GetVideoByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if video == nil {
-		http.Error(w, "Video no encontrado", http.StatusNotFound)
+		if h.redirectOldSlug(w, r, "video", idOrSlug) {
+			return
+		}
+		writeError(w, r, http.StatusNotFound, "Video no encontrado")
 		return
 	}
 
@@ -148,7 +158,7 @@ func (h *AuthHandler) GetVideosByBandID(w http.ResponseWriter, r *http.Request)
 	`
 	rows, err := h.DB.Select(query, bandID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	defer rows.Close()
@@ -157,7 +167,7 @@ func (h *AuthHandler) GetVideosByBandID(w http.ResponseWriter, r *http.Request)
 	for rows.Next() {
 		var v Video
 		if err := rows.Scan(&v.ID, &v.Title, &v.Slug, &v.YoutubeID); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, "", err)
 			return
 		}
 		videos = append(videos, v)
@@ -169,14 +179,21 @@ func (h *AuthHandler) GetVideosByBandID(w http.ResponseWriter, r *http.Request)
 func (h *AuthHandler) CreateVideo(w http.ResponseWriter, r *http.Request) {
 	var v Video
 	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "", err)
 		return
 	}
+	slug, err := h.assignSlug("video", v.Slug, v.Title, "")
+	if err != nil {
+		writeSlugError(w, r, err)
+		return
+	}
+	v.Slug = slug
+	defer h.releaseSlug("video", slug)
 
 	lastID, err := h.DB.Insert(true, "INSERT INTO videos (title, slug, id_youtube) VALUES (?, ?, ?)",
 		v.Title, v.Slug, v.YoutubeID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
 	v.ID = int(lastID)
@@ -186,11 +203,12 @@ func (h *AuthHandler) CreateVideo(w http.ResponseWriter, r *http.Request) {
 		if band.ID > 0 {
 			_, err = h.DB.Insert(true, "INSERT INTO videos_bands (id_video, id_band) VALUES (?, ?)", v.ID, band.ID)
 			if err != nil {
-				http.Error(w, "Video creado pero falló el vínculo con alguna banda", http.StatusInternalServerError)
+				writeError(w, r, http.StatusInternalServerError, "Video creado pero falló el vínculo con alguna banda")
 				return
 			}
 		}
 	}
+	h.reindexSearch("video", v.ID)
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(v)
@@ -200,9 +218,17 @@ func (h *AuthHandler) UpdateVideo(w http.ResponseWriter, r *http.Request) {
 	idOrSlug := chi.URLParam(r, "id")
 	var v Video
 	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+	prevSlug := h.currentSlug("video", idOrSlug)
+	slug, err := h.updateSlug("video", prevSlug, v.Slug, v.Title, "")
+	if err != nil {
+		writeSlugError(w, r, err)
 		return
 	}
+	v.Slug = slug
+	defer h.releaseSlug("video", slug)
 
 	query := "UPDATE videos SET title = ?, slug = ?, id_youtube = ? WHERE "
 	args := []interface{}{v.Title, v.Slug, v.YoutubeID}
@@ -214,11 +240,13 @@ func (h *AuthHandler) UpdateVideo(w http.ResponseWriter, r *http.Request) {
 	}
 	args = append(args, idOrSlug)
 
-	_, err := h.DB.Update(true, query, args...)
+	_, err = h.DB.Update(true, query, args...)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
+	h.recordSlugChange("video", prevSlug, v.Slug)
+	h.reindexSearch("video", prevSlug.ID)
 
 	// También podés actualizar videos_bands si necesitás
 	w.WriteHeader(http.StatusOK)
@@ -233,11 +261,13 @@ func (h *AuthHandler) DeleteVideo(w http.ResponseWriter, r *http.Request) {
 	} else {
 		query += "slug = ?"
 	}
+	video := h.currentSlug("video", idOrSlug)
 	_, err := h.DB.Delete(true, query, arg)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, "", err)
 		return
 	}
+	h.removeFromSearch("video", video.ID)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,787 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"brotecolectivo/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Tipos de evento a los que se puede suscribir un webhook
+var webhookEventTypes = []string{"event.created", "event.cancelled", "band.created", "news.published", "submission.approved",
+	"event.reminder", "events.weekly_digest"}
+
+const (
+	webhookPollInterval  = 15 * time.Second
+	webhookTimeout       = 10 * time.Second
+	webhookRetryBase     = time.Minute // 1, 2, 4, 8... minutos entre intentos
+	maxWebhookAttempts   = 8           // unas dos horas entre el primer intento y el último
+	webhookWorkers       = 4
+	maxWebhookRespLength = 1024
+)
+
+// Webhook es una suscripción de una URL externa a tipos de evento.
+//
+// @Schema
+type Webhook struct {
+	ID          int      `json:"id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	Active      bool     `json:"active"`
+	Secret      string   `json:"secret,omitempty"` // solo se devuelve al crear el webhook
+	CreatedAt   string   `json:"created_at"`
+}
+
+// WebhookDelivery es un envío de un evento a un webhook, con el resultado del último intento.
+//
+// @Schema
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	WebhookID      int             `json:"webhook_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"` // pending, delivered o failed
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	NextAttemptAt  string          `json:"next_attempt_at,omitempty"`
+	RedeliveryOf   int             `json:"redelivery_of,omitempty"` // entrega original si es un reenvío
+	CreatedAt      string          `json:"created_at"`
+	DeliveredAt    string          `json:"delivered_at,omitempty"`
+}
+
+// webhookPayload es el cuerpo que recibe el receptor
+type webhookPayload struct {
+	ID        string      `json:"id"` // el mismo para todas las entregas de un evento
+	Type      string      `json:"type"`
+	CreatedAt string      `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// webhookDispatcher despierta al proceso de entregas cuando hay eventos nuevos, sin esperar al próximo ciclo
+type webhookDispatcher struct {
+	wake   chan struct{}
+	client *http.Client
+}
+
+func newWebhookDispatcher() *webhookDispatcher {
+	return &webhookDispatcher{
+		wake: make(chan struct{}, 1),
+		client: &http.Client{
+			Timeout: webhookTimeout,
+			// Una redirección cuenta como falla: el receptor tiene que registrar la URL final
+			CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+func (d *webhookDispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// normalize valida la suscripción y deja los eventos sin repetir
+func (wh *Webhook) normalize() error {
+	wh.URL = strings.TrimSpace(wh.URL)
+	if wh.URL == "" {
+		return fieldError("url", "required", "url es obligatoria")
+	}
+	u, err := url.Parse(wh.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fieldError("url", "invalid", "url inválida")
+	}
+	if len(wh.URL) > 500 {
+		return fieldError("url", "range", "url no puede superar los 500 caracteres")
+	}
+	if len(wh.Events) == 0 {
+		return fieldError("events", "required", "se necesita al menos un tipo de evento")
+	}
+	var events []string
+	for _, e := range wh.Events {
+		e = strings.TrimSpace(e)
+		if !slices.Contains(webhookEventTypes, e) {
+			return fieldError("events", "invalid", "tipo de evento desconocido: "+e+" (válidos: "+strings.Join(webhookEventTypes, ", ")+")")
+		}
+		if !slices.Contains(events, e) {
+			events = append(events, e)
+		}
+	}
+	wh.Events = events
+	wh.Description = strings.TrimSpace(wh.Description)
+	if len(wh.Description) > 255 {
+		return fieldError("description", "range", "description no puede superar los 255 caracteres")
+	}
+	return nil
+}
+
+// SignWebhook firma un cuerpo como lo reciben los webhooks: HMAC-SHA256 de "timestamp.cuerpo"
+// con el secreto de la suscripción, en hexadecimal.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature comprueba el encabezado X-Brote-Signature ("sha256=...") de una entrega
+func VerifyWebhookSignature(secret, timestamp string, body []byte, signature string) bool {
+	expected := "sha256=" + SignWebhook(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// emitWebhook registra una entrega del evento para cada webhook activo suscripto a ese tipo.
+// El envío lo hace el proceso de entregas (StartWebhookDeliveries), así la respuesta no espera al receptor.
+func (h *AuthHandler) emitWebhook(eventType string, data interface{}) {
+	rows, err := h.DB.Select(`SELECT id FROM webhooks WHERE active = 1 AND FIND_IN_SET(?, events)`, eventType)
+	if err != nil {
+		fmt.Printf("[Warning] No se pudieron consultar los webhooks de %s: %v\n", eventType, err)
+		return
+	}
+	var webhookIDs []int
+	for rows.Next() {
+		var id int
+		if rows.Scan(&id) == nil {
+			webhookIDs = append(webhookIDs, id)
+		}
+	}
+	rows.Close()
+	if len(webhookIDs) == 0 {
+		return
+	}
+
+	payload, err := newWebhookPayload(eventType, data)
+	if err != nil {
+		fmt.Printf("[Warning] No se pudo armar el evento %s: %v\n", eventType, err)
+		return
+	}
+	for _, id := range webhookIDs {
+		if _, err := h.queueWebhookDelivery(id, eventType, payload, 0); err != nil {
+			fmt.Printf("[Warning] No se pudo registrar la entrega de %s al webhook #%d: %v\n", eventType, id, err)
+		}
+	}
+	h.webhooks.notify()
+}
+
+func newWebhookPayload(eventType string, data interface{}) ([]byte, error) {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return json.Marshal(webhookPayload{
+		ID:        hex.EncodeToString(id),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Data:      data,
+	})
+}
+
+func (h *AuthHandler) queueWebhookDelivery(webhookID int, eventType string, payload []byte, redeliveryOf int) (int, error) {
+	return h.DB.Insert(false, `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload, redelivery_of, next_attempt_at)
+		VALUES (?, ?, ?, NULLIF(?, 0), NOW())`, webhookID, eventType, string(payload), redeliveryOf)
+}
+
+// webhookEvent es el resumen de un evento que reciben los webhooks (event.created, event.cancelled,
+// event.reminder y events.weekly_digest)
+type webhookEvent struct {
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	Slug      string `json:"slug"`
+	DateStart string `json:"date_start"`
+	DateEnd   string `json:"date_end"`
+	Venue     struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	} `json:"venue"`
+}
+
+// Consulta de webhookEvent; se completa con el WHERE. date_end puede ser NULL.
+const webhookEventQuery = `
+	SELECT e.id, e.title, e.slug, e.date_start, IFNULL(e.date_end, ''), IFNULL(e.id_venue, 0), IFNULL(v.name, ''), IFNULL(v.slug, '')
+	FROM events e LEFT JOIN venues v ON v.id = e.id_venue`
+
+func scanWebhookEvent(row interface{ Scan(...interface{}) error }) (*webhookEvent, error) {
+	var e webhookEvent
+	if err := row.Scan(&e.ID, &e.Title, &e.Slug, &e.DateStart, &e.DateEnd, &e.Venue.ID, &e.Venue.Name, &e.Venue.Slug); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// webhookEventData resume un evento para los webhooks (también se usa antes de borrarlo)
+func (h *AuthHandler) webhookEventData(eventID int) (*webhookEvent, error) {
+	row, err := h.DB.SelectRow(webhookEventQuery+" WHERE e.id = ?", eventID)
+	if err != nil {
+		return nil, err
+	}
+	return scanWebhookEvent(row)
+}
+
+// emitEventCreated avisa a los webhooks de un evento nuevo
+func (h *AuthHandler) emitEventCreated(eventID int) {
+	data, err := h.webhookEventData(eventID)
+	if err != nil {
+		fmt.Printf("[Warning] No se pudo leer el evento #%d para los webhooks: %v\n", eventID, err)
+		return
+	}
+	h.emitWebhook("event.created", data)
+}
+
+// emitSubmissionApproved avisa a los webhooks si la submission quedó aprobada
+func (h *AuthHandler) emitSubmissionApproved(submissionID string) {
+	row, err := h.DB.SelectRow("SELECT id, type, user_id, status FROM submissions WHERE id = ?", submissionID)
+	if err != nil {
+		return
+	}
+	var id, userID int
+	var subType, status string
+	if row.Scan(&id, &subType, &userID, &status) != nil || status != "approved" {
+		return
+	}
+	h.emitWebhook("submission.approved", map[string]interface{}{"id": id, "type": subType, "user_id": userID})
+}
+
+// StartWebhookDeliveries inicia el proceso que envía las entregas pendientes y reintenta las fallidas
+func (h *AuthHandler) StartWebhookDeliveries() {
+	go func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+		for {
+			if err := h.deliverPendingWebhooks(); err != nil {
+				fmt.Printf("[Warning] No se pudieron enviar los webhooks pendientes: %v\n", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-h.webhooks.wake:
+			}
+		}
+	}()
+}
+
+type pendingDelivery struct {
+	id, webhookID, attempts int
+	eventType, url, secret  string
+	payload                 []byte
+}
+
+// deliverPendingWebhooks envía las entregas que llegaron a su próximo intento
+func (h *AuthHandler) deliverPendingWebhooks() error {
+	rows, err := h.DB.Select(`
+		SELECT d.id, d.webhook_id, d.attempts, d.event_type, w.url, w.secret, d.payload
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND w.active = 1
+		ORDER BY d.id LIMIT 50`)
+	if err != nil {
+		return err
+	}
+	var pending []pendingDelivery
+	for rows.Next() {
+		var d pendingDelivery
+		if err := rows.Scan(&d.id, &d.webhookID, &d.attempts, &d.eventType, &d.url, &d.secret, &d.payload); err == nil {
+			pending = append(pending, d)
+		}
+	}
+	rows.Close()
+
+	sem := make(chan struct{}, webhookWorkers)
+	var wg sync.WaitGroup
+	for _, d := range pending {
+		// Reservar la entrega: con varias instancias de la API solo una la envía
+		claimed, err := h.DB.Update(false, `
+			UPDATE webhook_deliveries SET next_attempt_at = DATE_ADD(NOW(), INTERVAL 5 MINUTE)
+			WHERE id = ? AND status = 'pending' AND next_attempt_at <= NOW()`, d.id)
+		if err != nil || claimed == 0 {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d pendingDelivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			h.deliverWebhook(d)
+		}(d)
+	}
+	wg.Wait()
+	return nil
+}
+
+// deliverWebhook hace un intento de entrega y agenda el siguiente si falló
+func (h *AuthHandler) deliverWebhook(d pendingDelivery) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	var status int
+	var respBody, errMsg string
+
+	req, err := http.NewRequest(http.MethodPost, d.url, bytes.NewReader(d.payload))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "BroteColectivo-Webhooks/1.0")
+		req.Header.Set("X-Brote-Event", d.eventType)
+		req.Header.Set("X-Brote-Delivery", strconv.Itoa(d.id))
+		req.Header.Set("X-Brote-Webhook", strconv.Itoa(d.webhookID))
+		req.Header.Set("X-Brote-Timestamp", timestamp)
+		req.Header.Set("X-Brote-Signature", "sha256="+SignWebhook(d.secret, timestamp, d.payload))
+
+		var resp *http.Response
+		resp, err = h.webhooks.client.Do(req)
+		if err == nil {
+			status = resp.StatusCode
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookRespLength))
+			resp.Body.Close()
+			respBody = string(body)
+		}
+	}
+	if err != nil {
+		errMsg = err.Error()
+	} else if status < 200 || status > 299 {
+		errMsg = fmt.Sprintf("El receptor respondió %d", status)
+	}
+	if len(errMsg) > 500 {
+		errMsg = errMsg[:500]
+	}
+
+	attempts := d.attempts + 1
+	switch {
+	case errMsg == "":
+		_, err = h.DB.Update(false, `
+			UPDATE webhook_deliveries
+			SET status = 'delivered', attempts = ?, response_status = ?, response_body = ?, error = NULL,
+			    next_attempt_at = NULL, delivered_at = NOW()
+			WHERE id = ?`, attempts, status, respBody, d.id)
+	case attempts >= maxWebhookAttempts:
+		_, err = h.DB.Update(false, `
+			UPDATE webhook_deliveries
+			SET status = 'failed', attempts = ?, response_status = NULLIF(?, 0), response_body = ?, error = ?, next_attempt_at = NULL
+			WHERE id = ?`, attempts, status, respBody, errMsg, d.id)
+	default:
+		delay := webhookRetryBase << (attempts - 1)
+		_, err = h.DB.Update(false, `
+			UPDATE webhook_deliveries
+			SET attempts = ?, response_status = NULLIF(?, 0), response_body = ?, error = ?,
+			    next_attempt_at = DATE_ADD(NOW(), INTERVAL ? SECOND)
+			WHERE id = ?`, attempts, status, respBody, errMsg, int(delay.Seconds()), d.id)
+	}
+	if err != nil {
+		fmt.Printf("[Warning] No se pudo registrar el resultado de la entrega #%d: %v\n", d.id, err)
+	}
+}
+
+func scanWebhook(rows *sql.Rows) (Webhook, error) {
+	var wh Webhook
+	var events string
+	err := rows.Scan(&wh.ID, &wh.URL, &events, &wh.Description, &wh.Active, &wh.CreatedAt)
+	wh.Events = splitList(events)
+	return wh, err
+}
+
+// GetWebhooks lista las suscripciones de webhooks (solo administradores).
+//
+// @Summary Listar webhooks
+// @Description Devuelve las URLs registradas y sus tipos de evento. El secreto no se incluye
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} Webhook "Webhooks registrados"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (h *AuthHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	rows, err := h.DB.Select(`SELECT id, url, events, description, active, created_at FROM webhooks ORDER BY id`)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener webhooks", err)
+		return
+	}
+	defer rows.Close()
+
+	webhooks := []Webhook{}
+	for rows.Next() {
+		wh, err := scanWebhook(rows)
+		if err != nil {
+			continue
+		}
+		webhooks = append(webhooks, wh)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// CreateWebhook registra una URL para recibir tipos de evento (solo administradores).
+//
+// @Summary Registrar webhook
+// @Description Registra una URL para event.created, event.cancelled, band.created, news.published y/o submission.approved. La respuesta incluye el secreto para verificar las firmas: es la única vez que se muestra
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body Webhook true "url, events y description"
+// @Success 201 {object} Webhook "Webhook creado, con su secreto"
+// @Failure 400 {string} string "Datos inválidos"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (h *AuthHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	var wh Webhook
+	if err := json.NewDecoder(r.Body).Decode(&wh); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar")
+		return
+	}
+	if err := wh.normalize(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	wh.Secret = hex.EncodeToString(secret)
+	wh.Active = true
+	id, err := h.DB.Insert(false, `
+		INSERT INTO webhooks (url, events, secret, description, created_by) VALUES (?, ?, ?, ?, ?)`,
+		wh.URL, strings.Join(wh.Events, ","), wh.Secret, wh.Description, claims.UserID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al crear el webhook", err)
+		return
+	}
+	wh.ID = id
+	wh.CreatedAt = time.Now().Format("2006-01-02 15:04:05")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(wh)
+}
+
+// UpdateWebhook cambia la URL, los eventos, la descripción o el estado de un webhook.
+//
+// @Summary Actualizar webhook
+// @Description Al desactivarlo, sus entregas pendientes se marcan como fallidas
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del webhook"
+// @Param webhook body Webhook true "url, events, description y active"
+// @Success 200 {object} Webhook "Webhook actualizado"
+// @Failure 400 {string} string "Datos inválidos"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Webhook no encontrado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /webhooks/{id} [put]
+func (h *AuthHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	var wh Webhook
+	if err := json.NewDecoder(r.Body).Decode(&wh); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Error al decodificar")
+		return
+	}
+	if err := wh.normalize(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "", err)
+		return
+	}
+
+	var createdAt string
+	row, err := h.DB.SelectRow("SELECT created_at FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "", err)
+		return
+	}
+	if err := row.Scan(&createdAt); err != nil {
+		writeError(w, r, http.StatusNotFound, "Webhook no encontrado")
+		return
+	}
+
+	_, err = h.DB.Update(false, `UPDATE webhooks SET url = ?, events = ?, description = ?, active = ? WHERE id = ?`,
+		wh.URL, strings.Join(wh.Events, ","), wh.Description, wh.Active, id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al actualizar el webhook", err)
+		return
+	}
+	if !wh.Active {
+		_, _ = h.DB.Update(false, `
+			UPDATE webhook_deliveries SET status = 'failed', error = 'Webhook desactivado', next_attempt_at = NULL
+			WHERE webhook_id = ? AND status = 'pending'`, id)
+	}
+	wh.ID = id
+	wh.Secret = ""
+	wh.CreatedAt = createdAt
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(wh)
+}
+
+// DeleteWebhook elimina un webhook y su registro de entregas.
+//
+// @Summary Eliminar webhook
+// @Tags webhooks
+// @Param id path int true "ID del webhook"
+// @Success 204 "Webhook eliminado"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Webhook no encontrado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /webhooks/{id} [delete]
+func (h *AuthHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	id := chi.URLParam(r, "id")
+	deleted, err := h.DB.Delete(false, "DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al eliminar el webhook", err)
+		return
+	}
+	if deleted == 0 {
+		writeError(w, r, http.StatusNotFound, "Webhook no encontrado")
+		return
+	}
+	_, _ = h.DB.Delete(false, "DELETE FROM webhook_deliveries WHERE webhook_id = ?", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries devuelve el registro de entregas de un webhook, de la más reciente a la más antigua.
+//
+// @Summary Registro de entregas
+// @Description Cada entrega muestra el payload, los intentos, la última respuesta del receptor y el próximo reintento
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID del webhook"
+// @Param status query string false "Filtrar por estado" Enums(pending, delivered, failed)
+// @Success 200 {array} WebhookDelivery "Entregas (hasta 100)"
+// @Failure 400 {string} string "Estado inválido"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (h *AuthHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	query := `
+		SELECT id, webhook_id, event_type, payload, status, attempts, IFNULL(response_status, 0), IFNULL(response_body, ''),
+		       IFNULL(error, ''), IFNULL(next_attempt_at, ''), IFNULL(redelivery_of, 0), created_at, IFNULL(delivered_at, '')
+		FROM webhook_deliveries WHERE webhook_id = ?`
+	args := []interface{}{chi.URLParam(r, "id")}
+	if status := r.URL.Query().Get("status"); status != "" {
+		if status != "pending" && status != "delivered" && status != "failed" {
+			writeError(w, r, http.StatusBadRequest, "", fieldError("status", "invalid", "status debe ser pending, delivered o failed"))
+			return
+		}
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY id DESC LIMIT 100"
+
+	rows, err := h.DB.Select(query, args...)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al obtener las entregas", err)
+		return
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var d WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &payload, &d.Status, &d.Attempts, &d.ResponseStatus,
+			&d.ResponseBody, &d.Error, &d.NextAttemptAt, &d.RedeliveryOf, &d.CreatedAt, &d.DeliveredAt); err != nil {
+			continue
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// RedeliverWebhook vuelve a enviar una entrega con el mismo payload. Se registra como una entrega nueva.
+//
+// @Summary Reenviar entrega
+// @Description Crea una entrega nueva con el mismo evento (mismo id de payload) y la envía en el momento
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID de la entrega"
+// @Success 202 {object} WebhookDelivery "Entrega agendada"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Entrega no encontrada"
+// @Failure 409 {string} string "El webhook está desactivado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /webhooks/deliveries/{id}/redeliver [post]
+func (h *AuthHandler) RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	originalID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	row, err := h.DB.SelectRow(`
+		SELECT d.webhook_id, d.event_type, d.payload, w.active
+		FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.id = ?`, originalID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "", err)
+		return
+	}
+	var d WebhookDelivery
+	var payload []byte
+	var active bool
+	if err := row.Scan(&d.WebhookID, &d.EventType, &payload, &active); err != nil {
+		writeError(w, r, http.StatusNotFound, "Entrega no encontrada")
+		return
+	}
+	if !active {
+		writeError(w, r, http.StatusConflict, "El webhook está desactivado")
+		return
+	}
+
+	d.ID, err = h.queueWebhookDelivery(d.WebhookID, d.EventType, payload, originalID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al agendar la entrega", err)
+		return
+	}
+	h.webhooks.notify()
+	d.Payload = payload
+	d.Status = "pending"
+	d.RedeliveryOf = originalID
+	d.CreatedAt = time.Now().Format("2006-01-02 15:04:05")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(d)
+}
+
+// PingWebhook envía un evento de prueba ("ping") al webhook, esté suscripto a lo que esté.
+//
+// @Summary Probar webhook
+// @Description Agenda una entrega de tipo ping para comprobar la URL y la verificación de firmas. El resultado se ve en el registro de entregas
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID del webhook"
+// @Success 202 {object} WebhookDelivery "Entrega agendada"
+// @Failure 401 {string} string "No autorizado"
+// @Failure 404 {string} string "Webhook no encontrado"
+// @Failure 409 {string} string "El webhook está desactivado"
+// @Failure 500 {string} string "Error interno del servidor"
+// @Security BearerAuth
+// @Router /webhooks/{id}/ping [post]
+func (h *AuthHandler) PingWebhook(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value("user").(*models.Claims)
+	if !ok || claims.Role != "admin" {
+		writeError(w, r, http.StatusUnauthorized, "No autorizado. Se requiere rol de administrador")
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	var active bool
+	row, err := h.DB.SelectRow("SELECT active FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "", err)
+		return
+	}
+	if err := row.Scan(&active); err != nil {
+		writeError(w, r, http.StatusNotFound, "Webhook no encontrado")
+		return
+	}
+	if !active {
+		writeError(w, r, http.StatusConflict, "El webhook está desactivado")
+		return
+	}
+
+	payload, err := newWebhookPayload("ping", map[string]interface{}{"webhook_id": id})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "", err)
+		return
+	}
+	deliveryID, err := h.queueWebhookDelivery(id, "ping", payload, 0)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Error al agendar la entrega", err)
+		return
+	}
+	h.webhooks.notify()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(WebhookDelivery{
+		ID:        deliveryID,
+		WebhookID: id,
+		EventType: "ping",
+		Payload:   payload,
+		Status:    "pending",
+		CreatedAt: time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+// WebhookTestReceiver es un receptor local para probar los webhooks sin servicios externos:
+// imprime cada entrega, verifica su firma con secret y responde el código que indique ?status=
+// en la URL registrada (200 por defecto), así se pueden probar los reintentos.
+func WebhookTestReceiver(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		signature := "sin verificar (falta -webhook-secret)"
+		if secret != "" {
+			signature = "INVÁLIDA"
+			if VerifyWebhookSignature(secret, r.Header.Get("X-Brote-Timestamp"), body, r.Header.Get("X-Brote-Signature")) {
+				signature = "válida"
+			}
+		}
+
+		status := http.StatusOK
+		if s, err := strconv.Atoi(r.URL.Query().Get("status")); err == nil && s >= 200 && s <= 599 {
+			status = s
+		}
+		fmt.Printf("[webhook] %s entrega #%s del webhook #%s, firma %s, respuesta %d\n%s\n\n",
+			r.Header.Get("X-Brote-Event"), r.Header.Get("X-Brote-Delivery"), r.Header.Get("X-Brote-Webhook"), signature, status, body)
+		w.WriteHeader(status)
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"gopkg.in/ini.v1"
@@ -21,12 +22,34 @@ var dataBase *database.DatabaseStruct
 var jwtKey []byte
 
 func main() {
-	initConfig()
-	defer dataBase.Close()
 	var port string
+	var checkSpec bool
+	var receiverAddr, receiverSecret string
 	flag.StringVar(&port, "port", "3001", "Define el puerto en el que el servidor debería escuchar")
+	flag.BoolVar(&checkSpec, "check-openapi", false, "Compara las rutas con sus anotaciones y termina con error si no coinciden")
+	flag.StringVar(&receiverAddr, "webhook-receiver", "", "Levanta solo un receptor local de webhooks en esta dirección (por ejemplo :4001) para probarlos")
+	flag.StringVar(&receiverSecret, "webhook-secret", "", "Secreto con el que el receptor local verifica las firmas")
 	flag.Parse()
+
+	// La verificación de la especificación no necesita configuración ni base de datos
+	if checkSpec {
+		os.Exit(checkOpenAPI())
+	}
+
+	// El receptor de prueba tampoco: imprime lo que llega y responde según ?status=
+	if receiverAddr != "" {
+		log.Printf("Receptor de webhooks escuchando en %s\n", receiverAddr)
+		log.Fatal(http.ListenAndServe(receiverAddr, handlers.WebhookTestReceiver(receiverSecret)))
+	}
+
+	initConfig()
+	defer dataBase.Close()
 	authHandler := handlers.NewAuthHandler(dataBase)
+	authHandler.StartAutocompleteRefresh()
+	authHandler.StartWebhookDeliveries()
+	authHandler.StartJobWorkers()
+	authHandler.StartScheduler()
+	authHandler.StartCacheSync()
 
 	r := InitRoutes(authHandler)
 
@@ -65,4 +88,7 @@ func initConfig() {
 	if err != nil {
 		log.Fatal("Error al conectar con la base de datos: ", err)
 	}
+
+	// Los envíos de email desde utils leen la configuración de Mailgun de la tabla settings
+	utils.GetMailgunConfig = getMailgunConfig
 }
@@ -1,12 +1,18 @@
 package main
 
 import (
+	"brotecolectivo/handlers"
 	"brotecolectivo/models"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/golang-jwt/jwt"
 	"golang.org/x/time/rate"
 )
@@ -18,7 +24,7 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		// Obtenemos el token de autorización del encabezado
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "No autorizado. Token no proporcionado.", http.StatusUnauthorized)
+			handlers.WriteError(w, r, http.StatusUnauthorized, "No autorizado. Token no proporcionado.")
 			return
 		}
 
@@ -38,7 +44,7 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		// El token debe estar en el formato "Bearer {token}"
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			http.Error(w, "No autorizado. Formato de token inválido.", http.StatusUnauthorized)
+			handlers.WriteError(w, r, http.StatusUnauthorized, "No autorizado. Formato de token inválido.")
 			return
 		}
 
@@ -56,15 +62,15 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		if err != nil {
 			fmt.Printf("[DEBUG] Error al validar token: %v\n", err)
 			if err == jwt.ErrSignatureInvalid {
-				http.Error(w, "No autorizado. Token de autenticación inválido.", http.StatusUnauthorized)
+				handlers.WriteError(w, r, http.StatusUnauthorized, "No autorizado. Token de autenticación inválido.")
 				return
 			}
-			http.Error(w, "No autorizado. Token de autenticación inválido.", http.StatusUnauthorized)
+			handlers.WriteError(w, r, http.StatusUnauthorized, "No autorizado. Token de autenticación inválido.")
 			return
 		}
 		if !token.Valid {
 			fmt.Printf("[DEBUG] Token inválido\n")
-			http.Error(w, "No autorizado. Token de autenticación inválido.", http.StatusUnauthorized)
+			handlers.WriteError(w, r, http.StatusUnauthorized, "No autorizado. Token de autenticación inválido.")
 			return
 		}
 
@@ -77,6 +83,66 @@ func AuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// OptionalAuth guarda en el contexto los claims de un token válido, si el pedido trae uno. Sin
+// token (o con uno inválido) el pedido sigue como anónimo: es para rutas públicas que tienen
+// opciones reservadas a administradores.
+func OptionalAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tokenString, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			claims := &models.Claims{}
+			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+				return jwtKey, nil
+			})
+			if err == nil && token.Valid {
+				r = r.WithContext(context.WithValue(r.Context(), "user", claims))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// StreamTicket cambia un ticket de un solo uso (?ticket=, ver POST /stream-tickets) por un token
+// interno de un minuto en Authorization, así AuthMiddleware valida el pedido como siempre. Es para
+// clientes como EventSource que no pueden enviar el encabezado; el JWT real nunca va en la URL.
+func StreamTicket(h *handlers.AuthHandler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ticket := r.URL.Query().Get("ticket")
+			if ticket == "" || r.Header.Get("Authorization") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			claims, err := h.ConsumeStreamTicket(ticket)
+			if err != nil {
+				handlers.WriteError(w, r, http.StatusUnauthorized, "No autorizado. Ticket inválido o vencido.")
+				return
+			}
+			claims.ExpiresAt = time.Now().Add(time.Minute).Unix()
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtKey)
+			if err != nil {
+				fmt.Printf("[Error] No se pudo firmar el token del ticket: %v\n", err)
+				handlers.WriteError(w, r, http.StatusInternalServerError, "Error al validar el ticket")
+				return
+			}
+			r.Header.Set("Authorization", "Bearer "+token)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RedactAccessToken quita ?access_token= de la URL antes de que llegue al log de acceso. Un token
+// en la URL queda escrito en los logs (los nuestros y los de cualquier proxy); ya no se acepta.
+func RedactAccessToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Has("access_token") {
+			q.Del("access_token")
+			r.URL.RawQuery = q.Encode()
+			r.RequestURI = r.URL.RequestURI()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func SecurityHeaders(next http.Handler) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -90,6 +156,26 @@ func SecurityHeaders(next http.Handler) http.Handler {
 	})
 }
 
+// Un X-Request-ID recibido se reutiliza solo si es corto y seguro para loguear
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{8,64}$`)
+
+// RequestID asigna un ID a cada pedido (o reutiliza el X-Request-ID del proxy), lo devuelve en
+// el encabezado X-Request-ID y lo deja en el contexto para el log y las respuestas de error.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !requestIDPattern.MatchString(id) {
+			buf := make([]byte, 8)
+			rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		w.Header().Set("X-Request-ID", id)
+
+		ctx := context.WithValue(r.Context(), middleware.RequestIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		origin := r.Header.Get("Origin")
@@ -97,7 +183,8 @@ func CORSMiddleware(next http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 		}
 		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Accept-Language, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, X-Request-ID, Authorization")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, Link, X-Total-Count, X-Next-Cursor")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
@@ -113,7 +200,7 @@ func CORSMiddleware(next http.Handler) http.Handler {
 func RateLimit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !limiter.Allow() {
-			http.Error(w, "Demasiadas solicitudes, intenta de nuevo más tarde.", http.StatusTooManyRequests)
+			handlers.WriteError(w, r, http.StatusTooManyRequests, "Demasiadas solicitudes, intenta de nuevo más tarde.")
 			return
 		}
 
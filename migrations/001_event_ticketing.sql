@@ -0,0 +1,11 @@
+-- Entradas y acceso a eventos: precios por tanda, link de compra,
+-- entrada libre (o libre hasta completar capacidad), edad mínima y apertura de puertas.
+ALTER TABLE events
+  ADD COLUMN price_tiers JSON NULL AFTER date_end,
+  ADD COLUMN is_free TINYINT(1) NOT NULL DEFAULT 0 AFTER price_tiers,
+  ADD COLUMN free_until_capacity TINYINT(1) NOT NULL DEFAULT 0 AFTER is_free,
+  ADD COLUMN ticket_url VARCHAR(512) NULL AFTER free_until_capacity,
+  ADD COLUMN min_age TINYINT UNSIGNED NOT NULL DEFAULT 0 AFTER ticket_url,
+  ADD COLUMN door_time TIME NULL AFTER min_age;
+
+CREATE INDEX idx_events_free ON events (is_free, free_until_capacity);
@@ -0,0 +1,2 @@
+-- Índice para detectar superposición de horarios de eventos en un mismo venue
+CREATE INDEX idx_events_venue_schedule ON events (id_venue, date_start, date_end);
@@ -0,0 +1,19 @@
+-- Festivales y ciclos: agrupan eventos de distintas fechas y venues
+CREATE TABLE IF NOT EXISTS event_series (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  type ENUM('festival', 'cycle') NOT NULL DEFAULT 'festival',
+  title VARCHAR(255) NOT NULL,
+  slug VARCHAR(255) NOT NULL,
+  description TEXT NULL,
+  image VARCHAR(512) NULL,
+  date_start DATE NULL,
+  date_end DATE NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  UNIQUE KEY uq_event_series_slug (slug)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE events
+  ADD COLUMN id_series INT NULL AFTER id_venue,
+  ADD KEY idx_events_series (id_series),
+  ADD CONSTRAINT fk_events_series FOREIGN KEY (id_series) REFERENCES event_series (id) ON DELETE SET NULL;
@@ -0,0 +1,17 @@
+-- Coordenadas de venues como tipo espacial (WGS 84).
+-- latlng se mantiene como texto normalizado "lat,lng" por compatibilidad.
+ALTER TABLE venues
+  ADD COLUMN location POINT SRID 4326 NULL AFTER latlng;
+
+-- Migrar los valores existentes que tengan formato "lat,lng" válido
+UPDATE venues
+SET latlng = REPLACE(latlng, ' ', '')
+WHERE latlng IS NOT NULL;
+
+UPDATE venues
+SET location = ST_GeomFromText(
+      CONCAT('POINT(', SUBSTRING_INDEX(latlng, ',', 1), ' ', SUBSTRING_INDEX(latlng, ',', -1), ')'),
+      4326, 'axis-order=lat-long')
+WHERE latlng REGEXP '^-?[0-9]+(\\.[0-9]+)?,-?[0-9]+(\\.[0-9]+)?$'
+  AND ABS(SUBSTRING_INDEX(latlng, ',', 1)) <= 90
+  AND ABS(SUBSTRING_INDEX(latlng, ',', -1)) <= 180;
@@ -0,0 +1,15 @@
+-- Perfil extendido de venues: datos técnicos, accesibilidad, horarios y contacto
+ALTER TABLE venues
+  ADD COLUMN capacity INT UNSIGNED NULL,
+  ADD COLUMN stage_info TEXT NULL,
+  ADD COLUMN backline JSON NULL,
+  ADD COLUMN accessibility JSON NULL,
+  ADD COLUMN is_accessible TINYINT(1) NOT NULL DEFAULT 0,
+  ADD COLUMN opening_hours JSON NULL,
+  ADD COLUMN contact_email VARCHAR(255) NULL,
+  ADD COLUMN contact_phone VARCHAR(50) NULL,
+  ADD COLUMN social JSON NULL;
+
+-- Índices para los filtros ?accessible=true y ?min_capacity=N
+CREATE INDEX idx_venues_is_accessible ON venues (is_accessible);
+CREATE INDEX idx_venues_capacity ON venues (capacity);
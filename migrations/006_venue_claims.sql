@@ -0,0 +1,6 @@
+-- Solicitudes de vinculación con venues: los vínculos pasan por pending -> approved | rejected
+ALTER TABLE venue_links
+  MODIFY COLUMN status VARCHAR(20) NOT NULL DEFAULT 'pending';
+
+CREATE INDEX idx_venue_links_user_venue ON venue_links (user_id, venue_id);
+CREATE INDEX idx_submissions_type_status ON submissions (type, status);
@@ -0,0 +1,30 @@
+-- Redirecciones de slugs viejos (fusiones y, más adelante, cambios de slug)
+CREATE TABLE IF NOT EXISTS slug_history (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  entity_type VARCHAR(20) NOT NULL,
+  entity_id INT NOT NULL,
+  old_slug VARCHAR(255) NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY uq_slug_history_type_slug (entity_type, old_slug),
+  KEY idx_slug_history_entity (entity_type, entity_id)
+);
+
+-- Registro de fusiones de duplicados. undo_data guarda la fila eliminada,
+-- las referencias reasignadas y las filas repetidas que se borraron.
+CREATE TABLE IF NOT EXISTS merge_operations (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  entity_type VARCHAR(20) NOT NULL,
+  source_id INT NOT NULL,
+  target_id INT NOT NULL,
+  source_slug VARCHAR(255) NOT NULL DEFAULT '',
+  target_slug VARCHAR(255) NOT NULL DEFAULT '',
+  moved JSON NULL,
+  undo_data LONGTEXT NOT NULL,
+  created_by INT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  undone_at DATETIME NULL,
+  undone_by INT NULL,
+  KEY idx_merge_operations_entity (entity_type, created_at)
+);
+
+-- Las bandas ya tienen la columna social; los venues la incorporaron en 005_venue_profile.sql
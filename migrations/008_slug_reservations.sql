@@ -0,0 +1,9 @@
+-- Reservas temporales de slugs mientras se crea el registro.
+-- La clave primaria evita que dos altas simultáneas se queden con el mismo slug.
+CREATE TABLE IF NOT EXISTS slug_reservations (
+  entity_type VARCHAR(20) NOT NULL,
+  slug VARCHAR(255) NOT NULL,
+  expires_at DATETIME NOT NULL,
+  PRIMARY KEY (entity_type, slug),
+  KEY idx_slug_reservations_expires (expires_at)
+);
@@ -0,0 +1,17 @@
+-- Índice de búsqueda general (GET /search). Cada fila es un documento de bandas, eventos,
+-- espacios, noticias, canciones, letras o videos. title_terms y terms guardan el texto
+-- normalizado sin tildes y reducido a raíces en español; la API los mantiene al día
+-- y POST /search/reindex los reconstruye.
+CREATE TABLE IF NOT EXISTS search_index (
+  entity_type VARCHAR(20) NOT NULL,
+  entity_id INT NOT NULL,
+  title VARCHAR(255) NOT NULL DEFAULT '',
+  slug VARCHAR(255) NOT NULL DEFAULT '',
+  body MEDIUMTEXT NOT NULL,
+  title_terms TEXT NOT NULL,
+  terms MEDIUMTEXT NOT NULL,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (entity_type, entity_id),
+  FULLTEXT KEY ft_search_title (title_terms),
+  FULLTEXT KEY ft_search_terms (terms)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
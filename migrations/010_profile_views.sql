@@ -0,0 +1,3 @@
+-- Visitas a los perfiles de bandas y venues, usadas para ordenar el autocompletado
+ALTER TABLE bands ADD COLUMN views INT UNSIGNED NOT NULL DEFAULT 0;
+ALTER TABLE venues ADD COLUMN views INT UNSIGNED NOT NULL DEFAULT 0;
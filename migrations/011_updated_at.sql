@@ -0,0 +1,6 @@
+-- Fecha de última modificación de bandas y venues, usada para Last-Modified en sus perfiles.
+-- Las visitas (views) se suman con updated_at = updated_at para no moverla.
+ALTER TABLE bands
+  ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;
+ALTER TABLE venues
+  ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;
@@ -0,0 +1,32 @@
+-- Webhooks salientes: URLs externas suscriptas a eventos del catálogo.
+-- events es una lista separada por comas (event.created,band.created,...).
+CREATE TABLE IF NOT EXISTS webhooks (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  url VARCHAR(500) NOT NULL,
+  events VARCHAR(255) NOT NULL,
+  secret VARCHAR(64) NOT NULL,
+  description VARCHAR(255) NOT NULL DEFAULT '',
+  active TINYINT(1) NOT NULL DEFAULT 1,
+  created_by INT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Registro de entregas. Las pendientes se envían cuando llega next_attempt_at;
+-- después del último intento fallido quedan como failed y se pueden reenviar.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  webhook_id INT NOT NULL,
+  event_type VARCHAR(50) NOT NULL,
+  payload LONGTEXT NOT NULL,
+  status ENUM('pending', 'delivered', 'failed') NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  response_status INT NULL,
+  response_body TEXT NULL,
+  error VARCHAR(500) NULL,
+  next_attempt_at DATETIME NULL,
+  redelivery_of INT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  delivered_at DATETIME NULL,
+  KEY idx_webhook_deliveries_pending (status, next_attempt_at),
+  KEY idx_webhook_deliveries_webhook (webhook_id, id)
+);
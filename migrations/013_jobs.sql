@@ -0,0 +1,20 @@
+-- Cola de trabajos en segundo plano. Un trabajo pendiente corre cuando llega run_at; si falla se
+-- reintenta con espera creciente y, al agotar max_attempts, queda como dead hasta que un
+-- administrador lo reintente. locked_at permite recuperar los que quedaron en running tras una caída.
+CREATE TABLE IF NOT EXISTS jobs (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  queue VARCHAR(50) NOT NULL,
+  type VARCHAR(100) NOT NULL,
+  payload LONGTEXT NOT NULL,
+  status ENUM('pending', 'running', 'done', 'dead') NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  max_attempts INT NOT NULL DEFAULT 3,
+  run_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  locked_by VARCHAR(100) NULL,
+  locked_at DATETIME NULL,
+  last_error TEXT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  finished_at DATETIME NULL,
+  KEY idx_jobs_claim (queue, status, run_at),
+  KEY idx_jobs_status (status, finished_at)
+);
@@ -0,0 +1,13 @@
+-- Tareas periódicas (renovación de tokens, limpieza, resúmenes...). El horario está en el código
+-- o en [scheduler] de data.conf; acá queda la próxima ejecución, que el líder adelanta con una
+-- actualización condicional para no encolarla dos veces, y el resultado de la última.
+CREATE TABLE IF NOT EXISTS scheduled_tasks (
+  name VARCHAR(100) PRIMARY KEY,
+  next_run_at DATETIME NULL,
+  last_started_at DATETIME NULL,
+  last_finished_at DATETIME NULL,
+  last_status ENUM('running', 'ok', 'error') NULL,
+  last_message TEXT NULL,
+  last_duration_ms INT NULL,
+  last_instance VARCHAR(100) NULL
+);
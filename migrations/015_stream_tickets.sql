@@ -0,0 +1,9 @@
+-- Tickets de un solo uso para abrir /submissions/stream desde EventSource, que no puede enviar
+-- Authorization. Se guarda el hash: el ticket en sí solo lo conoce el panel que lo pidió.
+CREATE TABLE IF NOT EXISTS stream_tickets (
+  ticket_hash CHAR(64) NOT NULL PRIMARY KEY,
+  user_id INT NOT NULL,
+  role VARCHAR(50) NOT NULL,
+  expires_at DATETIME NOT NULL,
+  KEY idx_stream_tickets_expires (expires_at)
+);
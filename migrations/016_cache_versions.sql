@@ -0,0 +1,6 @@
+-- Versión de cada entidad del caché de respuestas. Cada escritura la incrementa y las demás
+-- instancias de la API la leen para descartar lo que tenían cacheado de esa entidad.
+CREATE TABLE IF NOT EXISTS cache_versions (
+  entity VARCHAR(20) NOT NULL PRIMARY KEY,
+  version BIGINT UNSIGNED NOT NULL DEFAULT 0
+);
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"embed"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -9,6 +12,12 @@ import (
 	"brotecolectivo/handlers"
 )
 
+// Los fuentes del paquete se incluyen para que la especificación OpenAPI use los comentarios de
+// cada ruta y las anotaciones de los handlers de main.
+//
+//go:embed *.go
+var routeSources embed.FS
+
 // InitRoutes configura y devuelve el router con todas las rutas de la API.
 // Esta función es el punto de entrada principal para la configuración de endpoints.
 //
@@ -26,21 +35,28 @@ func InitRoutes(authHandler *handlers.AuthHandler) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Middlewares generales
+	r.Use(RequestID)
+	r.Use(RedactAccessToken) // antes del Logger: un token en la URL no tiene que quedar en el log
 	r.Use(CORSMiddleware)
 	r.Use(SecurityHeaders)
 	r.Use(middleware.Logger)
+	r.Use(middleware.Compress(5)) // gzip/deflate según Accept-Encoding
 
 	// Root (protegido con rate limit)
 	r.Group(func(r chi.Router) {
 		r.Use(RateLimit)
-		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-			http.Error(w, "Acceso restringido", http.StatusUnauthorized)
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) { // Sin contenido: responde 401
+			handlers.WriteError(w, r, http.StatusUnauthorized, "Acceso restringido")
 		})
 	})
 
 	// Endpoint para verificar la versión de la API
 	r.Get("/version", getVersion)
 
+	// Especificación OpenAPI 3 generada desde estas rutas y su documentación navegable
+	r.Get("/openapi.json", handlers.OpenAPIHandler(r, routeSources, AuthMiddleware)) // Especificación en JSON
+	r.Get("/docs", handlers.APIDocs)                                                 // Swagger UI
+
 	// Endpoint para aprobación directa de submissions (usado en enlaces de WhatsApp)
 	r.Get("/direct-approve/{id}", authHandler.DirectApprove)
 
@@ -60,23 +76,25 @@ func InitRoutes(authHandler *handlers.AuthHandler) *chi.Mux {
 
 	// Grupo de rutas para bandas/artistas
 	r.Route("/bands", func(r chi.Router) {
+		r.Use(authHandler.Invalidates("bands"))
+
 		// Endpoints auxiliares
-		r.Get("/count", authHandler.GetBandsCount)           // Obtener conteo total de bandas
-		r.Get("/table", authHandler.GetBandsDatatable)       // Datos para DataTables
-		r.Post("/upload-image", authHandler.UploadBandImage) // Subir imagen de banda
-		r.Get("/slug/{slug}", authHandler.CheckBandSlug)     // Verificar disponibilidad de slug
+		r.Get("/count", authHandler.GetBandsCount)                                  // Obtener conteo total de bandas
+		r.Get("/table", authHandler.GetBandsDatatable)                              // Datos para DataTables
+		r.Post("/upload-image", authHandler.UploadBandImage)                        // Subir imagen de banda
+		r.Get("/slug/{slug}", authHandler.CheckBandSlug)                            // Verificar disponibilidad de slug
 		r.With(AuthMiddleware).Post("/generate-bio", authHandler.GenerateArtistBio) // Generar biografía con IA
 
 		// Ruta protegida con autenticación
 		r.With(AuthMiddleware).Get("/user/{user_id}", authHandler.GetUserBands) // Obtener artistas vinculados a un usuario
 
 		// CRUD principal
-		r.Get("/", authHandler.GetBands)    // Listar todas las bandas
-		r.Post("/", authHandler.CreateBand) // Crear nueva banda
+		r.With(authHandler.Cached(30*time.Second, "bands", "songs")).Get("/", authHandler.GetBands) // Listar todas las bandas
+		r.Post("/", authHandler.CreateBand)                                                         // Crear nueva banda
 		r.Route("/{id}", func(r chi.Router) {
-			r.Get("/", authHandler.GetBandByID)   // Obtener detalles de banda
-			r.Put("/", authHandler.UpdateBand)    // Actualizar banda
-			r.Delete("/", authHandler.DeleteBand) // Eliminar banda
+			r.With(handlers.CacheControl(time.Minute)).Get("/", authHandler.GetBandByID) // Obtener detalles de banda
+			r.Put("/", authHandler.UpdateBand)                                           // Actualizar banda
+			r.Delete("/", authHandler.DeleteBand)                                        // Eliminar banda
 		})
 		r.Get("/search", authHandler.SearchBands) // Buscar artistas
 	})
@@ -94,15 +112,17 @@ func InitRoutes(authHandler *handlers.AuthHandler) *chi.Mux {
 
 	// Grupo de rutas para eventos
 	r.Route("/events", func(r chi.Router) {
+		r.Use(authHandler.Invalidates("events"))
+
 		// Endpoints auxiliares
 		r.Get("/count", authHandler.GetEventsCount)     // Obtener conteo total de eventos
 		r.Get("/table", authHandler.GetEventsDatatable) // Datos para DataTables
 
 		r.Get("/slug/{slug}", authHandler.CheckEventSlug) // Verificar disponibilidad de slug
 
-		r.Get("/", authHandler.GetEvents)    // Listar todos los eventos
-		r.Post("/upload-image", authHandler.UploadEventImage)
-		r.With(AuthMiddleware).Post("/generate-description", authHandler.GenerateEventDescription)
+		r.With(authHandler.Cached(30*time.Second, "events", "bands", "venues", "series", "songs")).Get("/", authHandler.GetEvents) // Listar todos los eventos
+		r.Post("/upload-image", authHandler.UploadEventImage)                                                                      // Subir imagen de evento
+		r.With(AuthMiddleware).Post("/generate-description", authHandler.GenerateEventDescription)                                 // Generar descripción con IA
 
 		r.With(AuthMiddleware).Group(func(r chi.Router) {
 			r.Post("/", authHandler.CreateEvent) // Crear nuevo evento
@@ -123,22 +143,116 @@ func InitRoutes(authHandler *handlers.AuthHandler) *chi.Mux {
 		r.Post("/{id}/publish-instagram", authHandler.PublishEventToInstagram) // Nueva ruta para publicar en Instagram
 	})
 
+	// Grupo de rutas para festivales y ciclos (series de eventos)
+	r.Route("/series", func(r chi.Router) {
+		r.Use(authHandler.Invalidates("series"))
+
+		r.With(authHandler.Cached(time.Minute, "series")).Get("/", authHandler.GetSeries) // Listar festivales y ciclos
+
+		r.With(AuthMiddleware).Group(func(r chi.Router) {
+			r.Post("/", authHandler.CreateSeries)                  // Crear nueva serie
+			r.Post("/upload-image", authHandler.UploadSeriesImage) // Subir imagen de serie
+		})
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(authHandler.Cached(time.Minute, "series", "events", "bands", "venues"))
+				r.Get("/", authHandler.GetSeriesByID)         // Obtener serie con sus eventos
+				r.Get("/lineup", authHandler.GetSeriesLineup) // Lineup combinado de la serie
+				r.Get("/ical", authHandler.GetSeriesICal)     // Calendario iCal de la serie
+			})
+
+			r.With(AuthMiddleware).Group(func(r chi.Router) {
+				r.Put("/", authHandler.UpdateSeries)                         // Actualizar serie
+				r.Delete("/", authHandler.DeleteSeries)                      // Eliminar serie
+				r.Post("/publish-social", authHandler.PublishSeriesToSocial) // Publicar serie en redes
+			})
+		})
+	})
+
+	// Grupo de rutas para fusionar duplicados de bandas y venues (solo administradores)
+	r.Route("/merges", func(r chi.Router) {
+		r.Use(AuthMiddleware)
+		r.Use(authHandler.Invalidates())            // una fusión mueve eventos, canciones, videos y noticias
+		r.Get("/", authHandler.GetMerges)           // Historial de fusiones
+		r.Post("/", authHandler.CreateMerge)        // Fusionar un registro duplicado en otro
+		r.Post("/{id}/undo", authHandler.UndoMerge) // Deshacer una fusión
+	})
+
+	// Webhooks salientes para integraciones externas (solo administradores)
+	r.Route("/webhooks", func(r chi.Router) {
+		r.Use(AuthMiddleware)
+		r.Get("/", authHandler.GetWebhooks)                                // Listar suscripciones
+		r.Post("/", authHandler.CreateWebhook)                             // Registrar una URL para tipos de evento
+		r.Put("/{id}", authHandler.UpdateWebhook)                          // Cambiar URL, eventos o estado
+		r.Delete("/{id}", authHandler.DeleteWebhook)                       // Eliminar suscripción y su registro
+		r.Get("/{id}/deliveries", authHandler.GetWebhookDeliveries)        // Registro de entregas
+		r.Post("/{id}/ping", authHandler.PingWebhook)                      // Enviar un evento de prueba
+		r.Post("/deliveries/{id}/redeliver", authHandler.RedeliverWebhook) // Reenviar una entrega
+	})
+
+	// Cola de trabajos en segundo plano (solo administradores)
+	r.Route("/jobs", func(r chi.Router) {
+		r.Use(AuthMiddleware)
+		r.Get("/", authHandler.GetJobs)             // Listar trabajos, con filtros por estado, cola y tipo
+		r.Get("/stats", authHandler.GetJobStats)    // Cantidad de trabajos por cola y estado
+		r.Get("/{id}", authHandler.GetJob)          // Ver un trabajo con su último error
+		r.Post("/{id}/retry", authHandler.RetryJob) // Reintentar un trabajo muerto o adelantar uno pendiente
+		r.Delete("/{id}", authHandler.CancelJob)    // Cancelar un trabajo que no está corriendo
+	})
+
+	// Tareas programadas (solo administradores)
+	r.Route("/scheduler", func(r chi.Router) {
+		r.Use(AuthMiddleware)
+		r.Get("/", authHandler.GetScheduler)                // Horario, próxima ejecución y último resultado de cada tarea
+		r.Post("/{name}/run", authHandler.RunScheduledTask) // Ejecutar una tarea ahora
+	})
+
+	// Búsqueda general en todo el contenido
+	r.Route("/search", func(r chi.Router) {
+		r.Get("/", authHandler.Search)                                     // Buscar bandas, eventos, espacios, noticias, canciones, letras y videos
+		r.With(AuthMiddleware).Post("/reindex", authHandler.ReindexSearch) // Reconstruir el índice (solo administradores)
+	})
+
+	// Autocompletado de bandas, venues y personas (índice en memoria)
+	r.Get("/autocomplete", authHandler.Autocomplete)
+
+	// Consultas GraphQL de solo lectura sobre el catálogo
+	r.Get("/graphql", authHandler.GraphQL)  // Consulta por URL (?query=)
+	r.Post("/graphql", authHandler.GraphQL) // Consulta en JSON
+
+	// Verificar disponibilidad de slugs para cualquier entidad
+	r.Get("/slugs/check", authHandler.CheckSlug)
+
 	// Endpoint para solicitudes de vinculación de artistas
 	r.Post("/artist-link-request", authHandler.CreateArtistLinkRequest)
 
 	// Grupo de rutas para submissions (propuestas de contenido)
 	r.Route("/submissions", func(r chi.Router) {
-		r.Get("/", authHandler.GetSubmissions)                     // Listar todas las submissions
-		r.Post("/upload-image", authHandler.UploadSubmissionImage) // Subir imagen para submission
-		r.Post("/", authHandler.CreateSubmission)                  // Crear nueva submission
-		r.Post("/generate-content", authHandler.GenerateNewsContent) // Generar contenido con IA
-		r.Get("/{id}", authHandler.GetSubmissionByID)              // Obtener detalles de submission
-		r.Post("/{id}/approve", authHandler.ApproveSubmission)     // Aprobar submission
-		r.Put("/{id}", authHandler.UpdateSubmissionStatus)         // Actualizar estado de submission
+		r.Use(authHandler.Invalidates()) // aprobar crea o modifica cualquier tipo de contenido
+		r.Use(OptionalAuth)              // force al aprobar solo vale para administradores
+
+		r.Get("/", authHandler.GetSubmissions)                             // Listar todas las submissions
+		r.Post("/upload-image", authHandler.UploadSubmissionImage)         // Subir imagen para submission
+		r.Post("/", authHandler.CreateSubmission)                          // Crear nueva submission
+		r.Post("/check-duplicates", authHandler.CheckSubmissionDuplicates) // Buscar posibles duplicados antes de enviar
+		r.Post("/generate-content", authHandler.GenerateNewsContent)       // Generar contenido con IA
+		r.Get("/{id}", authHandler.GetSubmissionByID)                      // Obtener detalles de submission
+		r.Post("/{id}/approve", authHandler.ApproveSubmission)             // Aprobar submission
+		r.Put("/{id}", authHandler.UpdateSubmissionStatus)                 // Actualizar estado de submission
+
+		// Cambios en tiempo real para el panel de moderación (SSE, solo administradores)
+		r.With(StreamTicket(authHandler), AuthMiddleware).Get("/stream", authHandler.SubmissionStream)
 	})
 
+	// Ticket de un solo uso para abrir /submissions/stream con EventSource (fuera de /submissions
+	// para que pedirlo no vacíe el caché)
+	r.With(AuthMiddleware).Post("/stream-tickets", authHandler.CreateStreamTicket)
+
 	// Grupo de rutas para ediciones (cambios propuestos a contenido existente)
 	r.Route("/edits", func(r chi.Router) {
+		r.Use(authHandler.Invalidates())
+
 		r.Get("/", authHandler.GetEdits)             // Listar todas las ediciones
 		r.Post("/", authHandler.CreateEdit)          // Crear nueva edición
 		r.Get("/{id}", authHandler.GetEditByID)      // Obtener detalles de edición
@@ -147,46 +261,55 @@ func InitRoutes(authHandler *handlers.AuthHandler) *chi.Mux {
 
 	// Grupo de rutas para noticias
 	r.Route("/news", func(r chi.Router) {
+		r.Use(authHandler.Invalidates("news"))
+
 		// Endpoints auxiliares
-		r.Get("/count", authHandler.GetNewsCount)            // Obtener conteo total de noticias
-		r.Post("/upload-image", authHandler.UploadNewsImage) // Subir imagen de noticia
-		r.Get("/table", authHandler.GetNewsDatatable)        // Datos para DataTables
+		r.Get("/count", authHandler.GetNewsCount)                    // Obtener conteo total de noticias
+		r.Post("/upload-image", authHandler.UploadNewsImage)         // Subir imagen de noticia
+		r.Get("/table", authHandler.GetNewsDatatable)                // Datos para DataTables
 		r.Post("/generate-content", authHandler.GenerateNewsContent) // Generar contenido con IA
 
 		// CRUD principal
-		r.Get("/", authHandler.GetNews)     // Listar todas las noticias
-		r.Post("/", authHandler.CreateNews) // Crear nueva noticia
+		r.With(authHandler.Cached(30*time.Second, "news", "bands")).Get("/", authHandler.GetNews) // Listar todas las noticias
+		r.Post("/", authHandler.CreateNews)                                                       // Crear nueva noticia
 
 		// Endpoints de relación
 		r.Get("/band/{id}", authHandler.GetNewsByBandID) // Noticias por banda
 
 		r.Route("/{id}", func(r chi.Router) {
-			r.Get("/", authHandler.GetNewsByID)   // Obtener detalles de noticia
-			r.Put("/", authHandler.UpdateNews)    // Actualizar noticia
-			r.Delete("/", authHandler.DeleteNews) // Eliminar noticia
+			r.With(authHandler.Cached(time.Minute, "news", "bands")).Get("/", authHandler.GetNewsByID) // Obtener detalles de noticia
+			r.Put("/", authHandler.UpdateNews)                                                         // Actualizar noticia
+			r.Delete("/", authHandler.DeleteNews)                                                      // Eliminar noticia
 		})
 	})
 
 	// Grupo de rutas para venues (lugares)
 	r.Route("/venues", func(r chi.Router) {
-		r.Get("/", authHandler.GetVenues)    // Listar todos los venues
-		r.Post("/", authHandler.CreateVenue) // Crear nuevo venue
+		r.Use(authHandler.Invalidates("venues"))
+
+		r.With(authHandler.Cached(30*time.Second, "venues", "events")).Get("/", authHandler.GetVenues)    // Listar todos los venues
+		r.With(authHandler.Cached(5*time.Minute, "venues")).Get("/geojson", authHandler.GetVenuesGeoJSON) // Venues con coordenadas en GeoJSON para el mapa
+		r.Post("/", authHandler.CreateVenue)                                                              // Crear nuevo venue
 		r.Route("/{id}", func(r chi.Router) {
-			r.Get("/", authHandler.GetVenueByIDOrSlug) // Obtener detalles de venue
-			r.Put("/", authHandler.UpdateVenue)        // Actualizar venue
-			r.Delete("/", authHandler.DeleteVenue)     // Eliminar venue
+			r.With(handlers.CacheControl(time.Minute)).Get("/", authHandler.GetVenueByIDOrSlug) // Obtener detalles de venue
+			r.Put("/", authHandler.UpdateVenue)                                                 // Actualizar venue
+			r.Delete("/", authHandler.DeleteVenue)                                              // Eliminar venue
+			r.With(AuthMiddleware).Put("/profile", authHandler.UpdateVenueProfile)              // Actualizar capacidad, accesibilidad, horarios y contacto
+			r.With(AuthMiddleware).Post("/claim", authHandler.CreateVenueClaim)                 // Solicitar vinculación con el venue (se modera como submission venue_link)
 		})
 		r.With(AuthMiddleware).Get("/user/{user_id}", authHandler.GetUserVenues) // Obtener venues vinculados a un usuario
 	})
 
 	// Grupo de rutas para videos
 	r.Route("/videos", func(r chi.Router) {
+		r.Use(authHandler.Invalidates("videos"))
+
 		// Endpoints de relación
 		r.Get("/band/{id}", authHandler.GetVideosByBandID) // Videos por banda
 
 		// CRUD principal
-		r.Get("/", authHandler.GetVideos)    // Listar todos los videos
-		r.Post("/", authHandler.CreateVideo) // Crear nuevo video
+		r.With(authHandler.Cached(time.Minute, "videos", "bands")).Get("/", authHandler.GetVideos) // Listar todos los videos
+		r.Post("/", authHandler.CreateVideo)                                                       // Crear nuevo video
 		r.Route("/{id}", func(r chi.Router) {
 			r.Get("/", authHandler.GetVideoByID)   // Obtener detalles de video
 			r.Put("/", authHandler.UpdateVideo)    // Actualizar video
@@ -196,12 +319,14 @@ func InitRoutes(authHandler *handlers.AuthHandler) *chi.Mux {
 
 	// Grupo de rutas para canciones
 	r.Route("/songs", func(r chi.Router) {
+		r.Use(authHandler.Invalidates("songs"))
+
 		// Endpoints específicos
 		r.Get("/lyrics/{id}", authHandler.GetLyricsByID) // Obtener letras de canción
 
 		// CRUD principal
-		r.Get("/", authHandler.GetSongs)    // Listar todas las canciones
-		r.Post("/", authHandler.CreateSong) // Crear nueva canción
+		r.With(authHandler.Cached(time.Minute, "songs", "bands")).Get("/", authHandler.GetSongs) // Listar todas las canciones
+		r.Post("/", authHandler.CreateSong)                                                      // Crear nueva canción
 		r.Route("/{id}", func(r chi.Router) {
 			r.Get("/", authHandler.GetSongByID)   // Obtener detalles de canción
 			r.Put("/", authHandler.UpdateSong)    // Actualizar canción
@@ -221,3 +346,23 @@ func InitRoutes(authHandler *handlers.AuthHandler) *chi.Mux {
 
 	return r
 }
+
+// checkOpenAPI arma las rutas sin conectarse a la base y lista las diferencias entre las rutas
+// registradas y las anotaciones de sus handlers. Devuelve el código de salida: 1 si hay diferencias.
+func checkOpenAPI() int {
+	r := InitRoutes(handlers.NewAuthHandler(nil))
+	_, drift, err := handlers.BuildOpenAPI(r, routeSources, AuthMiddleware)
+	if err != nil {
+		fmt.Println("Error al armar la especificación OpenAPI:", err)
+		return 1
+	}
+	for _, problem := range drift {
+		fmt.Println(problem)
+	}
+	if len(drift) > 0 {
+		fmt.Printf("%d diferencias entre las rutas y la especificación\n", len(drift))
+		return 1
+	}
+	fmt.Println("La especificación OpenAPI coincide con las rutas registradas")
+	return 0
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+
+	"brotecolectivo/handlers"
+)
+
+// TestOpenAPIMatchesRoutes falla si una ruta registrada no coincide con las anotaciones de su
+// handler (ruta sin @Router, método distinto, seguridad que no corresponde...)
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	r := InitRoutes(handlers.NewAuthHandler(nil))
+	spec, drift, err := handlers.BuildOpenAPI(r, routeSources, AuthMiddleware)
+	if err != nil {
+		t.Fatalf("BuildOpenAPI: %v", err)
+	}
+	for _, problem := range drift {
+		t.Error(problem)
+	}
+	if paths, _ := spec["paths"].(map[string]map[string]any); len(paths) == 0 {
+		t.Error("la especificación no tiene rutas")
+	}
+}
@@ -60,6 +60,40 @@ func SendRecoveryEmail(email, token string) error {
 	return err
 }
 
+// SendEmail envía un correo HTML simple con el remitente de notificaciones
+func SendEmail(to, subject, htmlBody string) error {
+	if GetMailgunConfig == nil {
+		return fmt.Errorf("configuración de Mailgun no inicializada")
+	}
+	domain, apiKey, err := GetMailgunConfig()
+	if err != nil {
+		return err
+	}
+
+	mg := mailgun.NewMailgun(domain, apiKey)
+	logoURL := "https://cnc.brote.store/themes/2019/img/logo.png"
+	body := fmt.Sprintf(`
+	<html>
+	<body>
+		<div style="text-align: center;">
+			<img src="%s" alt="Logo BROTE" style="max-width: 200px; margin-bottom: 20px;">
+		</div>
+		%s
+	</body>
+	</html>
+	`, logoURL, htmlBody)
+
+	message := mg.NewMessage("no-reply@m.brote.org", subject, "", to)
+	message.SetHtml(body)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	_, _, err = mg.Send(ctx, message)
+	if err != nil {
+		log.Println("Error enviando correo con Mailgun:", err)
+	}
+	return err
+}
+
 var JwtKey []byte
 
 func SetJwtKey(key []byte) {